@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"investor/config"
+	"investor/internal/adapter/rest"
+	"investor/internal/app"
+	"investor/internal/mcp"
+	"investor/internal/tools"
+)
+
+// MCP server exposing the DataService tools to external agents.
+//
+//	go run ./cmd/mcp                  # stdio (for Claude Desktop, IDEs, ...)
+//	go run ./cmd/mcp -transport http  # streamable HTTP at 127.0.0.1:8090/mcp
+//
+// The HTTP transport takes the REST API keys (API_KEYS, API_KEYS_FILE) and
+// only accepts browser origins from localhost and MCP_ALLOWED_ORIGINS.
+func main() {
+	transport := flag.String("transport", "stdio", "transport: stdio or http")
+	addr := flag.String("addr", "127.0.0.1:8090", "listen address for the http transport")
+	flag.Parse()
+
+	// stdout carries the protocol in stdio mode, so all logging goes to stderr
+	log.SetOutput(os.Stderr)
+
+	// 1. Init Config
+	config.Init()
+
+	// zap's development logger writes to stderr as well
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	// 2. Init Data Service
+	app.LoadReferenceData(config.AppConfig, logger)
+	data := app.NewData(config.AppConfig, logger)
+
+	// 3. Init MCP Server
+	// Stateless analytics tools are safe to expose alongside the data tools
+	toolRegistry := tools.NewDataRegistry(data.Default)
+	data.RegisterTools(toolRegistry)
+	server := mcp.NewServer("investor", "1.0.0", toolRegistry)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch *transport {
+	case "stdio":
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			log.Fatalf("MCP stdio server failed: %v", err)
+		}
+	case "http":
+		guard, err := rest.NewConfigGuard(config.AppConfig.Server)
+		if err != nil {
+			log.Printf("Failed to load API key store: %v", err)
+		}
+		if !guard.Enabled() {
+			log.Printf("No API keys configured, the MCP endpoint is open to anyone who can reach %s", *addr)
+		}
+		handler := mcp.NewHTTPHandler(server)
+		for _, origin := range strings.Split(config.AppConfig.MCP.AllowedOrigins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				handler.AllowedOrigins = append(handler.AllowedOrigins, origin)
+			}
+		}
+
+		r := gin.New()
+		r.Use(gin.Recovery())
+		r.SetTrustedProxies(nil)
+		r.Any("/mcp", guard.RequireKey(), gin.WrapH(handler))
+		srv := &http.Server{Addr: *addr, Handler: r}
+		go func() {
+			<-ctx.Done()
+			srv.Shutdown(context.Background())
+		}()
+		log.Printf("MCP server listening on %s/mcp", *addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("MCP http server failed: %v", err)
+		}
+	default:
+		log.Fatalf("unknown transport: %s", *transport)
+	}
+}
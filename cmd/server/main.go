@@ -5,18 +5,31 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"investor/config"
 	"investor/internal/adapter/feishu"
 	"investor/internal/adapter/rest"
 	"investor/internal/agent"
+	"investor/internal/alert"
+	"investor/internal/app"
+	"investor/internal/briefing"
+	"investor/internal/calendar"
 	"investor/internal/core"
-	"investor/internal/dataservice"
+	"investor/internal/journal"
 	"investor/internal/llm"
+	"investor/internal/notify"
+	"investor/internal/portfolio"
+	"investor/internal/screener"
 	"investor/internal/session"
+	"investor/internal/store"
+	"investor/internal/stream"
+	"investor/internal/watchlist"
 )
 
 func main() {
@@ -31,22 +44,114 @@ func main() {
 	llmProvider := llm.NewOpenAIProvider(config.AppConfig.LLM)
 
 	// 4. Init Core Services
-	sessionMgr := session.NewManager()
+	// Persistent state (sessions, alerts, watchlists, ...) and proactive push hub
+	stateStore, err := store.New(config.AppConfig.Store.Path)
+	if err != nil {
+		log.Fatalf("Failed to open state store: %v", err)
+	}
+	sessionMgr := session.NewManager(stateStore)
+	notifier := notify.NewHub()
 
-	// 4.1 Init Data Service Registry (Extensible Data Sources)
-	registry := dataservice.GetRegistry()
-	// Register Yahoo (Primary)
-	yahooSvc := dataservice.NewYahooDataService()
-	registry.Register("yahoo", yahooSvc)
-	// TODO: Register other data sources here (e.g., Bloomberg, Custom API)
+	// Instrument master and exchange holidays beyond the built-in ones
+	app.LoadReferenceData(config.AppConfig, logger)
 
+	// 4.1 Init Data Service Registry (Extensible Data Sources)
+	data := app.NewData(config.AppConfig, logger)
 	// Use Default Data Service for Agent
-	dataService := registry.GetDefault()
+	dataService := data.Default
 
 	// 5. Init Agents
 	// Note: We only need ChatAgent now, as it handles IPO intent too via Tools
 	chatAgent := agent.NewChatAgent(llmProvider, sessionMgr, dataService)
 
+	// Stateless analytics: backtests, multi-symbol comparison, currency conversion
+	data.RegisterTools(chatAgent.Tools)
+
+	// Screener over the configured universes
+	universes, err := screener.LoadUniverses(config.AppConfig.Screen.UniverseFile)
+	if err != nil {
+		logger.Error("Failed to load screener universes, using defaults", zap.Error(err))
+	}
+	screener.RegisterTools(chatAgent.Tools, screener.New(dataService, universes, config.AppConfig.Screen.Concurrency))
+
+	// Signal journal: every BUY/SELL/WAIT answer is recorded and scored after 1d/5d/20d
+	journalMgr := journal.NewManager(stateStore, dataService, logger, config.AppConfig.LLM.ModelName, 0)
+	chatAgent.Observe(journalMgr.Observe)
+	journal.RegisterTools(chatAgent.Tools, journalMgr)
+	go journalMgr.Run(context.Background())
+
+	// 5.1 Price Alerts (evaluated in background, pushed via the originating adapter)
+	alertMgr := alert.NewManager(stateStore, dataService, notifier, logger,
+		time.Duration(config.AppConfig.Alert.CheckInterval)*time.Second)
+	alert.RegisterTools(chatAgent.Tools, alertMgr)
+	alert.RegisterCommands(chatAgent.Commands, alertMgr)
+	go alertMgr.Run(context.Background())
+
+	// 5.2 Watchlists (per user and per group chat)
+	watchMgr := watchlist.NewManager(stateStore, dataService)
+	watchlist.RegisterTools(chatAgent.Tools, watchMgr)
+	watchlist.RegisterCommands(chatAgent.Commands, watchMgr)
+
+	// Live crypto prices: exchange WebSocket tickers for watched and alerted pairs.
+	// The book answers quotes first and re-checks alerts as prices move.
+	var streamMgr *stream.Manager
+	if exchanges := config.AppConfig.Stream.Exchanges; exchanges != "" {
+		book := stream.NewBook(0)
+		var feeds []*stream.Feed
+		for _, name := range strings.Split(exchanges, ",") {
+			if ex := stream.ExchangeByName(name); ex != nil {
+				feeds = append(feeds, stream.NewFeed(ex, book, logger))
+			} else {
+				logger.Warn("Unknown stream exchange, skipping", zap.String("exchange", name))
+			}
+		}
+		streamMgr = stream.NewManager(book, logger, feeds...)
+		streamMgr.Watch(watchMgr.All)
+		streamMgr.Watch(alertMgr.Symbols)
+		data.Yahoo.Live = book
+		go streamMgr.Run(context.Background())
+		go streamMgr.OnUpdate(context.Background(), 5*time.Second, func(codes []string) {
+			ticked := make(map[string]bool, len(codes))
+			for _, c := range codes {
+				ticked[c] = true
+			}
+			// Only price levels are checked against ticks; the scheduler covers the rest
+			err := alertMgr.EvaluatePrices(context.Background(), func(symbol string) (float64, bool) {
+				code, ok := stream.Canonical(symbol)
+				if !ok || !ticked[code] {
+					return 0, false
+				}
+				t, ok := book.Get(code)
+				return t.Price, ok
+			})
+			if err != nil {
+				logger.Error("Alert evaluation failed", zap.Error(err))
+			}
+		})
+	}
+
+	// Economic calendar: imported macro events + earnings dates of watched symbols
+	cal := calendar.New(logger)
+	for _, src := range strings.Split(config.AppConfig.Calendar.Sources, ",") {
+		if src = strings.TrimSpace(src); src != "" {
+			cal.Add(calendar.NewFileSource(src))
+		}
+	}
+	if earnings := calendar.NewEarningsSource(dataService); earnings != nil {
+		cal.Add(earnings)
+	}
+	calendar.RegisterTools(chatAgent.Tools, cal, watchMgr)
+
+	// 5.3 Scheduled market briefings (per chat subscriptions, LLM-summarized)
+	briefMgr := briefing.NewManager(stateStore, dataService, llmProvider, watchMgr, notifier, logger)
+	briefing.RegisterCommands(chatAgent.Commands, briefMgr)
+	go briefMgr.Run(context.Background())
+
+	// 5.4 Portfolios (positions per user, valued in a base currency)
+	portfolioMgr := portfolio.NewManager(stateStore, dataService, data.FX)
+	portfolio.RegisterTools(chatAgent.Tools, portfolioMgr)
+	portfolio.RegisterCommands(chatAgent.Commands, portfolioMgr)
+
 	// 6. Init Dispatcher
 	dispatcher := core.NewDispatcher(logger)
 	dispatcher.RegisterAgent(chatAgent)
@@ -56,6 +161,7 @@ func main() {
 	// 7.1 Feishu Adapter (WebSocket Mode)
 	if config.AppConfig.Feishu.AppID != "" && config.AppConfig.Feishu.AppSecret != "" {
 		feishuAdapter := feishu.NewAdapter(config.AppConfig.Feishu, dispatcher, logger)
+		notifier.Register("feishu", feishuAdapter)
 		go func() {
 			if err := feishuAdapter.StartWS(context.Background()); err != nil {
 				logger.Error("Failed to start Feishu WS", zap.Error(err))
@@ -67,8 +173,17 @@ func main() {
 
 	// 7.2 REST API Adapter (For Coze, Dify, Custom Webhooks)
 	// This also serves as the HTTP server
-	port := config.AppConfig.Server.Port
-	restAdapter := rest.NewAdapter(port, dispatcher, logger)
+	restAdapter := rest.NewAdapter(config.AppConfig.Server, dispatcher, logger)
+	// Portfolios are per key, so they are only served when keys are configured
+	if restAdapter.Guard.Enabled() {
+		restAdapter.Mount(func(r gin.IRouter) { portfolio.RegisterRoutes(r, portfolioMgr, rest.Principal) })
+	} else {
+		logger.Warn("No API keys configured, portfolio REST endpoints are disabled")
+	}
+	restAdapter.Mount(func(r gin.IRouter) { journal.RegisterRoutes(r, journalMgr) })
+	if streamMgr != nil {
+		restAdapter.Mount(func(r gin.IRouter) { stream.RegisterRoutes(r, streamMgr) })
+	}
 
 	// TODO: 7.3 Add WeChat Adapter
 	// wechatAdapter := wechat.NewAdapter(..., dispatcher, logger)
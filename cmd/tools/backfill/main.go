@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"investor/internal/bars"
+	"investor/internal/dataservice"
+)
+
+// Usage: go run ./cmd/tools/backfill -dir ./data/bars -symbols AAPL,BTC,茅台 [-file symbols.txt] [-interval 1d,1wk] [-range 10y]
+//
+// Downloads history into the bar store (BARS_DIR) so the server and the
+// backtest tool read it from disk. Re-running only fetches the missing tail.
+func main() {
+	dir := flag.String("dir", envOr("BARS_DIR", "./data/bars"), "bar store directory")
+	list := flag.String("symbols", "", "comma-separated symbols or names")
+	file := flag.String("file", "", "file with one symbol per line (# comments allowed)")
+	intervals := flag.String("interval", "1d", "comma-separated bar intervals (1d, 1wk, 1mo)")
+	rangeStr := flag.String("range", "10y", "history range (1y, 2y, 5y, 10y, max)")
+	workers := flag.Int("concurrency", 4, "parallel downloads")
+	flag.Parse()
+
+	symbols := splitList(*list)
+	if *file != "" {
+		more, err := readSymbols(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		symbols = append(symbols, more...)
+	}
+	if len(symbols) == 0 {
+		fmt.Fprintln(os.Stderr, "no symbols: use -symbols or -file")
+		os.Exit(2)
+	}
+
+	store, err := bars.New(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	store.TTL = 0 // always check for new bars
+	ds := dataservice.NewYahooDataService()
+	ds.Bars = store
+
+	ctx := context.Background()
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	sem := make(chan struct{}, *workers)
+	for _, sym := range symbols {
+		for _, interval := range splitList(*intervals) {
+			wg.Add(1)
+			go func(sym, interval string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				klines, err := ds.GetHistoricalQuotes(ctx, sym, interval, *rangeStr)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil || len(klines) == 0 {
+					failed++
+					fmt.Printf("❌ %-12s %-4s %v\n", sym, interval, err)
+					return
+				}
+				fmt.Printf("✅ %-12s %-4s %5d bars  %s → %s\n", sym, interval, len(klines), klines[0].Date, klines[len(klines)-1].Date)
+			}(sym, interval)
+		}
+	}
+	wg.Wait()
+
+	fmt.Println("----------------------------------------")
+	fmt.Printf("Stored in %s (%d failed)\n", *dir, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func readSymbols(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			out = append(out, line)
+		}
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"investor/internal/backtest"
+	"investor/internal/bars"
+	"investor/internal/dataservice"
+)
+
+// Usage: go run ./cmd/tools/backtest -symbol AAPL -strategy ma_cross -range 5y [-stop 5 -target 15] [-bars ./data/bars | -data ./testdata] [-json]
+func main() {
+	symbol := flag.String("symbol", "AAPL", "symbol or name")
+	strategy := flag.String("strategy", "ma_cross", "ma_cross | rsi | sr_bounce")
+	rangeStr := flag.String("range", "2y", "history range (1y, 2y, 5y, 10y, max)")
+	interval := flag.String("interval", "1d", "bar interval (1d, 1wk)")
+	fast := flag.Int("fast", 0, "ma_cross fast period (default 20)")
+	slow := flag.Int("slow", 0, "ma_cross slow period (default 60)")
+	oversold := flag.Float64("oversold", 0, "rsi oversold level (default 30)")
+	overbought := flag.Float64("overbought", 0, "rsi overbought level (default 70)")
+	stop := flag.Float64("stop", 0, "stop loss %")
+	target := flag.Float64("target", 0, "take profit %")
+	fee := flag.Float64("fee", 5, "fee per side in bps")
+	barsDir := flag.String("bars", os.Getenv("BARS_DIR"), "read history from this bar store (see cmd/tools/backfill)")
+	dataDir := flag.String("data", "", "run offline on a directory of CSV bars (see dataservice.FileDataService)")
+	asJSON := flag.Bool("json", false, "print the full result as JSON")
+	flag.Parse()
+
+	s, err := backtest.NewStrategy(*strategy, backtest.Params{
+		Fast: *fast, Slow: *slow, Oversold: *oversold, Overbought: *overbought,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	cfg := backtest.Config{
+		StopLossPct:    *stop,
+		TakeProfitPct:  *target,
+		FeeBps:         *fee,
+		PeriodsPerYear: backtest.PeriodsPerYear(*symbol, *interval),
+	}
+	var ds dataservice.DataService
+	if *dataDir != "" {
+		fileSvc, err := dataservice.NewFileDataService(*dataDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		ds = fileSvc
+	} else {
+		yahooSvc := dataservice.NewYahooDataService()
+		if *barsDir != "" {
+			store, err := bars.New(*barsDir)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			yahooSvc.Bars = store
+		}
+		ds = yahooSvc
+	}
+	res, err := backtest.RunSymbol(context.Background(), ds, *symbol, *interval, *rangeStr, s, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ backtest failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(res)
+		return
+	}
+
+	fmt.Printf("📊 %s  %s  %s → %s (%d bars)\n", res.Symbol, res.Strategy, res.Start, res.End, res.Bars)
+	fmt.Println("----------------------------------------")
+	fmt.Printf("Trades:        %d (win rate %.2f%%, avg %.2f%%)\n", res.TradeCount, res.WinRate, res.AvgTradeReturn)
+	fmt.Printf("Total return:  %.2f%% (buy & hold %.2f%%)\n", res.TotalReturn, res.BuyHoldReturn)
+	fmt.Printf("CAGR:          %.2f%%\n", res.CAGR)
+	fmt.Printf("Max drawdown:  %.2f%%\n", res.MaxDrawdown)
+	fmt.Printf("Sharpe:        %.2f\n", res.Sharpe)
+	fmt.Printf("Exposure:      %.2f%%\n", res.Exposure)
+	fmt.Println("----------------------------------------")
+	for _, t := range res.Trades {
+		fmt.Printf("%s %.2f → %s %.2f  %+.2f%%  (%d bars, %s)\n",
+			t.EntryDate, t.EntryPrice, t.ExitDate, t.ExitPrice, t.Return, t.Bars, t.Reason)
+	}
+}
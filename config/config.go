@@ -7,13 +7,31 @@ import (
 )
 
 type Config struct {
-	Server ServerConfig `mapstructure:",squash"`
-	Feishu FeishuConfig `mapstructure:",squash"`
-	LLM    LLMConfig    `mapstructure:",squash"`
+	Server   ServerConfig   `mapstructure:",squash"`
+	Feishu   FeishuConfig   `mapstructure:",squash"`
+	LLM      LLMConfig      `mapstructure:",squash"`
+	Store    StoreConfig    `mapstructure:",squash"`
+	Alert    AlertConfig    `mapstructure:",squash"`
+	Screen   ScreenConfig   `mapstructure:",squash"`
+	Calendar CalendarConfig `mapstructure:",squash"`
+	Symbols  SymbolsConfig  `mapstructure:",squash"`
+	Stream   StreamConfig   `mapstructure:",squash"`
+	Bars     BarsConfig     `mapstructure:",squash"`
+	Data     DataConfig     `mapstructure:",squash"`
+	Hours    HoursConfig    `mapstructure:",squash"`
+	MCP      MCPConfig      `mapstructure:",squash"`
 }
 
 type ServerConfig struct {
 	Port string `mapstructure:"PORT"`
+
+	// REST API access control. Leave API_KEYS and API_KEYS_FILE empty to keep the API open (dev only).
+	APIKeys       string  `mapstructure:"API_KEYS"`        // "name:key,name2:key2"
+	APIKeysFile   string  `mapstructure:"API_KEYS_FILE"`   // JSON key store, see rest.LoadKeyStoreFile
+	RateLimit     float64 `mapstructure:"API_RATE_LIMIT"`  // requests per second per key, unset = 1, negative = unlimited
+	RateBurst     int     `mapstructure:"API_RATE_BURST"`  // token bucket size per key, unset = 5
+	DailyQuota    int     `mapstructure:"API_DAILY_QUOTA"` // requests per key per day, 0 = unlimited
+	WebhookSecret string  `mapstructure:"WEBHOOK_SECRET"`  // HMAC secret for webhooks signed without a key id
 }
 
 type FeishuConfig struct {
@@ -30,6 +48,48 @@ type LLMConfig struct {
 	ModelName string `mapstructure:"LLM_MODEL_NAME"` // e.g. "deepseek-chat", "gpt-4o"
 }
 
+type StoreConfig struct {
+	Path string `mapstructure:"STORE_PATH"` // JSON file for persistent bot state, empty = in-memory
+}
+
+type AlertConfig struct {
+	CheckInterval int `mapstructure:"ALERT_CHECK_INTERVAL"` // seconds between alert evaluations
+}
+
+type ScreenConfig struct {
+	UniverseFile string `mapstructure:"SCREENER_UNIVERSE_FILE"` // JSON of extra universes, see screener.LoadUniverses
+	Concurrency  int    `mapstructure:"SCREENER_CONCURRENCY"`   // parallel data requests per screen
+}
+
+type CalendarConfig struct {
+	Sources string `mapstructure:"CALENDAR_SOURCES"` // comma-separated JSON/ICS files or URLs, see calendar.FileSource
+}
+
+type SymbolsConfig struct {
+	File string `mapstructure:"SYMBOLS_FILE"` // extra instruments (.json/.csv) merged over the embedded master
+}
+
+type StreamConfig struct {
+	Exchanges string `mapstructure:"STREAM_EXCHANGES"` // "okx,binance": live crypto tickers over WebSocket, empty = REST polling only
+}
+
+type BarsConfig struct {
+	Dir string `mapstructure:"BARS_DIR"` // local price history (daily/weekly/monthly bars), empty = always download
+}
+
+type DataConfig struct {
+	Source string `mapstructure:"DATA_SOURCE"` // registered data source: "composite" (default: A-shares from "cn", the rest from "yahoo"), "yahoo", "cn" or "file"
+	Dir    string `mapstructure:"DATA_DIR"`    // directory for the "file" source (CSV bars + news.json), see dataservice.FileDataService
+}
+
+type HoursConfig struct {
+	HolidaysFile string `mapstructure:"TRADING_HOLIDAYS_FILE"` // extra exchange holidays / early closes (JSON), see tradinghours.MergeFile
+}
+
+type MCPConfig struct {
+	AllowedOrigins string `mapstructure:"MCP_ALLOWED_ORIGINS"` // comma-separated browser origins allowed on the MCP HTTP transport besides localhost
+}
+
 var AppConfig *Config
 
 func Init() {
@@ -49,4 +109,14 @@ func Init() {
     if AppConfig.Server.Port == "" {
         AppConfig.Server.Port = "8080"
     }
+    if AppConfig.Alert.CheckInterval == 0 {
+        AppConfig.Alert.CheckInterval = 60
+    }
+    // 0 is "unset": rate limiting is turned off with a negative API_RATE_LIMIT
+    if AppConfig.Server.RateLimit == 0 {
+        AppConfig.Server.RateLimit = 1
+    }
+    if AppConfig.Server.RateBurst == 0 {
+        AppConfig.Server.RateBurst = 5
+    }
 }
@@ -3,14 +3,17 @@ package feishu
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	
 	"investor/config"
+	"investor/internal/agent"
 	"investor/internal/core"
 	"investor/internal/model"
 
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkevent "github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 	larkws "github.com/larksuite/oapi-sdk-go/v3/ws"
 	"go.uber.org/zap"
@@ -43,6 +46,7 @@ func (a *Adapter) StartWS(ctx context.Context) error {
 	// Note: For WS, we use "larkevent" package alias which now points to "github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
 	eventHandler := larkevent.NewEventDispatcher(a.Config.VerificationToken, a.Config.EncryptKey).
 		OnP2MessageReceiveV1(a.handleMessage).
+		OnP2CardActionTrigger(a.handleCardAction).
 		OnP2MessageReadV1(func(ctx context.Context, event *larkim.P2MessageReadV1) error {
 			// Handle read receipt if needed
 			return nil
@@ -71,12 +75,18 @@ func (a *Adapter) handleMessage(ctx context.Context, event *larkim.P2MessageRece
 	}
 	text := contentMap["text"]
 
+	// Feishu reports "p2p" or "group"
+	chatType := "private"
+	if ct := event.Event.Message.ChatType; ct != nil && *ct == "group" {
+		chatType = "group"
+	}
+
 	a.Logger.Info("Received message", zap.String("text", text), zap.String("sender", *senderID))
 
 	// 1. Convert to Internal Message
 	internalMsg := model.InternalMessage{
 		Platform:    "feishu",
-		ChatType:    "private", // TODO: Detect group
+		ChatType:    chatType,
 		ChatID:      *chatID,
 		UserID:      *senderID,
 		Text:        text,
@@ -85,64 +95,61 @@ func (a *Adapter) handleMessage(ctx context.Context, event *larkim.P2MessageRece
 	}
 
 	// 2. Dispatch
-	go func() {
-		response, err := a.Dispatcher.Dispatch(context.Background(), &internalMsg)
-		if err != nil {
-			a.Logger.Error("Dispatch failed", zap.Error(err))
-			return
-		}
-
-		if response != "" {
-			a.Reply(*msgID, response)
-		}
-	}()
+	go a.dispatch(*msgID, &internalMsg)
 
 	return nil
 }
 
-func (a *Adapter) Reply(messageID string, text string) {
-	// Use Interactive Card (Markdown) for better rendering
-	// We need to construct a specific JSON structure for Feishu Interactive Cards
+// handleCardAction turns a card button click (e.g. a symbol choice) into a
+// message from the clicking user, answered as a reply to the card
+func (a *Adapter) handleCardAction(ctx context.Context, event *callback.CardActionTriggerEvent) (*callback.CardActionTriggerResponse, error) {
+	req := event.Event
+	if req == nil || req.Action == nil || req.Operator == nil || req.Context == nil {
+		return nil, nil
+	}
+	choice, _ := req.Action.Value["choice"].(string)
+	if choice == "" {
+		return nil, nil
+	}
+	chatType, _ := req.Action.Value["chat_type"].(string)
 
-	// 1. Check if the text is a simple quote card (already formatted by data service)
-	// If it starts with "### 🍎", it's likely a quote card.
-	// But to be safe, we wrap EVERYTHING in a Markdown element in a Card.
+	internalMsg := model.InternalMessage{
+		Platform: "feishu",
+		ChatType: chatType,
+		ChatID:   req.Context.OpenChatID,
+		UserID:   req.Operator.OpenID,
+		Text:     choice,
+	}
+	go a.dispatch(req.Context.OpenMessageID, &internalMsg)
 
-	// Construct Card JSON
-	cardContent := map[string]interface{}{
-		"config": map[string]interface{}{
-			"wide_screen_mode": true,
-		},
-		"header": map[string]interface{}{
-			"template": "blue", // Use blue header
-			"title": map[string]interface{}{
-				"content": "Investor AI",
-				"tag":     "plain_text",
-			},
-		},
-		"elements": []map[string]interface{}{
-			{
-				"tag": "markdown",
-				"content": text, // The markdown content from AI
-			},
-			{
-				"tag": "note",
-				"elements": []map[string]interface{}{
-					{
-						"tag":     "plain_text",
-						"content": "⚠️ 投资有风险，决策需谨慎 | Powered by Investor",
-					},
-				},
-			},
-		},
+	return &callback.CardActionTriggerResponse{
+		Toast: &callback.Toast{Type: "info", Content: "已选择 " + choice},
+	}, nil
+}
+
+func (a *Adapter) dispatch(messageID string, msg *model.InternalMessage) {
+	reply, err := a.Dispatcher.DispatchDetailed(context.Background(), msg)
+	if err != nil {
+		a.Logger.Error("Dispatch failed", zap.Error(err))
+		return
 	}
 
-	cardBytes, err := json.Marshal(cardContent)
+	if reply.Text != "" {
+		var extra []map[string]interface{}
+		if len(reply.Choices) > 0 {
+			extra = append(extra, choiceButtons(reply.Choices, msg.ChatType))
+		}
+		a.Reply(messageID, reply.Text, extra...)
+	}
+}
+
+// Reply answers a message with a card; extra elements (e.g. buttons) go below the text
+func (a *Adapter) Reply(messageID string, text string, extra ...map[string]interface{}) {
+	content, err := buildCard(text, extra...)
 	if err != nil {
 		a.Logger.Error("Failed to marshal card content", zap.Error(err))
 		return
 	}
-	content := string(cardBytes)
 
 	resp, err := a.Client.Im.Message.Reply(context.Background(), larkim.NewReplyMessageReqBuilder().
 		MessageId(messageID).
@@ -163,3 +170,96 @@ func (a *Adapter) Reply(messageID string, text string) {
 		a.Logger.Info("Reply sent to Feishu (Card)")
 	}
 }
+
+// Send pushes a card to a chat without a message to reply to (alerts, briefings).
+// Implements notify.Sender.
+func (a *Adapter) Send(ctx context.Context, chatID string, text string) error {
+	content, err := buildCard(text)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.Client.Im.Message.Create(ctx, larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(larkim.ReceiveIdTypeChatId).
+		Body(larkim.NewCreateMessageReqBodyBuilder().
+			ReceiveId(chatID).
+			MsgType(larkim.MsgTypeInteractive).
+			Content(content).
+			Build()).
+		Build())
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("feishu send failed: %d %s", resp.Code, resp.Msg)
+	}
+	a.Logger.Info("Message pushed to Feishu (Card)", zap.String("chat_id", chatID))
+	return nil
+}
+
+// choiceButtons renders disambiguation choices as card buttons. A click sends
+// the choice's code back through handleCardAction, so it still names the
+// right instrument after the chat has moved on to another question.
+func choiceButtons(choices []agent.Choice, chatType string) map[string]interface{} {
+	var buttons []map[string]interface{}
+	for _, c := range choices {
+		buttons = append(buttons, map[string]interface{}{
+			"tag":  "button",
+			"type": "default",
+			"text": map[string]interface{}{
+				"tag":     "plain_text",
+				"content": c.Label,
+			},
+			"value": map[string]interface{}{
+				"choice":    c.Code,
+				"chat_type": chatType,
+			},
+		})
+	}
+	return map[string]interface{}{
+		"tag":     "action",
+		"layout":  "flow",
+		"actions": buttons,
+	}
+}
+
+// buildCard wraps markdown text in an Interactive Card (Markdown) for better rendering
+func buildCard(text string, extra ...map[string]interface{}) (string, error) {
+	elements := []map[string]interface{}{
+		{
+			"tag":     "markdown",
+			"content": text, // The markdown content from AI
+		},
+	}
+	elements = append(elements, extra...)
+	elements = append(elements, map[string]interface{}{
+		"tag": "note",
+		"elements": []map[string]interface{}{
+			{
+				"tag":     "plain_text",
+				"content": "⚠️ 投资有风险，决策需谨慎 | Powered by Investor",
+			},
+		},
+	})
+
+	// Construct Card JSON
+	cardContent := map[string]interface{}{
+		"config": map[string]interface{}{
+			"wide_screen_mode": true,
+		},
+		"header": map[string]interface{}{
+			"template": "blue", // Use blue header
+			"title": map[string]interface{}{
+				"content": "Investor AI",
+				"tag":     "plain_text",
+			},
+		},
+		"elements": elements,
+	}
+
+	cardBytes, err := json.Marshal(cardContent)
+	if err != nil {
+		return "", err
+	}
+	return string(cardBytes), nil
+}
@@ -0,0 +1,409 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKey is one client credential with its own limits
+type APIKey struct {
+	Name       string  `json:"name"`
+	Key        string  `json:"key"`
+	Secret     string  `json:"secret,omitempty"`      // HMAC secret for webhooks signed as this key; without one the key cannot sign
+	RateLimit  float64 `json:"rate_limit,omitempty"`  // requests/second, 0 = server default, negative = unlimited
+	Burst      int     `json:"burst,omitempty"`       // bucket size, 0 = server default
+	DailyQuota int     `json:"daily_quota,omitempty"` // requests/day, 0 = server default, negative = unlimited
+	Disabled   bool    `json:"disabled,omitempty"`
+}
+
+// KeyStore looks up API keys
+type KeyStore interface {
+	Lookup(key string) (*APIKey, bool)
+	ByName(name string) (*APIKey, bool)
+	Len() int
+}
+
+// StaticKeyStore is an in-memory KeyStore built from config
+type StaticKeyStore struct {
+	mu     sync.RWMutex
+	byKey  map[string]*APIKey
+	byName map[string]*APIKey
+}
+
+func NewStaticKeyStore(keys []APIKey) *StaticKeyStore {
+	s := &StaticKeyStore{
+		byKey:  make(map[string]*APIKey),
+		byName: make(map[string]*APIKey),
+	}
+	for _, k := range keys {
+		s.Add(k)
+	}
+	return s
+}
+
+// Add registers (or replaces) a key
+func (s *StaticKeyStore) Add(k APIKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := k
+	s.byKey[key.Key] = &key
+	s.byName[key.Name] = &key
+}
+
+func (s *StaticKeyStore) Lookup(key string) (*APIKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// Constant-time compare against every key, without stopping at a match,
+	// so timing leaks neither key prefixes nor which key matched
+	var found *APIKey
+	for candidate, k := range s.byKey {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			found = k
+		}
+	}
+	if found == nil {
+		return nil, false
+	}
+	return found, !found.Disabled
+}
+
+func (s *StaticKeyStore) ByName(name string) (*APIKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.byName[name]
+	if !ok || k.Disabled {
+		return nil, false
+	}
+	return k, true
+}
+
+func (s *StaticKeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byKey)
+}
+
+// ParseAPIKeys parses the API_KEYS config value: "name:key,name2:key2".
+// A bare key without a name is named after its position.
+func ParseAPIKeys(spec string) []APIKey {
+	var keys []APIKey
+	for i, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, key, ok := strings.Cut(part, ":")
+		if !ok {
+			name, key = fmt.Sprintf("key%d", i+1), part
+		}
+		keys = append(keys, APIKey{Name: strings.TrimSpace(name), Key: strings.TrimSpace(key)})
+	}
+	return keys
+}
+
+// LoadKeyStoreFile reads a JSON array of APIKey objects
+func LoadKeyStoreFile(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid key store %s: %v", path, err)
+	}
+	return keys, nil
+}
+
+// NewConfigGuard builds the Guard for API_KEYS, API_KEYS_FILE and the server
+// limits. The error reports an unreadable key file; the guard is still
+// returned with the API_KEYS keys.
+func NewConfigGuard(cfg config.ServerConfig) (*Guard, error) {
+	keys := ParseAPIKeys(cfg.APIKeys)
+	var err error
+	if cfg.APIKeysFile != "" {
+		var fileKeys []APIKey
+		fileKeys, err = LoadKeyStoreFile(cfg.APIKeysFile)
+		keys = append(keys, fileKeys...)
+	}
+	return NewGuard(NewStaticKeyStore(keys), Limits{
+		RateLimit:     cfg.RateLimit,
+		Burst:         cfg.RateBurst,
+		DailyQuota:    cfg.DailyQuota,
+		WebhookSecret: cfg.WebhookSecret,
+	}), err
+}
+
+// Limits are the server-wide defaults applied to keys without their own.
+// A RateLimit or DailyQuota of zero or less is unlimited.
+type Limits struct {
+	RateLimit     float64
+	Burst         int
+	DailyQuota    int
+	WebhookSecret string
+}
+
+// Guard enforces authentication, rate limits and quotas
+type Guard struct {
+	Keys   KeyStore
+	Limits Limits
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	quotas  map[string]*dailyCounter
+	now     func() time.Time
+}
+
+func NewGuard(keys KeyStore, limits Limits) *Guard {
+	return &Guard{
+		Keys:    keys,
+		Limits:  limits,
+		buckets: make(map[string]*tokenBucket),
+		quotas:  make(map[string]*dailyCounter),
+		now:     time.Now,
+	}
+}
+
+// Enabled reports whether any keys are configured. Without keys the API stays open.
+func (g *Guard) Enabled() bool {
+	return g != nil && g.Keys != nil && g.Keys.Len() > 0
+}
+
+const apiKeyContextKey = "api_key"
+
+// Principal is the name of the key that authenticated the request, empty
+// when the request was not authenticated (no keys configured)
+func Principal(c *gin.Context) string {
+	return c.GetString(apiKeyContextKey)
+}
+
+// RequireKey authenticates requests by "Authorization: Bearer <key>" or "X-API-Key"
+func (g *Guard) RequireKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !g.Enabled() {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader("X-API-Key")
+		if auth := c.GetHeader("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		}
+		if key == "" {
+			unauthorized(c, "missing API key")
+			return
+		}
+
+		k, ok := g.Keys.Lookup(key)
+		if !ok {
+			unauthorized(c, "invalid API key")
+			return
+		}
+		g.admit(c, k)
+	}
+}
+
+// maxSignedBody caps the webhook body read into memory for signature checks
+const maxSignedBody = 1 << 20
+
+// RequireSignature authenticates webhook calls by HMAC-SHA256.
+// The client sends:
+//
+//	X-Investor-Key-Id:    key name (optional)
+//	X-Investor-Timestamp: unix seconds
+//	X-Investor-Signature: sha256=hex(HMAC(secret, timestamp + "." + body))
+//
+// A call naming a key must be signed with that key's own secret. Without a
+// key id it is signed with WEBHOOK_SECRET and runs as the anonymous "webhook"
+// principal.
+func (g *Guard) RequireSignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.GetHeader("X-Investor-Key-Id")
+		ts := c.GetHeader("X-Investor-Timestamp")
+		sig := strings.TrimPrefix(c.GetHeader("X-Investor-Signature"), "sha256=")
+		if ts == "" || sig == "" {
+			unauthorized(c, "missing signature headers")
+			return
+		}
+
+		var k *APIKey
+		secret := g.Limits.WebhookSecret
+		if name != "" {
+			// The shared secret must not let its holders act as any named key
+			var ok bool
+			if g.Enabled() {
+				k, ok = g.Keys.ByName(name)
+			}
+			if !ok || k.Secret == "" {
+				unauthorized(c, "unknown key id or key without a webhook secret")
+				return
+			}
+			secret = k.Secret
+		}
+		if secret == "" {
+			unauthorized(c, "webhook signing is not configured")
+			return
+		}
+
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil || math.Abs(float64(g.now().Unix()-sec)) > 300 {
+			unauthorized(c, "timestamp expired")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "body too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cannot read body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !hmac.Equal([]byte(Sign(secret, ts, body)), []byte(sig)) {
+			unauthorized(c, "invalid signature")
+			return
+		}
+
+		if k == nil {
+			k = &APIKey{Name: "webhook"}
+		}
+		g.admit(c, k)
+	}
+}
+
+// Sign computes the webhook signature (hex, without the "sha256=" prefix)
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// admit applies rate limit and quota for an authenticated key
+func (g *Guard) admit(c *gin.Context, k *APIKey) {
+	now := g.now()
+
+	if ok, wait := g.allow(k, now); !ok {
+		tooMany(c, wait, "rate limit exceeded")
+		return
+	}
+	if ok, remaining, reset := g.consume(k, now); !ok {
+		tooMany(c, reset, "daily quota exceeded")
+		return
+	} else if remaining >= 0 {
+		c.Header("X-Quota-Remaining", strconv.Itoa(remaining))
+	}
+
+	c.Set(apiKeyContextKey, k.Name)
+	c.Next()
+}
+
+func (g *Guard) allow(k *APIKey, now time.Time) (bool, time.Duration) {
+	rate, burst := k.RateLimit, k.Burst
+	if rate == 0 {
+		rate = g.Limits.RateLimit
+	}
+	if burst == 0 {
+		burst = g.Limits.Burst
+	}
+	if rate <= 0 {
+		return true, 0
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.buckets[k.Name]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		g.buckets[k.Name] = b
+	}
+	return b.take(now, rate, float64(burst))
+}
+
+func (g *Guard) consume(k *APIKey, now time.Time) (bool, int, time.Duration) {
+	quota := k.DailyQuota
+	if quota == 0 {
+		quota = g.Limits.DailyQuota
+	}
+	if quota <= 0 {
+		return true, -1, 0
+	}
+
+	day := now.Format("2006-01-02")
+	y, m, d := now.Date()
+	reset := time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Sub(now)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	q, ok := g.quotas[k.Name]
+	if !ok || q.day != day {
+		q = &dailyCounter{day: day}
+		g.quotas[k.Name] = q
+	}
+	if q.count >= quota {
+		return false, 0, reset
+	}
+	q.count++
+	return true, quota - q.count, reset
+}
+
+// tokenBucket refills continuously at `rate` tokens per second up to `burst`
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time, rate, burst float64) (bool, time.Duration) {
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+type dailyCounter struct {
+	day   string
+	count int
+}
+
+func unauthorized(c *gin.Context, reason string) {
+	c.Header("WWW-Authenticate", `Bearer realm="investor"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": reason})
+}
+
+func tooMany(c *gin.Context, wait time.Duration, reason string) {
+	secs := int(math.Ceil(wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": reason, "retry_after": secs})
+}
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"investor/config"
 	"investor/internal/core"
 	"investor/internal/model"
 	"net/http"
@@ -15,25 +16,46 @@ type Adapter struct {
 	Dispatcher *core.Dispatcher
 	Logger     *zap.Logger
 	Port       string
+	Guard      *Guard
+
+	routes []func(r gin.IRouter)
 }
 
-func NewAdapter(port string, dispatcher *core.Dispatcher, logger *zap.Logger) *Adapter {
+func NewAdapter(cfg config.ServerConfig, dispatcher *core.Dispatcher, logger *zap.Logger) *Adapter {
+	guard, err := NewConfigGuard(cfg)
+	if err != nil {
+		logger.Error("Failed to load API key store", zap.String("path", cfg.APIKeysFile), zap.Error(err))
+	}
+	if !guard.Enabled() {
+		logger.Warn("No API keys configured, REST API is open to anyone who can reach the port")
+	}
+
 	return &Adapter{
 		Dispatcher: dispatcher,
 		Logger:     logger,
-		Port:       port,
+		Port:       cfg.Port,
+		Guard:      guard,
 	}
 }
 
+// Mount registers extra endpoints under /api/v1, behind the API key guard.
+// Feature packages use it to expose their REST API (e.g. portfolio.RegisterRoutes).
+func (a *Adapter) Mount(register func(r gin.IRouter)) {
+	a.routes = append(a.routes, register)
+}
+
 type ChatRequest struct {
-	UserID   string `json:"user_id" binding:"required"`
-	Text     string `json:"text" binding:"required"`
-	ChatID   string `json:"chat_id"`
-	Platform string `json:"platform"` // optional, defaults to "api"
+	UserID      string `json:"user_id"` // required without API keys; with keys it must be empty or the key's name
+	Text        string `json:"text" binding:"required"`
+	ChatID      string `json:"chat_id"`
+	Platform    string `json:"platform"`     // optional, "api"; other platforms only without API keys
+	Format      string `json:"format"`       // optional reply format: "markdown" (default), "plain", "slack", "telegram", "feishu"
+	IncludeData bool   `json:"include_data"` // v1 only: also return the structured data (always on in v2)
 }
 
 type ChatResponse struct {
-	Response string `json:"response"`
+	Response string    `json:"response"`
+	Data     *ChatData `json:"data,omitempty"`
 }
 
 func (a *Adapter) Start(ctx context.Context) error {
@@ -41,7 +63,25 @@ func (a *Adapter) Start(ctx context.Context) error {
 	// Fix trusted proxies warning
 	r.SetTrustedProxies(nil)
 
-	r.POST("/api/v1/chat", a.handleChat)
+	v1 := r.Group("/api/v1")
+	v1.POST("/chat", a.Guard.RequireKey(), a.handleChat)
+	// Signed variant for Coze/Dify webhooks (HMAC instead of a bearer key)
+	v1.POST("/webhook/chat", a.Guard.RequireSignature(), a.handleChat)
+
+	authed := v1.Group("", a.Guard.RequireKey())
+	for _, register := range a.routes {
+		register(authed)
+	}
+
+	v2 := r.Group("/api/v2")
+	v2.POST("/chat", a.Guard.RequireKey(), a.handleChatV2)
+	v2.POST("/webhook/chat", a.Guard.RequireSignature(), a.handleChatV2)
+
+	// OpenAI-compatible facade
+	openai := r.Group("/v1", a.Guard.RequireKey())
+	openai.GET("/models", a.handleModels)
+	openai.POST("/chat/completions", a.handleChatCompletions)
+
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
@@ -57,35 +97,93 @@ func (a *Adapter) handleChat(c *gin.Context) {
 		return
 	}
 
-	platform := req.Platform
-	if platform == "" {
-		platform = "api"
+	msg, ok := toInternalMessage(c, &req)
+	if !ok {
+		return
 	}
 
-	msg := &model.InternalMessage{
-		Platform:    platform,
-		ChatType:    "private",
-		ChatID:      req.ChatID,
-		UserID:      req.UserID,
-		Text:        req.Text,
-		Timestamp:   time.Now().Unix(),
-		IsMentioned: true, // API calls are always mentions/direct
+	// Dispatch is synchronous: core.Dispatcher.Dispatch calls agent.Process and waits
+	// for the answer, so we can return it directly in the HTTP response.
+	reply, err := a.Dispatcher.DispatchDetailed(c.Request.Context(), msg)
+	if err != nil {
+		a.Logger.Error("Dispatch failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
 	}
 
-	// Dispatch is currently synchronous for the API response,
-	// but the dispatcher might run async.
-	// We need to wait for the response to return it in HTTP.
-	// The current Dispatcher.Dispatch returns (string, error), so it is synchronous.
-	// If it spawns goroutines, we might need to adjust, but core.Dispatcher.Dispatch calls agent.Process which seems sync.
+	resp := ChatResponse{
+		Response: reply.Text,
+	}
+	if req.IncludeData {
+		data := newChatData(reply)
+		resp.Data = &data
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (a *Adapter) handleChatV2(c *gin.Context) {
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	respText, err := a.Dispatcher.Dispatch(c.Request.Context(), msg)
+	msg, ok := toInternalMessage(c, &req)
+	if !ok {
+		return
+	}
+
+	reply, err := a.Dispatcher.DispatchDetailed(c.Request.Context(), msg)
 	if err != nil {
 		a.Logger.Error("Dispatch failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, ChatResponse{
-		Response: respText,
+	c.JSON(http.StatusOK, ChatResponseV2{
+		Response: reply.Text,
+		ChatData: newChatData(reply),
 	})
 }
+
+// toInternalMessage binds the request to its caller. With API keys the caller
+// is the authenticated key: it acts as user "<key name>" on platform "api",
+// chats are namespaced under it, and a body claiming another user or platform
+// is rejected (403). Without keys the API is open and the body is trusted.
+func toInternalMessage(c *gin.Context, req *ChatRequest) (*model.InternalMessage, bool) {
+	platform, userID, chatID := req.Platform, req.UserID, req.ChatID
+	if principal := Principal(c); principal != "" {
+		if (userID != "" && userID != principal) || (platform != "" && platform != "api") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "an API key can only act as its own user on platform api"})
+			return nil, false
+		}
+		platform, userID = "api", principal
+		chatID = scopedID(principal, chatID)
+	} else if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return nil, false
+	}
+	if platform == "" {
+		platform = "api"
+	}
+
+	return &model.InternalMessage{
+		Platform:    platform,
+		ChatType:    "private",
+		ChatID:      chatID,
+		UserID:      userID,
+		Text:        req.Text,
+		Timestamp:   time.Now().Unix(),
+		IsMentioned: true, // API calls are always mentions/direct
+		Format:      req.Format,
+	}, true
+}
+
+// scopedID namespaces a client-chosen id under the authenticated key, so two
+// keys never share a chat or user: "key", "key/id"
+func scopedID(principal, id string) string {
+	if id == "" {
+		return principal
+	}
+	return principal + "/" + id
+}
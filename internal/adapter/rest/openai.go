@@ -0,0 +1,288 @@
+package rest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"investor/internal/agent"
+	"investor/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OpenAI Chat Completions facade: lets any OpenAI-compatible client
+// (IDE plugins, Open WebUI, ...) use Investor as a "model" with market tools built in.
+
+// ModelID is the model name advertised on /v1/models
+const ModelID = "investor"
+
+type openAIMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// text flattens string or multi-part ([{type:"text",text:"..."}]) content
+func (m openAIMessage) text() string {
+	var s string
+	if err := json.Unmarshal(m.Content, &s); err == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(m.Content, &parts); err == nil {
+		var texts []string
+		for _, p := range parts {
+			if p.Type == "text" {
+				texts = append(texts, p.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+type chatCompletionRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages" binding:"required"`
+	Stream   bool            `json:"stream"`
+	User     string          `json:"user"`
+}
+
+type chatCompletionMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type chatCompletionChoice struct {
+	Index        int                    `json:"index"`
+	Message      *chatCompletionMessage `json:"message,omitempty"`
+	Delta        *chatCompletionMessage `json:"delta,omitempty"`
+	FinishReason *string                `json:"finish_reason"`
+}
+
+type chatCompletionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type chatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []chatCompletionChoice `json:"choices"`
+	Usage   *chatCompletionUsage   `json:"usage,omitempty"`
+}
+
+func (a *Adapter) handleModels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data": []gin.H{
+			{"id": ModelID, "object": "model", "created": 0, "owned_by": "investor"},
+		},
+	})
+}
+
+func (a *Adapter) handleChatCompletions(c *gin.Context) {
+	var req chatCompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	if req.Model != "" && req.Model != ModelID {
+		openAIError(c, http.StatusNotFound, "model_not_found", fmt.Sprintf("The model '%s' does not exist", req.Model))
+		return
+	}
+
+	msg, err := completionToInternalMessage(&req, Principal(c))
+	if err != nil {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	id := "chatcmpl-" + randomID()
+	created := time.Now().Unix()
+
+	if !req.Stream {
+		reply, err := a.Dispatcher.DispatchDetailed(c.Request.Context(), msg)
+		if err != nil {
+			a.Logger.Error("Dispatch failed", zap.Error(err))
+			openAIError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+			return
+		}
+		stop := "stop"
+		c.JSON(http.StatusOK, chatCompletionResponse{
+			ID:      id,
+			Object:  "chat.completion",
+			Created: created,
+			Model:   ModelID,
+			Choices: []chatCompletionChoice{{
+				Message:      &chatCompletionMessage{Role: "assistant", Content: reply.Text},
+				FinishReason: &stop,
+			}},
+			Usage: &chatCompletionUsage{},
+		})
+		return
+	}
+
+	a.streamCompletion(c, msg, id, created)
+}
+
+// streamCompletion answers with server-sent events. The agent is not incremental,
+// so we keep the connection alive while it works and then stream the answer in chunks.
+func (a *Adapter) streamCompletion(c *gin.Context, msg *model.InternalMessage, id string, created int64) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	type result struct {
+		reply *agent.Reply
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		reply, err := a.Dispatcher.DispatchDetailed(c.Request.Context(), msg)
+		done <- result{reply, err}
+	}()
+
+	chunk := func(delta *chatCompletionMessage, finish *string) chatCompletionResponse {
+		return chatCompletionResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   ModelID,
+			Choices: []chatCompletionChoice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	writeEvent(c.Writer, chunk(&chatCompletionMessage{Role: "assistant"}, nil))
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	var res result
+wait:
+	for {
+		select {
+		case res = <-done:
+			break wait
+		case <-ticker.C:
+			io.WriteString(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+
+	text := ""
+	if res.err != nil {
+		a.Logger.Error("Dispatch failed", zap.Error(res.err))
+		text = "Internal server error"
+	} else {
+		text = res.reply.Text
+	}
+
+	for _, piece := range splitChunks(text, 24) {
+		writeEvent(c.Writer, chunk(&chatCompletionMessage{Content: piece}, nil))
+	}
+	stop := "stop"
+	writeEvent(c.Writer, chunk(&chatCompletionMessage{}, &stop))
+	io.WriteString(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+func writeEvent(w gin.ResponseWriter, v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	w.Flush()
+}
+
+// splitChunks cuts text into pieces of at most n runes, preferring line breaks
+func splitChunks(text string, n int) []string {
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > 0 {
+		end := n
+		if end > len(runes) {
+			end = len(runes)
+		}
+		for i := 0; i < end; i++ {
+			if runes[i] == '\n' {
+				end = i + 1
+				break
+			}
+		}
+		chunks = append(chunks, string(runes[:end]))
+		runes = runes[end:]
+	}
+	return chunks
+}
+
+// completionToInternalMessage turns an OpenAI request into an InternalMessage:
+// the last user message is the question, earlier user/assistant turns become history.
+// System messages are ignored; the agent uses its own system prompt.
+//
+// The caller is the authenticated key, as on the native chat API; the "user"
+// field names a sub-user within it ("key/user"). Without API keys "user" is required.
+func completionToInternalMessage(req *chatCompletionRequest, principal string) (*model.InternalMessage, error) {
+	last := -1
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("messages must contain at least one user message")
+	}
+
+	var history []model.HistoryMessage
+	for _, m := range req.Messages[:last] {
+		if m.Role != "user" && m.Role != "assistant" {
+			continue
+		}
+		history = append(history, model.HistoryMessage{Role: m.Role, Content: m.text()})
+	}
+
+	user := req.User
+	if principal != "" {
+		user = scopedID(principal, user)
+	} else if user == "" {
+		return nil, fmt.Errorf("user is required when the server has no API keys")
+	}
+
+	return &model.InternalMessage{
+		Platform:    "api",
+		ChatType:    "private",
+		ChatID:      user,
+		UserID:      user,
+		Text:        req.Messages[last].text(),
+		Timestamp:   time.Now().Unix(),
+		IsMentioned: true,
+		Format:      "markdown",
+		Stateless:   true,
+		History:     history,
+	}, nil
+}
+
+func openAIError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": gin.H{"message": message, "type": code, "code": code},
+	})
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"encoding/json"
+
+	"investor/internal/agent"
+)
+
+// SchemaVersion is bumped whenever a field in the structured response changes meaning
+// or is removed. Adding optional fields does not bump it.
+const SchemaVersion = "2"
+
+// ChatData is the structured data behind an answer
+type ChatData struct {
+	SchemaVersion string         `json:"schema_version"`
+	Intent        IntentData     `json:"intent"`
+	Signal        *SignalData    `json:"signal"` // null unless the answer carries a BUY/SELL/WAIT call
+	ToolCalls     []ToolCallData `json:"tool_calls"`
+	Fallback      bool           `json:"fallback"`
+	Timing        TimingData     `json:"timing"`
+}
+
+type IntentData struct {
+	Level int    `json:"level"` // 0-5 as in the intent system, -1 for small talk
+	Name  string `json:"name"`  // signal, ticker, flash, review, battle, deep_dive, chat
+}
+
+type SignalData struct {
+	Action     string  `json:"action"` // BUY, SELL, WAIT
+	Confidence int     `json:"confidence"`
+	Entry      float64 `json:"entry,omitempty"`
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+}
+
+type ToolCallData struct {
+	Name       string          `json:"name"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"` // raw quote/analysis/news payload
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+}
+
+type TimingData struct {
+	StartedAt int64 `json:"started_at"` // unix millis
+	LLMMs     int64 `json:"llm_ms"`
+	ToolsMs   int64 `json:"tools_ms"`
+	TotalMs   int64 `json:"total_ms"`
+}
+
+// ChatResponseV2 is the /api/v2/chat response: the answer plus its structured data
+type ChatResponseV2 struct {
+	Response string `json:"response"`
+	ChatData
+}
+
+// newChatData maps the agent reply onto the public schema
+func newChatData(r *agent.Reply) ChatData {
+	data := ChatData{
+		SchemaVersion: SchemaVersion,
+		Intent:        IntentData{Level: r.Intent.Level, Name: r.Intent.Name},
+		ToolCalls:     make([]ToolCallData, 0, len(r.ToolCalls)),
+		Fallback:      r.Fallback,
+		Timing: TimingData{
+			StartedAt: r.Timing.StartedAt,
+			LLMMs:     r.Timing.LLMMs,
+			ToolsMs:   r.Timing.ToolsMs,
+			TotalMs:   r.Timing.TotalMs,
+		},
+	}
+	if r.Signal != nil {
+		data.Signal = &SignalData{
+			Action:     r.Signal.Action,
+			Confidence: r.Signal.Confidence,
+			Entry:      r.Signal.Entry,
+			StopLoss:   r.Signal.StopLoss,
+			TakeProfit: r.Signal.TakeProfit,
+		}
+	}
+	for _, tc := range r.ToolCalls {
+		data.ToolCalls = append(data.ToolCalls, ToolCallData{
+			Name:       tc.Name,
+			Arguments:  tc.Arguments,
+			Result:     tc.Result,
+			Error:      tc.Error,
+			DurationMs: tc.DurationMs,
+		})
+	}
+	return data
+}
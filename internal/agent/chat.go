@@ -6,45 +6,108 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"investor/internal/command"
 	"investor/internal/dataservice"
 	"investor/internal/llm"
 	"investor/internal/model"
+	"investor/internal/render"
 	"investor/internal/session"
+	"investor/internal/tools"
+	"investor/internal/tradinghours"
 )
 
+// PromptVersion identifies the system prompt below. Bump it whenever the prompt
+// changes so signal statistics can be compared across versions.
+// v2: symbol disambiguation, market clock and freshness rules, FX tools.
+// v3: the Signal line names its symbol.
+// v4: 'break' freshness.
+// v5: 'unknown' freshness past the holiday tables.
+const PromptVersion = "v5"
+
+// Observer is notified of every LLM-generated reply (e.g. to journal signals).
+// It runs synchronously, so slow work should be moved to a goroutine.
+type Observer func(ctx context.Context, msg *model.InternalMessage, reply *Reply)
+
 type ChatAgent struct {
-	LLM     llm.Provider
-	Session *session.Manager
-	Data    dataservice.DataService
+	LLM       llm.Provider
+	Session   *session.Manager
+	Data      dataservice.DataService
+	Tools     *tools.Registry
+	Commands  *command.Router
+	Observers []Observer
 }
 
 func NewChatAgent(p llm.Provider, session *session.Manager, data dataservice.DataService) *ChatAgent {
 	return &ChatAgent{
-		LLM:     p,
-		Session: session,
-		Data:    data,
+		LLM:      p,
+		Session:  session,
+		Data:     data,
+		Tools:    tools.NewDataRegistry(data),
+		Commands: command.NewRouter(),
 	}
 }
 
+// Observe registers an observer for LLM replies
+func (a *ChatAgent) Observe(o Observer) {
+	a.Observers = append(a.Observers, o)
+}
+
 func (a *ChatAgent) Name() string {
 	return "ChatAgent"
 }
 
 func (a *ChatAgent) Process(ctx context.Context, msg *model.InternalMessage) (string, error) {
+	reply, err := a.ProcessDetailed(ctx, msg)
+	if err != nil {
+		return "", err
+	}
+	return reply.Text, nil
+}
+
+// ProcessDetailed answers a message and returns the structured data behind the answer
+func (a *ChatAgent) ProcessDetailed(ctx context.Context, msg *model.InternalMessage) (*Reply, error) {
+	reply := newReply()
+
 	// 1. Hardcoded test response
 	if strings.TrimSpace(msg.Text) == "ping" {
-		return "pong (飞书连接正常)", nil
+		return reply.done("pong (飞书连接正常)"), nil
 	}
 	if strings.TrimSpace(msg.Text) == "测试" {
-		return "收到测试消息，系统运行正常！", nil
+		return reply.done("收到测试消息，系统运行正常！"), nil
 	}
 
-	// 2. Load History
+	// 1.1 Slash commands bypass the LLM
+	if cmd, args, ok := a.Commands.Match(msg.Text); ok {
+		doc, err := cmd.Handler(ctx, msg, args)
+		if err != nil {
+			doc = render.NewDocument().Paragraph("⚠️ " + err.Error())
+		}
+		return reply.done(replyRenderer(msg).Render(doc)), nil
+	}
+
+	// 1.2 A reply to "which one did you mean?" replays the original question
 	sessionID := fmt.Sprintf("%s:%s", msg.Platform, msg.ChatID)
-	history, err := a.Session.GetHistory(ctx, sessionID)
-	if err != nil {
-		fmt.Printf("Failed to get history: %v\n", err)
+	stateless := msg.Stateless
+	if !stateless {
+		if replay, ok := a.answerChoice(ctx, sessionID, msg); ok {
+			msg = replay
+		}
+	}
+
+	// 2. Load History
+	var history []llm.Message
+	if stateless {
+		for _, h := range msg.History {
+			history = append(history, llm.Message{Role: h.Role, Content: h.Content})
+		}
+	} else {
+		var err error
+		history, err = a.Session.GetHistory(ctx, sessionID)
+		if err != nil {
+			fmt.Printf("Failed to get history: %v\n", err)
+		}
 	}
 
 	// 3. Construct Messages
@@ -59,20 +122,20 @@ You MUST classify user intent into exactly one of these levels and strictly foll
 - **Tools**: 'get_security_analysis' + 'get_market_sentiment'
 - **Tone**: Trader (Decisive, Risk-Aware)
 - **Output**:
-  1. **Signal**: BUY / SELL / WAIT (Confidence: 1-10)
+  1. **Signal**: <SYMBOL> BUY / SELL / WAIT (Confidence: 1-10), e.g. "**Signal**: NVDA BUY (Confidence: 7)"
   2. **Trade Plan**: Entry, Stop Loss, Take Profit
   3. **Reason**: 1 short sentence (e.g. "RSI divergence + Support bounce")
   4. *Disclaimer*: "NFA (Not Financial Advice)"
 
 ## Level 1: Ticker (🤖 报价模式)
 - **Trigger**: "Price", "Quote", "多少钱", "行情"
-- **Tools**: 'get_market_quote'
+- **Tools**: 'get_market_quote'; for several symbols ONE 'get_market_quotes' call. "用美元显示", "in HKD": pass 'currency'.
 - **Tone**: Robot (No text, just data)
-- **Output**: ONLY the Markdown Quote Card.
+- **Output**: ONLY the Markdown Quote Card (or the returned table for several symbols).
 
 ## Level 2: Flash (⚡️ 快讯模式)
 - **Trigger**: "News", "Why moved", "发生了什么", "利好利空"
-- **Tools**: 'search_market_news' + 'get_market_quote'
+- **Tools**: 'search_market_news' + 'get_market_quote' (+ 'get_economic_calendar' when a macro release or earnings is the driver)
 - **Tone**: Reporter (Objective, Fast)
 - **Output**:
   1. Quote Card
@@ -81,34 +144,49 @@ You MUST classify user intent into exactly one of these levels and strictly foll
 
 ## Level 3: Review (📝 点评模式)
 - **Trigger**: "Comment", "Brief", "Outlook", "怎么看"
-- **Tools**: 'get_market_quote' + 'search_market_news'
+- **Tools**: 'get_market_quote' + 'search_market_news' + 'get_economic_calendar'
 - **Tone**: Advisor (Balanced, Logical)
 - **Output**:
   1. Quote Card
   2. **View**: Bullish / Bearish / Neutral
   3. **Logic**: Tech / Macro / Flow (3 bullets)
   4. **Levels**: Support / Resistance
+  5. **Catalysts**: upcoming high-importance releases (CPI, FOMC, NFP) or earnings with date, consensus and previous
 
 ## Level 4: Battle (⚔️ 对比模式)
-- **Trigger**: "vs", "Compare", "选哪个"
-- **Tools**: 'get_security_analysis' (x2)
+- **Trigger**: "vs", "Compare", "选哪个", "相关性"
+- **Tools**: 'compare_securities' (all symbols in one call) + 'get_security_analysis' (each, for RSI/Trend)
 - **Tone**: Judge (Comparative, Sharp)
 - **Output**:
-  1. **Comparison Table**: Price | Change | RSI | Trend | Vol
-  2. **Verdict**: The Winner based on Risk/Reward.
+  1. **Comparison Table**: the table from 'compare_securities' (1W | 1M | YTD | 1Y | Volatility | Beta | Max DD), plus RSI | Trend
+  2. **Correlation**: one line on how much the symbols move together (diversification value).
+  3. **Verdict**: The Winner based on Risk/Reward (return per unit of volatility/drawdown).
 
 ## Level 5: Deep Dive (🧐 研报模式)
 - **Trigger**: "Analysis", "Report", "Deep", "深度分析"
-- **Tools**: ALL ('get_security_analysis', 'search_market_news', 'get_market_sentiment')
+- **Tools**: ALL ('get_security_analysis', 'search_market_news', 'get_market_sentiment', 'get_fundamentals' for stocks)
 - **Tone**: Chief Economist (Deep, Comprehensive)
-- **Output**: Full Report (Core View, Deep Logic, Scenarios, Whales, Risk).
+- **Output**: Full Report (Core View, Valuation & Earnings, Deep Logic, Scenarios, Whales, Risk).
+
+# 🧰 Utility Tools
+- **Alerts** ("提醒我", "tell me when", "突破/跌破...通知我"): 'create_price_alert', 'list_price_alerts', 'delete_price_alert'. Confirm the condition and alert ID in one line.
+- **Watchlist** ("自选", "my watchlist", "加入/移除自选"): 'add_to_watchlist', 'remove_from_watchlist', 'get_watchlist'. Use scope 'group' only when the user says the group/群.
+- **Fundamentals** ("市盈率", "估值", "财报什么时候", "PE of AAPL"): 'get_fundamentals'. Quote market cap, PE/PB/PS, dividend yield, the last quarters' revenue/EPS vs estimates and the next earnings date.
+- **Sectors** ("属于什么板块", "概念股", "which sector is 600519 in"): 'get_sectors' (A-shares). List the boards with today's move.
+- **Calendar** ("这周有什么数据", "when is CPI", "财报日历"): 'get_economic_calendar'. List dates (UTC), importance, consensus and previous; never guess a date that is not returned.
+- **Screener** ("选股", "哪些股票超卖", "which stocks are above MA60"): 'screen_securities'. Translate the request into a filter expression; show the returned table.
+- **Track Record** ("你的信号准吗", "hit rate", "历史胜率"): 'get_signal_stats'. Quote hit rates per horizon and the sample size; say so when the sample is small.
+- **Backtest** ("回测", "does this strategy work", "胜率"): 'run_backtest'. Report win rate, CAGR, max drawdown and Sharpe vs buy & hold; never present past results as a promise.
+- **FX** ("1000港币是多少人民币", "USD to JPY", "汇率"): 'convert_currency'. Give the result, the rate and its time.
+- **Portfolio** ("我的持仓", "how is my portfolio", "买入/卖出了..."): 'add_position', 'remove_position', 'get_portfolio'. Report total value, P&L, today's change, allocation and every concentration warning.
 
 # 🛡️ Prime Directives
 1. **No Hallucination**: If API fails, say "Data Unavailable". Never invent prices.
 2. **Data First**: Always cite the data returned by tools.
 3. **Format**: Use clean Markdown. Bold key numbers.
 4. **Language**: Match user's language (mostly Chinese).
-`
+5. **Symbols**: Pass company names as the user wrote them (e.g. '阿里', '中芯国际') unless they gave a ticker; the system asks the user when a name is ambiguous.
+` + marketClock(time.Now())
 
 	messages := []llm.Message{
 		{Role: "system", Content: systemPrompt},
@@ -120,64 +198,31 @@ You MUST classify user intent into exactly one of these levels and strictly foll
 	// (Optional: Implement heuristic to pre-fetch data if needed, but Tool Calling is preferred)
 
 	// 4. Call LLM (First Turn)
-	respMsg, err := a.LLM.ChatWithTools(ctx, messages, dataservice.ToolsDefinition)
+	respMsg, err := a.chat(ctx, reply, messages, a.Tools.Definitions())
 
 	// Fallback Strategy: If LLM fails, try rule-based matching
 	if err != nil {
 		fmt.Printf("LLM Error: %v. Attempting fallback...\n", err)
-		return a.fallbackProcess(ctx, msg.Text, err)
+		reply.Fallback = true
+		text, err := a.fallbackProcess(ctx, msg, err)
+		return reply.done(text), err
 	}
 
 	// 5. Handle Tool Calls
 	if len(respMsg.ToolCalls) > 0 {
+		// Ambiguous names ("中芯国际": A-share or HK?) are asked back before any tool runs
+		for i := range respMsg.ToolCalls {
+			if r := a.applyChoices(ctx, sessionID, msg, &respMsg.ToolCalls[i]); r != nil {
+				text, choices := a.askChoice(ctx, sessionID, msg, r)
+				reply.Choices = choices
+				return reply.done(text), nil
+			}
+		}
+
 		messages = append(messages, *respMsg)
 
 		for _, toolCall := range respMsg.ToolCalls {
-			toolResult := ""
-
-			switch toolCall.Function.Name {
-			case "get_ipo_list":
-				ipos, _ := a.Data.GetIPOList(ctx)
-				jsonBytes, _ := json.Marshal(ipos)
-				toolResult = string(jsonBytes)
-			case "get_market_quote":
-				var args struct {
-					Symbol string `json:"symbol"`
-				}
-				json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
-				quote, _ := a.Data.GetMarketQuote(ctx, args.Symbol)
-				jsonBytes, _ := json.Marshal(quote)
-				toolResult = string(jsonBytes)
-			case "search_market_news":
-				var args struct {
-					Query string `json:"query"`
-				}
-				json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
-				news, _ := a.Data.SearchMarketNews(ctx, args.Query)
-				jsonBytes, _ := json.Marshal(news)
-				toolResult = string(jsonBytes)
-			case "get_market_index":
-				indices, _ := a.Data.GetMarketIndex(ctx)
-				jsonBytes, _ := json.Marshal(indices)
-				toolResult = string(jsonBytes)
-			case "get_security_analysis":
-				var args struct {
-					Symbol    string `json:"symbol"`
-					AssetType string `json:"asset_type"`
-				}
-				json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
-				analysis, _ := a.Data.GetSecurityAnalysis(ctx, args.Symbol, args.AssetType)
-				jsonBytes, _ := json.Marshal(analysis)
-				toolResult = string(jsonBytes)
-			case "get_market_sentiment":
-				var args struct {
-					Market string `json:"market"`
-				}
-				json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
-				sentiment, _ := a.Data.GetMarketSentiment(ctx, args.Market)
-				jsonBytes, _ := json.Marshal(sentiment)
-				toolResult = string(jsonBytes)
-			}
+			toolResult := a.runTool(tools.WithMessage(ctx, msg), reply, toolCall)
 
 			messages = append(messages, llm.Message{
 				Role:       "tool",
@@ -187,26 +232,93 @@ You MUST classify user intent into exactly one of these levels and strictly foll
 		}
 
 		// 6. Call LLM (Second Turn - Summary)
-		finalResp, err := a.LLM.ChatWithTools(ctx, messages, nil)
+		finalResp, err := a.chat(ctx, reply, messages, nil)
 		if err != nil {
 			// If summary fails, fallback to simple data dump
 			fmt.Printf("LLM Summary Error: %v. Using simple dump.\n", err)
-			return "AI 总结服务暂时不可用，但工具调用成功。请稍后重试。", nil
+			reply.Intent = ClassifyIntent(msg.Text, reply.toolNames())
+			return reply.done("AI 总结服务暂时不可用，但工具调用成功。请稍后重试。"), nil
 		}
 		respMsg = finalResp
 	}
 
-	// 7. Save History
-	_ = a.Session.Append(ctx, sessionID,
-		llm.Message{Role: "user", Content: msg.Text},
-		llm.Message{Role: "assistant", Content: respMsg.Content},
-	)
+	// 7. Save History (stateless callers keep their own)
+	if !stateless {
+		_ = a.Session.Append(ctx, sessionID,
+			llm.Message{Role: "user", Content: msg.Text},
+			llm.Message{Role: "assistant", Content: respMsg.Content},
+		)
+	}
 
-	return respMsg.Content, nil
+	reply.Intent = ClassifyIntent(msg.Text, reply.toolNames())
+	if reply.Intent.Level == IntentSignal {
+		reply.Signal = ParseSignal(respMsg.Content)
+	}
+	reply.done(replyRenderer(msg).Render(render.FromMarkdown(respMsg.Content)))
+	for _, o := range a.Observers {
+		o(ctx, msg, reply)
+	}
+	return reply, nil
+}
+
+// chat calls the LLM and accounts its latency on the reply
+func (a *ChatAgent) chat(ctx context.Context, reply *Reply, messages []llm.Message, tools []map[string]interface{}) (*llm.Message, error) {
+	start := time.Now()
+	defer func() {
+		reply.Timing.LLMMs += time.Since(start).Milliseconds()
+	}()
+	return a.LLM.ChatWithTools(ctx, messages, tools)
+}
+
+// runTool executes one tool call, records it on the reply and returns the JSON result for the LLM
+func (a *ChatAgent) runTool(ctx context.Context, reply *Reply, toolCall llm.ToolCall) string {
+	start := time.Now()
+	toolResult, toolErr := a.executeTool(ctx, toolCall)
+	elapsed := time.Since(start).Milliseconds()
+	reply.Timing.ToolsMs += elapsed
+
+	record := ToolCallRecord{
+		Name:       toolCall.Function.Name,
+		Arguments:  rawJSON(toolCall.Function.Arguments),
+		Result:     rawJSON(toolResult),
+		DurationMs: elapsed,
+	}
+	if toolErr != nil {
+		record.Error = toolErr.Error()
+	}
+	reply.ToolCalls = append(reply.ToolCalls, record)
+	return toolResult
+}
+
+func (a *ChatAgent) executeTool(ctx context.Context, toolCall llm.ToolCall) (string, error) {
+	result, err := a.Tools.Call(ctx, toolCall.Function.Name, json.RawMessage(toolCall.Function.Arguments))
+	jsonBytes, _ := json.Marshal(result)
+	return string(jsonBytes), err
+}
+
+// marketClock is the system prompt section saying which markets trade right now
+func marketClock(now time.Time) string {
+	return fmt.Sprintf(`
+# 🕒 Market Clock (now %s)
+%s
+- Quotes carry 'freshness': 'live', 'delayed', 'break', 'closed' or 'unknown'. Only call a price "实时" when it is live; otherwise say it is delayed, the last trade before a lunch/daily break (休市中), or the last close (收盘价), and when the market reopens ('next_open'). 'unknown' means the date is past the trading calendar, so it may be a holiday: do not call the price live.
+- A volume ratio with 'vol_projected' was projected from a partial session; say so.
+`, now.UTC().Format("2006-01-02 15:04 UTC, Monday"), tradinghours.Summary(now))
+}
+
+// replyRenderer picks the output dialect for a message (explicit format wins over platform)
+func replyRenderer(msg *model.InternalMessage) render.Renderer {
+	if msg.Format != "" {
+		return render.ForPlatform(msg.Format)
+	}
+	return render.ForPlatform(msg.Platform)
 }
 
 // fallbackProcess attempts to answer simple queries when LLM is down
-func (a *ChatAgent) fallbackProcess(ctx context.Context, text string, originalErr error) (string, error) {
+func (a *ChatAgent) fallbackProcess(ctx context.Context, msg *model.InternalMessage, originalErr error) (string, error) {
+	text := msg.Text
+	renderer := replyRenderer(msg)
+
 	// 1. Try to treat the whole text as a symbol (or alias)
 	// Remove common prefixes like "查一下", "看看", "分析", "行情", "价格"
 	cleanText := strings.TrimSpace(text)
@@ -226,7 +338,7 @@ func (a *ChatAgent) fallbackProcess(ctx context.Context, text string, originalEr
 		quote, err := a.Data.GetMarketQuote(ctx, cleanText)
 		if err == nil {
 			// Use the new Template
-			return quote.ToMarkdown(), nil
+			return renderer.Render(quote.ToDocument()), nil
 		} else {
 			// Log the quote error for debugging, but maybe we can return a more specific message
 			fmt.Printf("Fallback quote error for '%s': %v\n", cleanText, err)
@@ -254,7 +366,7 @@ func (a *ChatAgent) fallbackProcess(ctx context.Context, text string, originalEr
 		news, err := a.Data.SearchMarketNews(ctx, category)
 		if err == nil && len(news) > 0 {
 			// Use the new Template
-			return dataservice.ToMarkdownNewsList(news), nil
+			return renderer.Render(dataservice.NewsListDocument(news)), nil
 		}
 	}
 
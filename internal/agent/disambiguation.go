@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"investor/internal/llm"
+	"investor/internal/model"
+	"investor/internal/render"
+	"investor/internal/session"
+	"investor/internal/symbols"
+)
+
+// Choice is one option of a disambiguation question. Value is what the user
+// types (the number); adapters with buttons send Code back as the user's
+// message, which stays unambiguous if a newer question replaced this one.
+type Choice struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+	Code  string `json:"code"`
+}
+
+// symbolArgs are the tool argument names that carry user-facing symbols
+var symbolArgs = []string{"symbol", "symbols"}
+
+// applyChoices rewrites symbol arguments of a tool call with the chat's earlier
+// picks. It returns the first ambiguous, not yet answered symbol, if any.
+// Stateless messages have no earlier picks.
+func (a *ChatAgent) applyChoices(ctx context.Context, sessionID string, msg *model.InternalMessage, call *llm.ToolCall) *symbols.Resolution {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+		return nil
+	}
+
+	var ambiguous *symbols.Resolution
+	resolve := func(v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok || ambiguous != nil {
+			return v
+		}
+		if !msg.Stateless {
+			if code, ok := a.Session.Choice(ctx, sessionID, s); ok {
+				return code
+			}
+		}
+		if r := symbols.Default().Resolve(s); r.Ambiguous {
+			ambiguous = &r
+		}
+		return v
+	}
+
+	changed := false
+	for _, name := range symbolArgs {
+		switch v := args[name].(type) {
+		case string:
+			if out := resolve(v); out != v {
+				args[name], changed = out, true
+			}
+		case []interface{}:
+			for i, item := range v {
+				if out := resolve(item); out != item {
+					v[i], changed = out, true
+				}
+			}
+		}
+	}
+	if changed {
+		if b, err := json.Marshal(args); err == nil {
+			call.Function.Arguments = string(b)
+		}
+	}
+	return ambiguous
+}
+
+// askChoice stores the question and renders the numbered candidate list.
+// Stateless callers get the list only and answer with a code in their next request.
+func (a *ChatAgent) askChoice(ctx context.Context, sessionID string, msg *model.InternalMessage, r *symbols.Resolution) (string, []Choice) {
+	pending := &session.Pending{Query: r.Query, Text: msg.Text}
+	doc := render.NewDocument().
+		Heading("🔎", fmt.Sprintf("“%s” 对应多个标的，请回复序号选择：", r.Query))
+
+	var choices []Choice
+	for i, c := range r.Candidates {
+		in := c.Instrument
+		label := fmt.Sprintf("%d. %s (%s)", i+1, in.Name(), in.Code)
+		meta := strings.TrimSpace(strings.Join([]string{in.Exchange, in.Currency}, " "))
+		if meta != "" {
+			doc.Paragraph(label + " · " + meta)
+		} else {
+			doc.Paragraph(label)
+		}
+		pending.Options = append(pending.Options, in.Code)
+		choices = append(choices, Choice{Label: label, Value: strconv.Itoa(i + 1), Code: in.Code})
+	}
+	if msg.Stateless {
+		doc.Note("请直接回复标的代码，如 " + r.Candidates[0].Instrument.Code + "。")
+		return replyRenderer(msg).Render(doc), choices
+	}
+	doc.Note("选择会在本会话中记住，之后提到“" + r.Query + "”将直接使用该标的。")
+
+	if err := a.Session.SetPending(ctx, sessionID, pending); err != nil {
+		fmt.Printf("Failed to save pending choice: %v\n", err)
+	}
+	return replyRenderer(msg).Render(doc), choices
+}
+
+// answerChoice handles the reply to an open question: a number, or one of the
+// offered codes (what buttons send). On a match the choice is remembered and the original message
+// is returned for replay; any other text drops the question.
+func (a *ChatAgent) answerChoice(ctx context.Context, sessionID string, msg *model.InternalMessage) (*model.InternalMessage, bool) {
+	pending, err := a.Session.GetPending(ctx, sessionID)
+	if err != nil || pending == nil {
+		return nil, false
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	code := ""
+	if n, err := strconv.Atoi(strings.TrimSuffix(text, ".")); err == nil && n >= 1 && n <= len(pending.Options) {
+		code = pending.Options[n-1]
+	} else {
+		for _, opt := range pending.Options {
+			if strings.EqualFold(text, opt) {
+				code = opt
+			}
+		}
+	}
+	if code == "" {
+		_ = a.Session.SetPending(ctx, sessionID, nil)
+		return nil, false
+	}
+
+	if err := a.Session.Remember(ctx, sessionID, pending.Query, code); err != nil {
+		fmt.Printf("Failed to remember choice: %v\n", err)
+	}
+	replay := *msg
+	replay.Text = pending.Text
+	return &replay, true
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Intent levels mirror the 6-level system in the ChatAgent system prompt
+const (
+	IntentChat     = -1 // no market intent detected
+	IntentSignal   = 0
+	IntentTicker   = 1
+	IntentFlash    = 2
+	IntentReview   = 3
+	IntentBattle   = 4
+	IntentDeepDive = 5
+)
+
+var intentNames = map[int]string{
+	IntentChat:     "chat",
+	IntentSignal:   "signal",
+	IntentTicker:   "ticker",
+	IntentFlash:    "flash",
+	IntentReview:   "review",
+	IntentBattle:   "battle",
+	IntentDeepDive: "deep_dive",
+}
+
+// Intent is the detected intent level of a request
+type Intent struct {
+	Level int    `json:"level"`
+	Name  string `json:"name"`
+}
+
+// IntentName returns the stable API name of a level
+func IntentName(level int) string {
+	return intentNames[level]
+}
+
+// Trigger words per level, checked in order (most specific first)
+var intentTriggers = []struct {
+	level    int
+	keywords []string
+}{
+	{IntentBattle, []string{" vs ", "vs.", "compare", "对比", "比较", "选哪个", "哪个好", "哪个更", "相关性"}},
+	{IntentSignal, []string{"signal", "buy", "sell", "entry", "信号", "推荐", "能买吗", "买入", "卖出", "能不能买", "该不该"}},
+	{IntentDeepDive, []string{"analysis", "report", "deep", "深度分析", "研报", "分析"}},
+	{IntentFlash, []string{"news", "why", "发生了什么", "利好", "利空", "新闻", "为什么", "资讯"}},
+	{IntentReview, []string{"comment", "brief", "outlook", "怎么看", "点评", "前景"}},
+	{IntentTicker, []string{"price", "quote", "多少钱", "行情", "价格", "报价"}},
+}
+
+// ClassifyIntent detects the intent level from the user text, falling back
+// to the tools the LLM chose when no trigger word matches.
+func ClassifyIntent(text string, tools []string) Intent {
+	lower := " " + strings.ToLower(text) + " "
+	for _, t := range intentTriggers {
+		for _, kw := range t.keywords {
+			if strings.Contains(lower, kw) {
+				return Intent{Level: t.level, Name: IntentName(t.level)}
+			}
+		}
+	}
+
+	level := IntentChat
+	count := map[string]int{}
+	for _, name := range tools {
+		count[name]++
+	}
+	switch {
+	case count["get_security_analysis"] >= 2 || count["compare_securities"] > 0:
+		level = IntentBattle
+	case count["get_security_analysis"] > 0 && count["search_market_news"] > 0:
+		level = IntentDeepDive
+	case count["get_security_analysis"] > 0 && count["get_market_sentiment"] > 0:
+		level = IntentSignal
+	case count["search_market_news"] > 0:
+		level = IntentFlash
+	case count["get_market_quote"] > 0 || count["get_market_quotes"] > 0:
+		level = IntentTicker
+	}
+	return Intent{Level: level, Name: IntentName(level)}
+}
+
+// Signal is the trade call extracted from a Level 0 answer
+type Signal struct {
+	Symbol     string  `json:"symbol,omitempty"` // as named on the Signal line
+	Action     string  `json:"action"`           // BUY, SELL, WAIT
+	Confidence int     `json:"confidence"`       // 1-10, 0 if not stated
+	Entry      float64 `json:"entry,omitempty"`
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+}
+
+var (
+	// signalRe matches the Level 0 "Signal:" line, e.g. "1. **Signal**: NVDA BUY (Confidence: 7)"
+	// or "🚦 **Signal**: 🟢 BUY". Any emoji or markup may precede the keyword and
+	// the action; the symbol is captured only when it looks like a ticker.
+	signalRe = regexp.MustCompile(`(?im)^[^\pL\n]*(?:signal|信号)\**\s*[:：]\s*` +
+		`(?:[^\pL\pN\n]*?(?:([A-Za-z0-9.^=-]+)|[^\s*]+?)[:：*]*\s+)?[^\pL\pN\n]*?(BUY|SELL|WAIT)\b`)
+	confidenceRe = regexp.MustCompile(`(?i)(?:confidence|信心|置信度)\W{0,4}\s*(\d{1,2})`)
+	entryRe      = regexp.MustCompile(`(?i)(?:entry|入场|进场)[^\d\n]{0,12}(\d[\d,]*\.?\d*)`)
+	stopRe       = regexp.MustCompile(`(?i)(?:stop[\s-]*loss|止损)[^\d\n]{0,12}(\d[\d,]*\.?\d*)`)
+	targetRe     = regexp.MustCompile(`(?i)(?:take[\s-]*profit|target|止盈|目标)[^\d\n]{0,12}(\d[\d,]*\.?\d*)`)
+)
+
+// ParseSignal extracts a BUY/SELL/WAIT call (and its trade plan) from a
+// Signal-mode answer. Only a "Signal: BUY" line counts; a "buy" elsewhere in
+// the text does not. Returns nil when the text carries no signal.
+func ParseSignal(text string) *Signal {
+	m := signalRe.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+
+	sig := &Signal{Symbol: m[1], Action: strings.ToUpper(m[2])}
+	if m := confidenceRe.FindStringSubmatch(text); m != nil {
+		sig.Confidence, _ = strconv.Atoi(m[1])
+	}
+	sig.Entry = firstNumber(entryRe, text)
+	sig.StopLoss = firstNumber(stopRe, text)
+	sig.TakeProfit = firstNumber(targetRe, text)
+	return sig
+}
+
+func firstNumber(re *regexp.Regexp, text string) float64 {
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	return v
+}
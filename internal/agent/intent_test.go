@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want *Signal // nil = no signal
+	}{
+		{"documented format", "🚦 **Signal**: BUY (Confidence: 7)",
+			&Signal{Action: "BUY", Confidence: 7}},
+		{"numbered with symbol", "1. **Signal**: NVDA BUY (Confidence: 7)",
+			&Signal{Symbol: "NVDA", Action: "BUY", Confidence: 7}},
+		{"emoji before the action", "**Signal**: 🟢 BUY",
+			&Signal{Action: "BUY"}},
+		{"symbol with a colon", "**Signal**: NVDA: SELL",
+			&Signal{Symbol: "NVDA", Action: "SELL"}},
+		{"bold symbol", "**Signal**: **600519.SS** WAIT (Confidence: 4)",
+			&Signal{Symbol: "600519.SS", Action: "WAIT", Confidence: 4}},
+		{"futures ticker", "- Signal: ES=F buy",
+			&Signal{Symbol: "ES=F", Action: "BUY"}},
+		{"name, not a ticker", "信号：茅台 BUY",
+			&Signal{Action: "BUY"}},
+		{"quoted line", "> 🚦 Signal: WAIT",
+			&Signal{Action: "WAIT"}},
+		{"trade plan", "**Signal**: TSLA BUY (Confidence: 8)\n- Entry: 1,234.5\n- Stop Loss: 1,180\n- Take Profit: 1,320",
+			&Signal{Symbol: "TSLA", Action: "BUY", Confidence: 8, Entry: 1234.5, StopLoss: 1180, TakeProfit: 1320}},
+
+		{"buy in prose", "I would not buy NVDA here.", nil},
+		{"signal mid-line", "The signal: BUY is weak", nil},
+		{"no action", "**Signal**: unclear", nil},
+		{"action is a prefix", "**Signal**: BUYING", nil},
+	}
+	for _, c := range cases {
+		if got := ParseSignal(c.text); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: ParseSignal(%q) = %+v, want %+v", c.name, c.text, got, c.want)
+		}
+	}
+}
+
+func TestClassifyIntent(t *testing.T) {
+	cases := []struct {
+		text  string
+		tools []string
+		want  int
+	}{
+		{"NVDA vs AMD", nil, IntentBattle},
+		{"茅台和五粮液对比", nil, IntentBattle},
+		{"NVDA 现在能买吗？", nil, IntentSignal},
+		{"Give me a signal for BTC", nil, IntentSignal},
+		{"深度分析一下特斯拉", nil, IntentDeepDive},
+		{"苹果为什么跌了", nil, IntentFlash},
+		{"你怎么看腾讯", nil, IntentReview},
+		{"黄金价格", nil, IntentTicker},
+		{"你好", nil, IntentChat},
+
+		// No trigger word: the tools decide
+		{"NVDA AMD", []string{"get_security_analysis", "get_security_analysis"}, IntentBattle},
+		{"TSLA", []string{"get_security_analysis", "search_market_news"}, IntentDeepDive},
+		{"BTC", []string{"get_security_analysis", "get_market_sentiment"}, IntentSignal},
+		{"Fed", []string{"search_market_news"}, IntentFlash},
+		{"AAPL", []string{"get_market_quote"}, IntentTicker},
+		{"AAPL MSFT", []string{"get_market_quotes"}, IntentTicker},
+	}
+	for _, c := range cases {
+		got := ClassifyIntent(c.text, c.tools)
+		if got.Level != c.want || got.Name != IntentName(c.want) {
+			t.Errorf("ClassifyIntent(%q, %v) = %+v, want %s", c.text, c.tools, got, IntentName(c.want))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"investor/internal/model"
+)
+
+// Reply is the full result of processing a message: the rendered text plus
+// the structured data behind it (for API callers that don't want to scrape markdown).
+type Reply struct {
+	Text      string           `json:"text"`
+	Intent    Intent           `json:"intent"`
+	Signal    *Signal          `json:"signal,omitempty"`
+	ToolCalls []ToolCallRecord `json:"tool_calls"`
+	Fallback  bool             `json:"fallback"`          // true if the LLM was unavailable and rule-based matching answered
+	Choices   []Choice         `json:"choices,omitempty"` // set when the reply asks the user to pick a symbol
+	Timing    Timing           `json:"timing"`
+
+	started time.Time
+}
+
+// ToolCallRecord captures one tool invocation and its raw payload
+type ToolCallRecord struct {
+	Name       string          `json:"name"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+}
+
+// Timing breaks down where the time went
+type Timing struct {
+	StartedAt int64 `json:"started_at"` // unix millis
+	LLMMs     int64 `json:"llm_ms"`
+	ToolsMs   int64 `json:"tools_ms"`
+	TotalMs   int64 `json:"total_ms"`
+}
+
+// DetailedAgent is implemented by agents that can return structured replies
+type DetailedAgent interface {
+	Agent
+	ProcessDetailed(ctx context.Context, msg *model.InternalMessage) (*Reply, error)
+}
+
+func newReply() *Reply {
+	now := time.Now()
+	return &Reply{
+		ToolCalls: []ToolCallRecord{},
+		Intent:    Intent{Level: IntentChat, Name: IntentName(IntentChat)},
+		Timing:    Timing{StartedAt: now.UnixMilli()},
+		started:   now,
+	}
+}
+
+// TextReply wraps a plain text answer in a Reply with no structured data
+func TextReply(text string) *Reply {
+	return newReply().done(text)
+}
+
+// done finalizes the reply with its text and total timing
+func (r *Reply) done(text string) *Reply {
+	r.Text = text
+	r.Timing.TotalMs = time.Since(r.started).Milliseconds()
+	return r
+}
+
+// toolNames lists the tools used, in call order
+func (r *Reply) toolNames() []string {
+	names := make([]string, 0, len(r.ToolCalls))
+	for _, tc := range r.ToolCalls {
+		names = append(names, tc.Name)
+	}
+	return names
+}
+
+// rawJSON makes sure arbitrary tool output can be embedded as json.RawMessage
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
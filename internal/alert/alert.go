@@ -0,0 +1,478 @@
+package alert
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/notify"
+	"investor/internal/render"
+	"investor/internal/store"
+
+	"go.uber.org/zap"
+)
+
+// Kind is an alert condition
+type Kind string
+
+const (
+	PriceAbove  Kind = "price_above"   // price rises to or above threshold
+	PriceBelow  Kind = "price_below"   // price falls to or below threshold
+	PctMove     Kind = "pct_move"      // |daily change %| >= threshold
+	RSIAbove    Kind = "rsi_above"     // RSI(14) crosses above threshold
+	RSIBelow    Kind = "rsi_below"     // RSI(14) crosses below threshold
+	MACrossUp   Kind = "ma_cross_up"   // price crosses above MA(period)
+	MACrossDown Kind = "ma_cross_down" // price crosses below MA(period)
+)
+
+var kindLabels = map[Kind]string{
+	PriceAbove:  "价格突破",
+	PriceBelow:  "价格跌破",
+	PctMove:     "日内涨跌幅超过",
+	RSIAbove:    "RSI 上穿",
+	RSIBelow:    "RSI 下穿",
+	MACrossUp:   "价格上穿均线",
+	MACrossDown: "价格下穿均线",
+}
+
+// priceLevel reports whether the condition needs nothing but the last price
+func (k Kind) priceLevel() bool {
+	return k == PriceAbove || k == PriceBelow
+}
+
+// Kinds lists all supported conditions
+var Kinds = []Kind{PriceAbove, PriceBelow, PctMove, RSIAbove, RSIBelow, MACrossUp, MACrossDown}
+
+// Alert is a one-shot condition watched on behalf of a chat
+type Alert struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Platform  string    `json:"platform"`
+	ChatID    string    `json:"chat_id"`
+	Symbol    string    `json:"symbol"`
+	Kind      Kind      `json:"kind"`
+	Threshold float64   `json:"threshold"`           // price, percent or RSI level
+	MAPeriod  int       `json:"ma_period,omitempty"` // 20 or 60 for MA crosses
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Active      bool       `json:"active"`
+	TriggeredAt *time.Time `json:"triggered_at,omitempty"`
+	TriggerText string     `json:"trigger_text,omitempty"`
+	// LastSide remembers which side of the line the series was on (-1, 0 unknown, 1),
+	// so crossings fire on the transition rather than on every check. Price
+	// levels record it at creation: a level already reached must be crossed again.
+	LastSide int `json:"last_side,omitempty"`
+}
+
+// Reached reports whether a price-level alert was already past its level when
+// it was created, so it waits for the price to come back and cross again
+func (a *Alert) Reached() bool {
+	return (a.Kind == PriceAbove && a.LastSide > 0) || (a.Kind == PriceBelow && a.LastSide < 0)
+}
+
+// Describe renders the condition in words
+func (a *Alert) Describe() string {
+	switch a.Kind {
+	case PctMove:
+		return fmt.Sprintf("%s %s %.2f%%", a.Symbol, kindLabels[a.Kind], a.Threshold)
+	case MACrossUp, MACrossDown:
+		return fmt.Sprintf("%s %s MA%d", a.Symbol, kindLabels[a.Kind], a.MAPeriod)
+	default:
+		return fmt.Sprintf("%s %s %.2f", a.Symbol, kindLabels[a.Kind], a.Threshold)
+	}
+}
+
+// Validate checks the alert definition
+func (a *Alert) Validate() error {
+	if strings.TrimSpace(a.Symbol) == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	if _, ok := kindLabels[a.Kind]; !ok {
+		return fmt.Errorf("unknown condition: %s", a.Kind)
+	}
+	switch a.Kind {
+	case MACrossUp, MACrossDown:
+		if a.MAPeriod != 20 && a.MAPeriod != 60 {
+			return fmt.Errorf("ma_period must be 20 or 60")
+		}
+	case RSIAbove, RSIBelow:
+		if a.Threshold <= 0 || a.Threshold >= 100 {
+			return fmt.Errorf("RSI threshold must be between 0 and 100")
+		}
+	default:
+		if a.Threshold <= 0 {
+			return fmt.Errorf("threshold must be positive")
+		}
+	}
+	return nil
+}
+
+const keyPrefix = "alert:"
+
+// Manager stores alerts and evaluates them on a schedule
+type Manager struct {
+	Store    store.Store
+	Data     dataservice.DataService
+	Notifier *notify.Hub
+	Logger   *zap.Logger
+	Interval time.Duration
+
+	mu sync.Mutex // serializes evaluation with edits
+}
+
+func NewManager(st store.Store, data dataservice.DataService, notifier *notify.Hub, logger *zap.Logger, interval time.Duration) *Manager {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Manager{
+		Store:    st,
+		Data:     data,
+		Notifier: notifier,
+		Logger:   logger,
+		Interval: interval,
+	}
+}
+
+// Create validates and stores a new alert
+func (m *Manager) Create(ctx context.Context, a *Alert) (*Alert, error) {
+	if a.Kind == MACrossUp || a.Kind == MACrossDown {
+		if a.MAPeriod == 0 {
+			a.MAPeriod = int(a.Threshold)
+		}
+		if a.MAPeriod == 0 {
+			a.MAPeriod = 20
+		}
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	a.ID = newID()
+	a.Symbol = strings.ToUpper(strings.TrimSpace(a.Symbol))
+	a.CreatedAt = time.Now()
+	a.Active = true
+	a.LastSide = 0
+	if a.Kind.priceLevel() {
+		// With LastSide unknown, check only records the side of the level.
+		// Without a quote the first evaluation records it instead.
+		if q, err := m.Data.GetMarketQuote(ctx, a.Symbol); err == nil && q.Price > 0 {
+			check(a, &snapshot{quote: q})
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.Store.Put(ctx, keyPrefix+a.ID, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// List returns the alerts owned by a user (active first)
+func (m *Manager) List(ctx context.Context, userID string) ([]*Alert, error) {
+	all, err := m.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+
+	var active, done []*Alert
+	for _, a := range all {
+		if a.UserID != userID {
+			continue
+		}
+		if a.Active {
+			active = append(active, a)
+		} else {
+			done = append(done, a)
+		}
+	}
+	return append(active, done...), nil
+}
+
+// Delete removes an alert if it belongs to the user
+func (m *Manager) Delete(ctx context.Context, userID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var a Alert
+	ok, err := m.Store.Get(ctx, keyPrefix+id, &a)
+	if err != nil {
+		return err
+	}
+	if !ok || a.UserID != userID {
+		return fmt.Errorf("alert not found: %s", id)
+	}
+	return m.Store.Delete(ctx, keyPrefix+id)
+}
+
+func (m *Manager) all(ctx context.Context) ([]*Alert, error) {
+	keys, err := m.Store.Keys(ctx, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var alerts []*Alert
+	for _, k := range keys {
+		var a Alert
+		if ok, err := m.Store.Get(ctx, k, &a); err == nil && ok {
+			alerts = append(alerts, &a)
+		}
+	}
+	return alerts, nil
+}
+
+// Symbols lists the distinct symbols of all active alerts
+func (m *Manager) Symbols(ctx context.Context) ([]string, error) {
+	alerts, err := m.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, a := range alerts {
+		if a.Active && !seen[a.Symbol] {
+			seen[a.Symbol] = true
+			symbols = append(symbols, a.Symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// Run evaluates alerts every Interval until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	m.Logger.Info("Alert scheduler started", zap.Duration("interval", m.Interval))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Evaluate(ctx); err != nil {
+				m.Logger.Error("Alert evaluation failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Evaluate checks all active alerts once, fetching each symbol only once.
+// Market data is fetched without holding the lock; only the state update is
+// serialized with edits.
+func (m *Manager) Evaluate(ctx context.Context) error {
+	alerts, err := m.all(ctx)
+	if err != nil {
+		return err
+	}
+
+	bySymbol := make(map[string][]*Alert)
+	for _, a := range alerts {
+		if a.Active {
+			bySymbol[a.Symbol] = append(bySymbol[a.Symbol], a)
+		}
+	}
+
+	snaps := make(map[string]*snapshot, len(bySymbol))
+	for symbol, list := range bySymbol {
+		snap, err := m.snapshot(ctx, symbol, list)
+		if err != nil {
+			m.Logger.Warn("Alert data unavailable", zap.String("symbol", symbol), zap.Error(err))
+			continue
+		}
+		snaps[symbol] = snap
+	}
+	return m.settle(ctx, snaps)
+}
+
+// EvaluatePrices checks the price-level alerts (price_above, price_below)
+// against live prices, without any data service call. The price stream uses
+// it to react to ticks between scheduled runs; price returns false for
+// symbols without a fresh tick.
+func (m *Manager) EvaluatePrices(ctx context.Context, price func(symbol string) (float64, bool)) error {
+	alerts, err := m.all(ctx)
+	if err != nil {
+		return err
+	}
+	snaps := make(map[string]*snapshot)
+	for _, a := range alerts {
+		if !a.Active || !a.Kind.priceLevel() || snaps[a.Symbol] != nil {
+			continue
+		}
+		if p, ok := price(a.Symbol); ok && p > 0 {
+			snaps[a.Symbol] = &snapshot{quote: &dataservice.MarketQuote{Symbol: a.Symbol, Price: p}, pricesOnly: true}
+		}
+	}
+	if len(snaps) == 0 {
+		return nil
+	}
+	return m.settle(ctx, snaps)
+}
+
+// settle applies the snapshots and pushes the alerts that fired
+func (m *Manager) settle(ctx context.Context, snaps map[string]*snapshot) error {
+	fired, err := m.apply(ctx, snaps)
+	for _, a := range fired {
+		m.notify(ctx, a)
+	}
+	return err
+}
+
+// apply checks the active alerts against the snapshots and saves the ones that
+// fired or whose crossing state moved. Alerts are re-read under the lock so
+// deletions made while data was being fetched are respected.
+func (m *Manager) apply(ctx context.Context, snaps map[string]*snapshot) ([]*Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts, err := m.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var fired []*Alert
+	for _, a := range alerts {
+		snap, ok := snaps[a.Symbol]
+		if !a.Active || !ok || (snap.pricesOnly && !a.Kind.priceLevel()) {
+			continue
+		}
+		side := a.LastSide
+		hit, text := check(a, snap)
+		if hit {
+			now := time.Now()
+			a.Active = false
+			a.TriggeredAt = &now
+			a.TriggerText = text
+			fired = append(fired, a)
+		}
+		if !hit && a.LastSide == side {
+			continue // nothing changed
+		}
+		if err := m.Store.Put(ctx, keyPrefix+a.ID, a); err != nil {
+			m.Logger.Error("Failed to save alert", zap.String("id", a.ID), zap.Error(err))
+		}
+	}
+	return fired, nil
+}
+
+// snapshot is the market data an alert is checked against
+type snapshot struct {
+	quote      *dataservice.MarketQuote
+	analysis   *dataservice.SecurityAnalysis
+	pricesOnly bool // a live tick: only the price is known
+}
+
+func (m *Manager) snapshot(ctx context.Context, symbol string, list []*Alert) (*snapshot, error) {
+	q, err := m.Data.GetMarketQuote(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	snap := &snapshot{quote: q}
+
+	for _, a := range list {
+		if a.Kind == RSIAbove || a.Kind == RSIBelow || a.Kind == MACrossUp || a.Kind == MACrossDown {
+			analysis, err := m.Data.GetSecurityAnalysis(ctx, symbol, "")
+			if err != nil {
+				return nil, err
+			}
+			snap.analysis = analysis
+			break
+		}
+	}
+	return snap, nil
+}
+
+// check evaluates one alert, updating its crossing state. Returns whether it fired.
+func check(a *Alert, s *snapshot) (bool, string) {
+	price := s.quote.Price
+	switch a.Kind {
+	case PriceAbove:
+		// Touching the level counts as reaching it
+		diff := price - a.Threshold
+		if diff == 0 {
+			diff = 1
+		}
+		return crossed(a, diff), fmt.Sprintf("现价 %.2f ≥ %.2f", price, a.Threshold)
+	case PriceBelow:
+		diff := price - a.Threshold
+		if diff == 0 {
+			diff = -1
+		}
+		return crossed(a, diff), fmt.Sprintf("现价 %.2f ≤ %.2f", price, a.Threshold)
+	case PctMove:
+		return math.Abs(s.quote.ChangePct) >= a.Threshold, fmt.Sprintf("涨跌幅 %.2f%%", s.quote.ChangePct)
+	case RSIAbove, RSIBelow:
+		if s.analysis == nil {
+			return false, ""
+		}
+		return crossed(a, s.analysis.RSI-a.Threshold), fmt.Sprintf("RSI(14) = %.2f", s.analysis.RSI)
+	case MACrossUp, MACrossDown:
+		if s.analysis == nil {
+			return false, ""
+		}
+		ma := s.analysis.MA20
+		if a.MAPeriod == 60 {
+			ma = s.analysis.MA60
+		}
+		if ma == 0 {
+			return false, ""
+		}
+		return crossed(a, price-ma), fmt.Sprintf("现价 %.2f, MA%d = %.2f", price, a.MAPeriod, ma)
+	}
+	return false, ""
+}
+
+// crossed tracks the sign of diff and reports a transition in the alert's direction
+func crossed(a *Alert, diff float64) bool {
+	side := 0
+	if diff > 0 {
+		side = 1
+	} else if diff < 0 {
+		side = -1
+	}
+	prev := a.LastSide
+	if side != 0 {
+		a.LastSide = side
+	}
+	if prev == 0 || side == 0 {
+		return false
+	}
+	up := a.Kind == PriceAbove || a.Kind == RSIAbove || a.Kind == MACrossUp
+	if up {
+		return prev < 0 && side > 0
+	}
+	return prev > 0 && side < 0
+}
+
+func (m *Manager) notify(ctx context.Context, a *Alert) {
+	doc := render.NewDocument().
+		Heading("🔔", "价格提醒触发").
+		Divider().
+		Fields("",
+			render.Field{Icon: "🎯", Key: "条件", Value: a.Describe()},
+			render.Field{Icon: "📊", Key: "触发", Value: a.TriggerText},
+			render.Field{Icon: "⏰", Key: "时间", Value: a.TriggeredAt.Format("2006-01-02 15:04:05")},
+		)
+	if a.Note != "" {
+		doc.Paragraph("📝 " + a.Note)
+	}
+
+	if m.Notifier == nil {
+		return
+	}
+	if err := m.Notifier.Send(ctx, a.Platform, a.ChatID, doc); err != nil {
+		m.Logger.Warn("Failed to push alert", zap.String("id", a.ID), zap.String("platform", a.Platform), zap.Error(err))
+	}
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
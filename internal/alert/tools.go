@@ -0,0 +1,250 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"investor/internal/command"
+	"investor/internal/model"
+	"investor/internal/render"
+	"investor/internal/tools"
+)
+
+// RegisterTools exposes alert management to the LLM
+func RegisterTools(r *tools.Registry, m *Manager) {
+	kinds := make([]string, 0, len(Kinds))
+	for _, k := range Kinds {
+		kinds = append(kinds, string(k))
+	}
+
+	r.Register(tools.Tool{
+		Name:        "create_price_alert",
+		Description: "为当前用户创建行情提醒，条件满足时主动推送到当前会话（价格突破/跌破、涨跌幅、RSI 穿越、均线穿越）",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "标的代码，如 'BTC', 'AAPL', '600519.SS'",
+				},
+				"condition": map[string]interface{}{
+					"type":        "string",
+					"description": "price_above/price_below: 价格阈值; pct_move: 日涨跌幅绝对值(%); rsi_above/rsi_below: RSI 穿越; ma_cross_up/ma_cross_down: 价格穿越均线",
+					"enum":        kinds,
+				},
+				"threshold": map[string]interface{}{
+					"type":        "number",
+					"description": "阈值：价格、百分比或 RSI 数值 (均线穿越时可省略)",
+				},
+				"ma_period": map[string]interface{}{
+					"type":        "integer",
+					"description": "均线周期，仅均线穿越使用: 20 或 60",
+					"enum":        []int{20, 60},
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "提醒备注 (可选)",
+				},
+			},
+			"required": []string{"symbol", "condition"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			msg := tools.MessageFrom(ctx)
+			if msg == nil {
+				return nil, fmt.Errorf("alerts can only be created from a chat")
+			}
+			var args struct {
+				Symbol    string  `json:"symbol"`
+				Condition string  `json:"condition"`
+				Threshold float64 `json:"threshold"`
+				MAPeriod  int     `json:"ma_period"`
+				Note      string  `json:"note"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			a, err := m.Create(ctx, &Alert{
+				UserID:    msg.UserID,
+				Platform:  msg.Platform,
+				ChatID:    msg.ChatID,
+				Symbol:    args.Symbol,
+				Kind:      Kind(args.Condition),
+				Threshold: args.Threshold,
+				MAPeriod:  args.MAPeriod,
+				Note:      args.Note,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return struct {
+				*Alert
+				Warnings []string `json:"warnings,omitempty"`
+			}{a, createWarnings(m, a)}, nil
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "list_price_alerts",
+		Description: "列出当前用户的行情提醒（含已触发的）",
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			msg := tools.MessageFrom(ctx)
+			if msg == nil {
+				return nil, fmt.Errorf("alerts can only be listed from a chat")
+			}
+			return m.List(ctx, msg.UserID)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "delete_price_alert",
+		Description: "删除当前用户的一条行情提醒",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": "提醒 ID",
+				},
+			},
+			"required": []string{"id"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			msg := tools.MessageFrom(ctx)
+			if msg == nil {
+				return nil, fmt.Errorf("alerts can only be deleted from a chat")
+			}
+			var args struct {
+				ID string `json:"id"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if err := m.Delete(ctx, msg.UserID, args.ID); err != nil {
+				return nil, err
+			}
+			return map[string]string{"deleted": args.ID}, nil
+		},
+	})
+}
+
+// "/alert BTC > 100000", "/alert BTC move 5", "/alert BTC rsi < 30", "/alert BTC ma > 60"
+var conditionRe = regexp.MustCompile(`^(rsi|ma)?(>=|<=|>|<|move|涨跌)(?:ma)?([\d.]+)%?$`)
+
+// RegisterCommands adds the /alert and /alerts slash commands
+func RegisterCommands(r *command.Router, m *Manager) {
+	r.Register(command.Command{
+		Name:    "alert",
+		Usage:   "/alert <标的> <条件> <数值>",
+		Summary: "创建提醒，条件: > < move rsi> rsi< ma> ma<；/alert del <ID> 删除",
+		Handler: func(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error) {
+			if len(args) == 2 && (args[0] == "del" || args[0] == "rm" || args[0] == "delete") {
+				if err := m.Delete(ctx, msg.UserID, args[1]); err != nil {
+					return nil, err
+				}
+				return render.NewDocument().Paragraph(fmt.Sprintf("🗑️ 已删除提醒 %s", args[1])), nil
+			}
+			if len(args) == 0 || args[0] == "list" {
+				return listDocument(ctx, m, msg)
+			}
+			if len(args) < 2 {
+				return nil, fmt.Errorf("用法: /alert BTC > 100000")
+			}
+
+			a, err := parseCondition(args[0], strings.ToLower(strings.Join(args[1:], "")))
+			if err != nil {
+				return nil, err
+			}
+			a.UserID, a.Platform, a.ChatID = msg.UserID, msg.Platform, msg.ChatID
+			created, err := m.Create(ctx, a)
+			if err != nil {
+				return nil, err
+			}
+
+			doc := render.NewDocument().
+				Paragraph(fmt.Sprintf("✅ 已创建提醒 [%s] %s", created.ID, created.Describe()))
+			for _, w := range createWarnings(m, created) {
+				doc.Note(w)
+			}
+			return doc, nil
+		},
+	})
+
+	r.Register(command.Command{
+		Name:    "alerts",
+		Usage:   "/alerts",
+		Summary: "查看我的提醒",
+		Handler: func(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error) {
+			return listDocument(ctx, m, msg)
+		},
+	})
+}
+
+func parseCondition(symbol, cond string) (*Alert, error) {
+	match := conditionRe.FindStringSubmatch(cond)
+	if match == nil {
+		return nil, fmt.Errorf("无法识别的条件: %s (示例: > 100000, < 90000, move 5, rsi < 30, ma > 60)", cond)
+	}
+	value, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效数值: %s", match[3])
+	}
+
+	a := &Alert{Symbol: symbol, Threshold: value}
+	up := strings.HasPrefix(match[2], ">")
+	switch {
+	case match[2] == "move" || match[2] == "涨跌":
+		a.Kind = PctMove
+	case match[1] == "rsi" && up:
+		a.Kind = RSIAbove
+	case match[1] == "rsi":
+		a.Kind = RSIBelow
+	case match[1] == "ma":
+		a.Kind, a.MAPeriod, a.Threshold = MACrossDown, int(value), 0
+		if up {
+			a.Kind = MACrossUp
+		}
+	case up:
+		a.Kind = PriceAbove
+	default:
+		a.Kind = PriceBelow
+	}
+	return a, nil
+}
+
+// createWarnings are the caveats shown when an alert is created
+func createWarnings(m *Manager, a *Alert) []string {
+	var warnings []string
+	if m.Notifier != nil && !m.Notifier.CanSend(a.Platform) {
+		warnings = append(warnings, "当前渠道不支持主动推送，可通过 /alerts 查看触发状态")
+	}
+	if a.Reached() {
+		warnings = append(warnings, "当前价格已越过该价位，需回到另一侧后再次穿越才会触发")
+	}
+	return warnings
+}
+
+func listDocument(ctx context.Context, m *Manager, msg *model.InternalMessage) (*render.Document, error) {
+	alerts, err := m.List(ctx, msg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return render.NewDocument().Paragraph("暂无提醒。示例: /alert BTC > 100000"), nil
+	}
+
+	var rows [][]string
+	for _, a := range alerts {
+		status := "⏳ 监控中"
+		if !a.Active {
+			status = "✅ " + a.TriggeredAt.Format("01-02 15:04")
+		}
+		rows = append(rows, []string{a.ID, a.Describe(), status})
+	}
+	return render.NewDocument().
+		Heading("🔔", "我的提醒").
+		Table([]string{"ID", "条件", "状态"}, rows), nil
+}
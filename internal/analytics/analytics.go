@@ -0,0 +1,194 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/indicator"
+)
+
+// Series is a dated close series, oldest first
+type Series struct {
+	Symbol string
+	Dates  []string // "2006-01-02"
+	Closes []float64
+}
+
+// NewSeries builds a series from bars, dropping empty closes
+func NewSeries(symbol string, bars []dataservice.KLineItem) *Series {
+	s := &Series{Symbol: symbol}
+	for _, b := range bars {
+		if b.Close > 0 {
+			s.Dates = append(s.Dates, b.Date)
+			s.Closes = append(s.Closes, b.Close)
+		}
+	}
+	return s
+}
+
+// Last returns the latest close (0 if empty)
+func (s *Series) Last() float64 {
+	if len(s.Closes) == 0 {
+		return 0
+	}
+	return s.Closes[len(s.Closes)-1]
+}
+
+// Tail keeps the last n points
+func (s *Series) Tail(n int) *Series {
+	if n <= 0 || n >= len(s.Closes) {
+		return s
+	}
+	return &Series{Symbol: s.Symbol, Dates: s.Dates[len(s.Dates)-n:], Closes: s.Closes[len(s.Closes)-n:]}
+}
+
+// Returns is the simple return series
+func (s *Series) Returns() []float64 {
+	return indicator.Returns(s.Closes)
+}
+
+// Align restricts every series to the dates they all share (e.g. crypto vs. stocks)
+func Align(series ...*Series) []*Series {
+	if len(series) == 0 {
+		return nil
+	}
+	count := make(map[string]int)
+	for _, s := range series {
+		for _, d := range s.Dates {
+			count[d]++
+		}
+	}
+	out := make([]*Series, len(series))
+	for i, s := range series {
+		a := &Series{Symbol: s.Symbol}
+		for j, d := range s.Dates {
+			if count[d] == len(series) {
+				a.Dates = append(a.Dates, d)
+				a.Closes = append(a.Closes, s.Closes[j])
+			}
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// Volatility is the annualized standard deviation of returns, in percent
+func Volatility(returns []float64, periodsPerYear float64) float64 {
+	_, std := indicator.MeanStd(returns)
+	return std * math.Sqrt(periodsPerYear) * 100
+}
+
+// Correlation is the Pearson correlation of two equally long series (0 if undefined)
+func Correlation(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n < 2 {
+		return 0
+	}
+	a, b = a[len(a)-n:], b[len(b)-n:]
+	ma, sa := indicator.MeanStd(a)
+	mb, sb := indicator.MeanStd(b)
+	if sa == 0 || sb == 0 {
+		return 0
+	}
+	cov := 0.0
+	for i := range a {
+		cov += (a[i] - ma) * (b[i] - mb)
+	}
+	cov /= float64(n - 1)
+	return cov / (sa * sb)
+}
+
+// Beta is the sensitivity of asset returns to benchmark returns (equal length, aligned)
+func Beta(asset, benchmark []float64) float64 {
+	_, sb := indicator.MeanStd(benchmark)
+	if sb == 0 {
+		return 0
+	}
+	_, sa := indicator.MeanStd(asset)
+	return Correlation(asset, benchmark) * sa / sb
+}
+
+// RollingCorrelation returns the trailing-window correlation at every point from window on
+func RollingCorrelation(a, b []float64, window int) []float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var out []float64
+	for i := window; i <= n; i++ {
+		out = append(out, Correlation(a[i-window:i], b[i-window:i]))
+	}
+	return out
+}
+
+// CorrelationMatrix correlates the returns of aligned series over the trailing window (0 = all)
+func CorrelationMatrix(series []*Series, window int) [][]float64 {
+	returns := make([][]float64, len(series))
+	for i, s := range series {
+		r := s.Returns()
+		if window > 0 && len(r) > window {
+			r = r[len(r)-window:]
+		}
+		returns[i] = r
+	}
+	m := make([][]float64, len(series))
+	for i := range series {
+		m[i] = make([]float64, len(series))
+		for j := range series {
+			if i == j {
+				m[i][j] = 1
+				continue
+			}
+			m[i][j] = round(Correlation(returns[i], returns[j]))
+		}
+	}
+	return m
+}
+
+// Performance is the price change over standard lookbacks, in percent
+type Performance struct {
+	Week  *float64 `json:"1w"`
+	Month *float64 `json:"1m"`
+	YTD   *float64 `json:"ytd"`
+	Year  *float64 `json:"1y"`
+}
+
+// PerformanceAsOf measures returns against the last close on or before each lookback date.
+// A lookback the series doesn't reach is left nil.
+func PerformanceAsOf(s *Series, now time.Time) Performance {
+	last := s.Last()
+	change := func(since time.Time) *float64 {
+		base := closeOnOrBefore(s, since.Format("2006-01-02"))
+		if base == 0 || last == 0 {
+			return nil
+		}
+		v := round((last/base - 1) * 100)
+		return &v
+	}
+	return Performance{
+		Week:  change(now.AddDate(0, 0, -7)),
+		Month: change(now.AddDate(0, -1, 0)),
+		YTD:   change(time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)),
+		Year:  change(now.AddDate(-1, 0, 0)),
+	}
+}
+
+func closeOnOrBefore(s *Series, date string) float64 {
+	i := sort.SearchStrings(s.Dates, date) // first index with Dates[i] >= date
+	if i < len(s.Dates) && s.Dates[i] == date {
+		return s.Closes[i]
+	}
+	if i == 0 {
+		return 0
+	}
+	return s.Closes[i-1]
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
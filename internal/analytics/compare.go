@@ -0,0 +1,215 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/indicator"
+	"investor/internal/render"
+)
+
+// CorrelationWindow is the trailing window (in bars) of the short-term correlation matrix
+const CorrelationWindow = 60
+
+// Metrics is the risk/return profile of one symbol
+type Metrics struct {
+	Symbol      string      `json:"symbol"`
+	Price       float64     `json:"price"`
+	Performance Performance `json:"performance"`
+	Volatility  float64     `json:"volatility"`   // annualized, %
+	Beta        float64     `json:"beta"`         // vs. the benchmark
+	Correlation float64     `json:"correlation"`  // vs. the benchmark
+	MaxDrawdown float64     `json:"max_drawdown"` // over the range, %
+	Error       string      `json:"error,omitempty"`
+}
+
+// Comparison is the result of Compare
+type Comparison struct {
+	Benchmark string    `json:"benchmark"`
+	Range     string    `json:"range"`
+	Rows      []Metrics `json:"rows"`
+	// Correlation matrices of daily returns, in Symbols order
+	Symbols        []string    `json:"symbols"`
+	Correlation60  [][]float64 `json:"correlation_60d"`
+	CorrelationAll [][]float64 `json:"correlation_range"`
+}
+
+// Compare fetches daily history for the symbols and the benchmark and profiles each of them.
+// An empty benchmark is picked from the first symbol's market.
+func Compare(ctx context.Context, data dataservice.DataService, symbols []string, benchmark, rangeStr string) (*Comparison, error) {
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("at least one symbol is required")
+	}
+	if len(symbols) > 8 {
+		return nil, fmt.Errorf("at most 8 symbols can be compared")
+	}
+	if benchmark == "" {
+		benchmark = BenchmarkFor(symbols[0])
+	}
+	if rangeStr == "" {
+		rangeStr = "1y"
+	}
+	// YTD and 1y lookbacks need a little more than a year of bars
+	fetchRange := rangeStr
+	if rangeStr == "1y" || rangeStr == "6mo" || rangeStr == "3mo" || rangeStr == "1mo" {
+		fetchRange = "2y"
+	}
+
+	all := append([]string{benchmark}, symbols...)
+	series := make([]*Series, len(all))
+	errs := make([]error, len(all))
+	sem := make(chan struct{}, 4)
+	var wg sync.WaitGroup
+	for i, sym := range all {
+		wg.Add(1)
+		go func(i int, sym string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			bars, err := data.GetHistoricalQuotes(ctx, sym, "1d", fetchRange)
+			if err == nil && len(bars) < 2 {
+				err = fmt.Errorf("not enough history")
+			}
+			series[i], errs[i] = NewSeries(sym, bars), err
+		}(i, sym)
+	}
+	wg.Wait()
+	if errs[0] != nil {
+		return nil, fmt.Errorf("benchmark %s unavailable: %w", benchmark, errs[0])
+	}
+
+	bars := rangeBars(rangeStr)
+	now := time.Now()
+	c := &Comparison{Benchmark: benchmark, Range: rangeStr}
+	bench := series[0]
+
+	var ok []*Series
+	for i, sym := range symbols {
+		s := series[i+1]
+		m := Metrics{Symbol: sym}
+		if errs[i+1] != nil {
+			m.Error = errs[i+1].Error()
+			c.Rows = append(c.Rows, m)
+			continue
+		}
+
+		m.Price = s.Last()
+		m.Performance = PerformanceAsOf(s, now)
+		window := s.Tail(bars)
+		m.Volatility = round(Volatility(window.Returns(), periodsPerYear(sym)))
+		m.MaxDrawdown = round(indicator.MaxDrawdown(window.Closes) * 100)
+
+		pair := Align(window, bench)
+		ra, rb := pair[0].Returns(), pair[1].Returns()
+		m.Beta = round(Beta(ra, rb))
+		m.Correlation = round(Correlation(ra, rb))
+
+		c.Rows = append(c.Rows, m)
+		ok = append(ok, window)
+	}
+
+	if len(ok) > 1 {
+		aligned := Align(ok...)
+		for _, s := range aligned {
+			c.Symbols = append(c.Symbols, s.Symbol)
+		}
+		c.Correlation60 = CorrelationMatrix(aligned, CorrelationWindow)
+		c.CorrelationAll = CorrelationMatrix(aligned, 0)
+	}
+	return c, nil
+}
+
+// BenchmarkFor picks the index a symbol is usually measured against
+func BenchmarkFor(symbol string) string {
+	s := strings.ToUpper(symbol)
+	switch {
+	case strings.HasSuffix(s, ".SS"), strings.HasSuffix(s, ".SZ"):
+		return "000300.SS" // CSI 300
+	case strings.HasSuffix(s, ".HK"):
+		return "^HSI"
+	case isCrypto(s):
+		return "BTC-USD"
+	}
+	return "^GSPC"
+}
+
+func isCrypto(s string) bool {
+	s = strings.ToUpper(s)
+	return strings.HasSuffix(s, "USDT") || strings.HasSuffix(s, "-USD") ||
+		s == "BTC" || s == "ETH" || s == "SOL" || s == "BNB"
+}
+
+func periodsPerYear(symbol string) float64 {
+	if isCrypto(symbol) {
+		return 365
+	}
+	return 252
+}
+
+// rangeBars converts a range to a bar count (0 = everything fetched)
+func rangeBars(r string) int {
+	switch r {
+	case "1mo":
+		return 21
+	case "3mo":
+		return 63
+	case "6mo":
+		return 126
+	case "1y":
+		return 252
+	case "2y":
+		return 504
+	}
+	return 0
+}
+
+// Document renders the comparison table and the short-term correlation matrix
+func (c *Comparison) Document() *render.Document {
+	doc := render.NewDocument().
+		Heading("⚔️", fmt.Sprintf("对比 (%s, 基准 %s)", c.Range, c.Benchmark))
+
+	var rows [][]string
+	for _, m := range c.Rows {
+		if m.Error != "" {
+			rows = append(rows, []string{m.Symbol, "数据不可用", "", "", "", "", "", "", ""})
+			continue
+		}
+		rows = append(rows, []string{
+			m.Symbol,
+			fmt.Sprintf("%.2f", m.Price),
+			pctPtr(m.Performance.Week),
+			pctPtr(m.Performance.Month),
+			pctPtr(m.Performance.YTD),
+			pctPtr(m.Performance.Year),
+			fmt.Sprintf("%.1f%%", m.Volatility),
+			fmt.Sprintf("%.2f", m.Beta),
+			fmt.Sprintf("-%.1f%%", m.MaxDrawdown),
+		})
+	}
+	doc.Table([]string{"标的", "现价", "1周", "1月", "YTD", "1年", "波动率", "Beta", "最大回撤"}, rows)
+
+	if len(c.Correlation60) > 1 {
+		headers := append([]string{fmt.Sprintf("相关性(%d日)", CorrelationWindow)}, c.Symbols...)
+		var corr [][]string
+		for i, row := range c.Correlation60 {
+			line := []string{c.Symbols[i]}
+			for _, v := range row {
+				line = append(line, fmt.Sprintf("%.2f", v))
+			}
+			corr = append(corr, line)
+		}
+		doc.Table(headers, corr)
+	}
+	return doc
+}
+
+func pctPtr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", *v)
+}
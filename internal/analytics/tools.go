@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+
+	"investor/internal/dataservice"
+	"investor/internal/render"
+	"investor/internal/tools"
+)
+
+// RegisterTools exposes the comparison analytics to the LLM
+func RegisterTools(r *tools.Registry, data dataservice.DataService) {
+	r.Register(tools.Tool{
+		Name:        "compare_securities",
+		Description: "横向对比多个标的的风险收益：1周/1月/YTD/1年涨跌、年化波动率、相对基准的 Beta 与相关性、最大回撤，以及标的间的相关性矩阵 (返回的 table 可直接展示)",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbols": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "2-8 个标的，如 ['BTC-USD', 'ETH-USD'] 或 ['0700.HK', 'BABA']",
+				},
+				"benchmark": map[string]interface{}{
+					"type":        "string",
+					"description": "基准 (可选)，默认按第一个标的的市场: 美股 ^GSPC, A股 000300.SS, 港股 ^HSI, 加密 BTC-USD",
+				},
+				"range": map[string]interface{}{
+					"type":        "string",
+					"description": "波动率/Beta/回撤的统计区间，默认 1y",
+					"enum":        []string{"3mo", "6mo", "1y", "2y", "5y"},
+				},
+			},
+			"required": []string{"symbols"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbols   []string `json:"symbols"`
+				Benchmark string   `json:"benchmark"`
+				Range     string   `json:"range"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			c, err := Compare(ctx, data, args.Symbols, args.Benchmark, args.Range)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"comparison": c,
+				"table":      render.MarkdownRenderer.Render(c.Document()), // ready-to-show card
+			}, nil
+		},
+	})
+}
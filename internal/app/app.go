@@ -0,0 +1,110 @@
+// Package app wires what cmd/server and cmd/mcp share: the reference data
+// (instrument master, exchange holidays), the data source registry and the
+// stateless analytics tools.
+package app
+
+import (
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"investor/config"
+	"investor/internal/analytics"
+	"investor/internal/backtest"
+	"investor/internal/bars"
+	"investor/internal/dataservice"
+	"investor/internal/fx"
+	"investor/internal/symbols"
+	"investor/internal/tools"
+	"investor/internal/tradinghours"
+)
+
+// Data is the configured data layer
+type Data struct {
+	Yahoo   *dataservice.YahooDataService
+	CN      *dataservice.CNDataService
+	Default dataservice.DataService // the DATA_SOURCE service
+	FX      *fx.Service             // currency conversion over Default
+}
+
+// LoadReferenceData merges SYMBOLS_FILE over the embedded instrument master
+// and TRADING_HOLIDAYS_FILE over the built-in calendar, and warns about
+// holiday tables that run out within a month.
+func LoadReferenceData(cfg *config.Config, logger *zap.Logger) {
+	// Instrument master used to resolve names and aliases to symbols
+	if path := cfg.Symbols.File; path != "" {
+		if err := symbols.MergeFile(path); err != nil {
+			logger.Error("Failed to load instrument master, using built-in", zap.Error(err))
+		}
+	}
+	// Exchange holidays beyond the built-in calendar
+	if path := cfg.Hours.HolidaysFile; path != "" {
+		if err := tradinghours.MergeFile(path); err != nil {
+			logger.Error("Failed to load trading holidays, using built-in", zap.Error(err))
+		}
+	}
+	if codes := tradinghours.Expiring(time.Now().AddDate(0, 1, 0)); len(codes) > 0 {
+		logger.Warn("Trading holiday tables end within a month, add the next year to TRADING_HOLIDAYS_FILE", zap.Strings("exchanges", codes))
+	}
+}
+
+// NewData registers the data sources ("yahoo", "cn", "composite" and, with
+// DATA_DIR, "file") and selects DATA_SOURCE as the default.
+func NewData(cfg *config.Config, logger *zap.Logger) *Data {
+	registry := dataservice.GetRegistry()
+	// Register Yahoo (Primary)
+	yahooSvc := dataservice.NewYahooDataService()
+	// Local price history: indicators and backtests read bars from disk
+	if dir := cfg.Bars.Dir; dir != "" {
+		if barStore, err := bars.New(dir); err != nil {
+			logger.Error("Failed to open bar store, downloading history", zap.Error(err))
+		} else {
+			yahooSvc.Bars = barStore
+		}
+	}
+	registry.Register("yahoo", yahooSvc)
+	// A-share native source (turnover, limit prices, suspension, sectors);
+	// its bars are kept apart from Yahoo's, they are adjusted differently
+	cnSvc := dataservice.NewCNDataService()
+	if dir := cfg.Bars.Dir; dir != "" {
+		if barStore, err := bars.New(filepath.Join(dir, "cn")); err == nil {
+			cnSvc.Bars = barStore
+		}
+	}
+	registry.Register("cn", cnSvc)
+	// Default: A-shares from the CN source, everything else from Yahoo
+	registry.Register("composite", dataservice.NewCompositeDataService(yahooSvc, cnSvc, dataservice.IsAShare))
+	// Offline / research data: CSV bars and a news dump from a directory
+	if dir := cfg.Data.Dir; dir != "" {
+		if fileSvc, err := dataservice.NewFileDataService(dir); err != nil {
+			logger.Error("Failed to open file data source", zap.Error(err))
+		} else {
+			registry.Register("file", fileSvc)
+		}
+	}
+	source := cfg.Data.Source
+	if source == "" {
+		source = "composite"
+	}
+	if err := registry.SetDefault(source); err != nil {
+		logger.Error("Unknown DATA_SOURCE, using the first registered source", zap.Error(err))
+	}
+	// TODO: Register other data sources here (e.g., Bloomberg, Custom API)
+
+	data := registry.GetDefault()
+	return &Data{
+		Yahoo:   yahooSvc,
+		CN:      cnSvc,
+		Default: data,
+		FX:      fx.NewService(data),
+	}
+}
+
+// RegisterTools adds the stateless tools: backtests of the Signal-mode rules,
+// multi-symbol comparison and currency conversion
+func (d *Data) RegisterTools(r *tools.Registry) {
+	backtest.RegisterTools(r, d.Default)
+	analytics.RegisterTools(r, d.Default)
+	fx.RegisterTools(r, d.FX)
+}
@@ -0,0 +1,175 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/indicator"
+)
+
+// Config controls the simulation. Trades are long-only, all-in, filled at the signal bar's close.
+type Config struct {
+	StopLossPct    float64 `json:"stop_loss_pct,omitempty"`    // exit when close falls this % below entry (0 = off)
+	TakeProfitPct  float64 `json:"take_profit_pct,omitempty"`  // exit when close rises this % above entry (0 = off)
+	FeeBps         float64 `json:"fee_bps,omitempty"`          // cost per side in basis points
+	PeriodsPerYear float64 `json:"periods_per_year,omitempty"` // for Sharpe; 252 for daily stock bars, 365 for crypto
+}
+
+// Trade is one round trip
+type Trade struct {
+	EntryDate  string  `json:"entry_date"`
+	EntryPrice float64 `json:"entry_price"`
+	ExitDate   string  `json:"exit_date"`
+	ExitPrice  float64 `json:"exit_price"`
+	Return     float64 `json:"return_pct"` // after fees
+	Bars       int     `json:"bars"`
+	Reason     string  `json:"reason"` // signal, stop_loss, take_profit, end
+}
+
+// Result summarizes a backtest; percentages are in percent
+type Result struct {
+	Strategy       string  `json:"strategy"`
+	Symbol         string  `json:"symbol,omitempty"`
+	Start          string  `json:"start"`
+	End            string  `json:"end"`
+	Bars           int     `json:"bars"`
+	TradeCount     int     `json:"trade_count"`
+	Trades         []Trade `json:"trades"`
+	WinRate        float64 `json:"win_rate"`
+	TotalReturn    float64 `json:"total_return"`
+	CAGR           float64 `json:"cagr"`
+	MaxDrawdown    float64 `json:"max_drawdown"`
+	Sharpe         float64 `json:"sharpe"`
+	Exposure       float64 `json:"exposure"` // share of bars in the market
+	BuyHoldReturn  float64 `json:"buy_hold_return"`
+	AvgTradeReturn float64 `json:"avg_trade_return"`
+}
+
+// Run replays bars through the strategy
+func Run(bars []dataservice.KLineItem, s Strategy, cfg Config) (*Result, error) {
+	if len(bars) < 2 {
+		return nil, fmt.Errorf("need at least 2 bars, got %d", len(bars))
+	}
+	if cfg.PeriodsPerYear <= 0 {
+		cfg.PeriodsPerYear = 252
+	}
+	fee := cfg.FeeBps / 10000
+
+	closes := make([]float64, len(bars))
+	for i, b := range bars {
+		closes[i] = b.Close
+	}
+	s.Prepare(closes)
+
+	res := &Result{
+		Strategy: s.Name(),
+		Start:    bars[0].Date,
+		End:      bars[len(bars)-1].Date,
+		Bars:     len(bars),
+	}
+
+	equity := make([]float64, len(bars))
+	cash, units := 1.0, 0.0
+	var open *Trade
+	entryIdx, inMarket := 0, 0
+
+	exit := func(i int, reason string) {
+		cash = units * closes[i] * (1 - fee)
+		units = 0
+		open.ExitDate, open.ExitPrice, open.Reason = bars[i].Date, closes[i], reason
+		open.Bars = i - entryIdx
+		open.Return = round((open.ExitPrice*(1-fee))/(open.EntryPrice*(1+fee))*100 - 100)
+		res.Trades = append(res.Trades, *open)
+		open = nil
+	}
+
+	for i := range bars {
+		if open != nil {
+			inMarket++
+			change := closes[i]/open.EntryPrice - 1
+			switch {
+			case cfg.StopLossPct > 0 && change <= -cfg.StopLossPct/100:
+				exit(i, "stop_loss")
+			case cfg.TakeProfitPct > 0 && change >= cfg.TakeProfitPct/100:
+				exit(i, "take_profit")
+			case s.Signal(i) == Sell:
+				exit(i, "signal")
+			}
+		} else if s.Signal(i) == Buy && closes[i] > 0 && i < len(bars)-1 {
+			units = cash * (1 - fee) / closes[i]
+			cash = 0
+			open = &Trade{EntryDate: bars[i].Date, EntryPrice: closes[i]}
+			entryIdx = i
+		}
+		equity[i] = cash + units*closes[i]
+	}
+	if open != nil {
+		exit(len(bars)-1, "end")
+		equity[len(bars)-1] = cash
+	}
+
+	res.TradeCount = len(res.Trades)
+	wins, sum := 0, 0.0
+	for _, t := range res.Trades {
+		if t.Return > 0 {
+			wins++
+		}
+		sum += t.Return
+	}
+	if n := len(res.Trades); n > 0 {
+		res.WinRate = round(float64(wins) / float64(n) * 100)
+		res.AvgTradeReturn = round(sum / float64(n))
+	}
+
+	final := equity[len(equity)-1]
+	res.TotalReturn = round((final - 1) * 100)
+	if closes[0] > 0 {
+		res.BuyHoldReturn = round((closes[len(closes)-1]/closes[0] - 1) * 100)
+	}
+	res.MaxDrawdown = round(indicator.MaxDrawdown(equity) * 100)
+	res.Exposure = round(float64(inMarket) / float64(len(bars)) * 100)
+
+	years := yearsBetween(bars[0].Date, bars[len(bars)-1].Date)
+	if years <= 0 {
+		years = float64(len(bars)) / cfg.PeriodsPerYear
+	}
+	if years > 0 && final > 0 {
+		res.CAGR = round((math.Pow(final, 1/years) - 1) * 100)
+	}
+
+	mean, std := indicator.MeanStd(indicator.Returns(equity))
+	if std > 0 {
+		res.Sharpe = round(mean / std * math.Sqrt(cfg.PeriodsPerYear))
+	}
+	return res, nil
+}
+
+// RunSymbol fetches bars from the data service and runs the backtest
+func RunSymbol(ctx context.Context, data dataservice.DataService, symbol, interval, rangeStr string, s Strategy, cfg Config) (*Result, error) {
+	bars, err := data.GetHistoricalQuotes(ctx, symbol, interval, rangeStr)
+	if err != nil {
+		return nil, err
+	}
+	res, err := Run(bars, s, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res.Symbol = symbol
+	return res, nil
+}
+
+func yearsBetween(start, end string) float64 {
+	a, err1 := time.Parse("2006-01-02", start)
+	b, err2 := time.Parse("2006-01-02", end)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return b.Sub(a).Hours() / 24 / 365.25
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,150 @@
+package backtest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"investor/internal/dataservice"
+)
+
+// script is a Strategy that plays fixed actions by bar index
+type script map[int]Action
+
+func (s script) Name() string             { return "script" }
+func (s script) Prepare(closes []float64) {}
+func (s script) Signal(i int) Action      { return s[i] }
+
+// daily makes one bar per calendar day from 2026-01-05
+func daily(closes ...float64) []dataservice.KLineItem {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	bars := make([]dataservice.KLineItem, len(closes))
+	for i, c := range closes {
+		bars[i] = dataservice.KLineItem{Date: start.AddDate(0, 0, i).Format("2006-01-02"), Close: c}
+	}
+	return bars
+}
+
+func TestRunExits(t *testing.T) {
+	cases := []struct {
+		name   string
+		closes []float64
+		script script
+		cfg    Config
+		want   Trade // dates are checked through Bars
+		total  float64
+	}{
+		{"signal", []float64{100, 100, 110, 121, 110}, script{1: Buy, 3: Sell}, Config{},
+			Trade{EntryPrice: 100, ExitPrice: 121, Return: 21, Bars: 2, Reason: "signal"}, 21},
+		// -2% holds, -6% breaks the 5% stop
+		{"stop loss", []float64{100, 98, 94, 90}, script{0: Buy}, Config{StopLossPct: 5},
+			Trade{EntryPrice: 100, ExitPrice: 94, Return: -6, Bars: 2, Reason: "stop_loss"}, -6},
+		{"take profit", []float64{100, 105, 111, 120}, script{0: Buy}, Config{TakeProfitPct: 10},
+			Trade{EntryPrice: 100, ExitPrice: 111, Return: 11, Bars: 2, Reason: "take_profit"}, 11},
+		// the stop wins over a Sell on the same bar
+		{"stop before signal", []float64{100, 90}, script{0: Buy, 1: Sell}, Config{StopLossPct: 5},
+			Trade{EntryPrice: 100, ExitPrice: 90, Return: -10, Bars: 1, Reason: "stop_loss"}, -10},
+		{"end", []float64{100, 105, 103}, script{0: Buy}, Config{},
+			Trade{EntryPrice: 100, ExitPrice: 103, Return: 3, Bars: 2, Reason: "end"}, 3},
+		// 10 bps per side: 110*0.999 / (100*1.001) = 1.097802...
+		{"fees", []float64{100, 110}, script{0: Buy, 1: Sell}, Config{FeeBps: 10},
+			Trade{EntryPrice: 100, ExitPrice: 110, Return: 9.78, Bars: 1, Reason: "signal"}, 9.78},
+	}
+	for _, c := range cases {
+		res, err := Run(daily(c.closes...), c.script, c.cfg)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if len(res.Trades) != 1 {
+			t.Errorf("%s: %d trades, want 1", c.name, len(res.Trades))
+			continue
+		}
+		got := res.Trades[0]
+		got.EntryDate, got.ExitDate = "", ""
+		if got != c.want || res.TotalReturn != c.total {
+			t.Errorf("%s: trade %+v total %v, want %+v total %v", c.name, got, res.TotalReturn, c.want, c.total)
+		}
+	}
+}
+
+func TestRunMetrics(t *testing.T) {
+	// In the market from bar 1 to 3: equity 1, 1, 1.1, 1.21, 1.21
+	res, err := Run(daily(100, 100, 110, 121, 110), script{1: Buy, 3: Sell}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TradeCount != 1 || res.WinRate != 100 || res.AvgTradeReturn != 21 {
+		t.Errorf("trades %d win %v avg %v", res.TradeCount, res.WinRate, res.AvgTradeReturn)
+	}
+	if res.BuyHoldReturn != 10 || res.Exposure != 40 || res.MaxDrawdown != 0 {
+		t.Errorf("buy&hold %v exposure %v drawdown %v", res.BuyHoldReturn, res.Exposure, res.MaxDrawdown)
+	}
+	if res.Start != "2026-01-05" || res.End != "2026-01-09" || res.Bars != 5 {
+		t.Errorf("span %s..%s, %d bars", res.Start, res.End, res.Bars)
+	}
+
+	// Equity 1, 1.1, 0.99, 1.089: returns +10%, -10%, +10%, mean 1/30,
+	// sample std sqrt(1/75); Sharpe = mean/std * sqrt(4) = 0.577...
+	res, _ = Run(daily(100, 110, 99, 108.9), script{0: Buy}, Config{PeriodsPerYear: 4})
+	if res.TotalReturn != 8.9 || res.MaxDrawdown != 10 || res.Sharpe != 0.58 {
+		t.Errorf("total %v drawdown %v sharpe %v", res.TotalReturn, res.MaxDrawdown, res.Sharpe)
+	}
+
+	// 2020-01-01 to 2024-01-01 is 1461 days, exactly 4 years of 365.25:
+	// CAGR = 1.21^(1/4) - 1 = sqrt(1.1) - 1 = 4.88%
+	bars := []dataservice.KLineItem{{Date: "2020-01-01", Close: 100}, {Date: "2022-01-01", Close: 100}, {Date: "2024-01-01", Close: 121}}
+	res, _ = Run(bars, script{0: Buy}, Config{})
+	if res.TotalReturn != 21 || res.CAGR != 4.88 {
+		t.Errorf("total %v CAGR %v, want 21 and 4.88", res.TotalReturn, res.CAGR)
+	}
+
+	// No entry on the last bar: the trade could never be closed
+	res, _ = Run(daily(100, 101, 102), script{2: Buy}, Config{})
+	if res.TradeCount != 0 || res.TotalReturn != 0 || res.Exposure != 0 {
+		t.Errorf("entry on the last bar: %+v", res)
+	}
+
+	if _, err := Run(daily(100), script{}, Config{}); err == nil {
+		t.Error("one bar should fail")
+	}
+}
+
+func TestMACrossSignals(t *testing.T) {
+	// i   close  SMA2  SMA3   fast-slow
+	// 2   10     10    10      0
+	// 3   9      9.5   9.67   -0.17  prev 0, falls below: Sell
+	// 5   9      8.5   8.67   -0.17
+	// 6   11     10    9.33   +0.67  crosses above: Buy
+	// 8   10     11    11      0     touching is no cross
+	// 9   8      9     10     -1     prev 0, falls below: Sell
+	closes := []float64{10, 10, 10, 9, 8, 9, 11, 12, 10, 8}
+	s := &MACross{Fast: 2, Slow: 3}
+	s.Prepare(closes)
+	got := map[int]Action{}
+	for i := range closes {
+		if a := s.Signal(i); a != Hold {
+			got[i] = a
+		}
+	}
+	if want := map[int]Action{3: Sell, 6: Buy, 9: Sell}; !reflect.DeepEqual(got, want) {
+		t.Errorf("signals = %v, want %v", got, want)
+	}
+}
+
+// The built-in strategies give the same signal at bar i whether Prepare saw
+// the full series or only the closes up to i, i.e. they never look ahead
+func TestStrategiesDoNotLookAhead(t *testing.T) {
+	closes := []float64{50, 48, 45, 44, 46, 49, 53, 55, 54, 50, 47, 45, 46, 50, 55, 58, 57, 53, 49, 48, 51, 56, 60, 59, 55}
+	for _, name := range Strategies {
+		full, _ := NewStrategy(name, Params{Fast: 3, Slow: 6, Period: 4, Lookback: 5})
+		full.Prepare(closes)
+		for i := range closes {
+			past, _ := NewStrategy(name, Params{Fast: 3, Slow: 6, Period: 4, Lookback: 5})
+			past.Prepare(closes[:i+1])
+			if a, b := full.Signal(i), past.Signal(i); a != b {
+				t.Errorf("%s: bar %d signals %v with the full series, %v without the future", name, i, a, b)
+			}
+		}
+	}
+}
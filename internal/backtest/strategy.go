@@ -0,0 +1,173 @@
+package backtest
+
+import (
+	"fmt"
+	"strings"
+
+	"investor/internal/indicator"
+)
+
+// Action is what a strategy wants to do at a bar
+type Action int
+
+const (
+	Hold Action = iota
+	Buy
+	Sell
+)
+
+// Strategy decides on each bar using only closes up to and including that bar.
+// Prepare is called once with the full series, future bars included, so
+// indicators can be precomputed. Signal(i) must not read past index i: every
+// value it uses must depend only on closes[:i+1] (trailing indicators are fine,
+// centred or forward-looking ones are not), or the backtest trades on prices
+// it could not have known.
+type Strategy interface {
+	Name() string
+	Prepare(closes []float64)
+	Signal(i int) Action
+}
+
+// MACross buys when the fast SMA crosses above the slow SMA and sells on the cross back
+type MACross struct {
+	Fast, Slow int
+	fast, slow []float64
+}
+
+func (s *MACross) Name() string { return fmt.Sprintf("ma_cross(%d,%d)", s.Fast, s.Slow) }
+
+func (s *MACross) Prepare(closes []float64) {
+	s.fast = indicator.SMASeries(closes, s.Fast)
+	s.slow = indicator.SMASeries(closes, s.Slow)
+}
+
+func (s *MACross) Signal(i int) Action {
+	if i < 1 || s.slow[i-1] == 0 {
+		return Hold
+	}
+	prev := s.fast[i-1] - s.slow[i-1]
+	cur := s.fast[i] - s.slow[i]
+	switch {
+	case prev <= 0 && cur > 0:
+		return Buy
+	case prev >= 0 && cur < 0:
+		return Sell
+	}
+	return Hold
+}
+
+// RSIThreshold buys when RSI crosses back above Oversold and sells when it crosses below Overbought
+type RSIThreshold struct {
+	Period               int
+	Oversold, Overbought float64
+	rsi                  []float64
+}
+
+func (s *RSIThreshold) Name() string {
+	return fmt.Sprintf("rsi(%d,%.0f/%.0f)", s.Period, s.Oversold, s.Overbought)
+}
+
+func (s *RSIThreshold) Prepare(closes []float64) {
+	s.rsi = indicator.RSISeries(closes, s.Period)
+}
+
+func (s *RSIThreshold) Signal(i int) Action {
+	if i <= s.Period {
+		return Hold
+	}
+	prev, cur := s.rsi[i-1], s.rsi[i]
+	switch {
+	case prev < s.Oversold && cur >= s.Oversold:
+		return Buy
+	case prev > s.Overbought && cur <= s.Overbought:
+		return Sell
+	}
+	return Hold
+}
+
+// SRBounce buys a bounce off support (the Lookback-bar low) and sells at resistance (the high).
+// Tolerance is how close to the level counts as a touch, as a fraction (0.02 = 2%).
+type SRBounce struct {
+	Lookback  int
+	Tolerance float64
+	closes    []float64
+}
+
+func (s *SRBounce) Name() string {
+	return fmt.Sprintf("sr_bounce(%d,%.1f%%)", s.Lookback, s.Tolerance*100)
+}
+
+func (s *SRBounce) Prepare(closes []float64) {
+	s.closes = closes
+}
+
+func (s *SRBounce) Signal(i int) Action {
+	if i < s.Lookback+1 {
+		return Hold
+	}
+	// Levels come from the bars before the current one
+	support, resistance := indicator.SupportResistance(s.closes[i-s.Lookback:i], s.Lookback)
+	prev, cur := s.closes[i-1], s.closes[i]
+	switch {
+	case prev <= support*(1+s.Tolerance) && cur > prev:
+		return Buy
+	case cur >= resistance*(1-s.Tolerance):
+		return Sell
+	}
+	return Hold
+}
+
+// Strategies lists the built-in strategy names with their default parameters
+var Strategies = []string{"ma_cross", "rsi", "sr_bounce"}
+
+// NewStrategy builds a built-in strategy by name; zero parameters take the defaults
+// (ma_cross 20/60, rsi 14 30/70, sr_bounce 20 bars 2%).
+func NewStrategy(name string, p Params) (Strategy, error) {
+	switch strings.ToLower(name) {
+	case "ma_cross", "ma":
+		s := &MACross{Fast: p.Fast, Slow: p.Slow}
+		if s.Fast <= 0 {
+			s.Fast = 20
+		}
+		if s.Slow <= 0 {
+			s.Slow = 60
+		}
+		if s.Fast >= s.Slow {
+			return nil, fmt.Errorf("fast period must be shorter than slow period")
+		}
+		return s, nil
+	case "rsi":
+		s := &RSIThreshold{Period: p.Period, Oversold: p.Oversold, Overbought: p.Overbought}
+		if s.Period <= 0 {
+			s.Period = 14
+		}
+		if s.Oversold <= 0 {
+			s.Oversold = 30
+		}
+		if s.Overbought <= 0 {
+			s.Overbought = 70
+		}
+		return s, nil
+	case "sr_bounce", "sr":
+		s := &SRBounce{Lookback: p.Lookback, Tolerance: p.Tolerance}
+		if s.Lookback <= 0 {
+			s.Lookback = 20
+		}
+		if s.Tolerance <= 0 {
+			s.Tolerance = 0.02
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q (%s)", name, strings.Join(Strategies, ", "))
+}
+
+// Params tunes the built-in strategies
+type Params struct {
+	Fast       int     `json:"fast,omitempty"`
+	Slow       int     `json:"slow,omitempty"`
+	Period     int     `json:"period,omitempty"`
+	Oversold   float64 `json:"oversold,omitempty"`
+	Overbought float64 `json:"overbought,omitempty"`
+	Lookback   int     `json:"lookback,omitempty"`
+	Tolerance  float64 `json:"tolerance,omitempty"`
+}
@@ -0,0 +1,100 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"investor/internal/dataservice"
+	"investor/internal/tools"
+)
+
+// maxToolTrades keeps the tool result small enough for the LLM context
+const maxToolTrades = 10
+
+// RegisterTools exposes backtesting to the LLM
+func RegisterTools(r *tools.Registry, data dataservice.DataService) {
+	r.Register(tools.Tool{
+		Name:        "run_backtest",
+		Description: "用历史K线回测规则策略 (均线交叉 / RSI 阈值 / 支撑压力反弹)，返回胜率、年化收益(CAGR)、最大回撤、夏普比率及与买入持有的对比",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "标的代码，如 'AAPL', 'BTC', '600519.SS'",
+				},
+				"strategy": map[string]interface{}{
+					"type":        "string",
+					"description": "ma_cross: 快线上穿慢线买入、下穿卖出; rsi: RSI 从超卖区回升买入、从超买区回落卖出; sr_bounce: 触及支撑反弹买入、接近压力卖出",
+					"enum":        Strategies,
+				},
+				"range": map[string]interface{}{
+					"type":        "string",
+					"description": "回测区间，默认 2y",
+					"enum":        []string{"1y", "2y", "5y", "10y", "max"},
+				},
+				"interval": map[string]interface{}{
+					"type":        "string",
+					"description": "K线周期，默认 1d",
+					"enum":        []string{"1d", "1wk"},
+				},
+				"stop_loss_pct":   map[string]interface{}{"type": "number", "description": "止损百分比 (可选)，如 5 表示 -5% 止损"},
+				"take_profit_pct": map[string]interface{}{"type": "number", "description": "止盈百分比 (可选)"},
+				"fast":            map[string]interface{}{"type": "integer", "description": "ma_cross 快线周期，默认 20"},
+				"slow":            map[string]interface{}{"type": "integer", "description": "ma_cross 慢线周期，默认 60"},
+				"oversold":        map[string]interface{}{"type": "number", "description": "rsi 超卖阈值，默认 30"},
+				"overbought":      map[string]interface{}{"type": "number", "description": "rsi 超买阈值，默认 70"},
+			},
+			"required": []string{"symbol", "strategy"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbol   string `json:"symbol"`
+				Strategy string `json:"strategy"`
+				Range    string `json:"range"`
+				Interval string `json:"interval"`
+				Params
+				Config
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.Range == "" {
+				args.Range = "2y"
+			}
+			if args.Interval == "" {
+				args.Interval = "1d"
+			}
+			s, err := NewStrategy(args.Strategy, args.Params)
+			if err != nil {
+				return nil, err
+			}
+			args.Config.PeriodsPerYear = PeriodsPerYear(args.Symbol, args.Interval)
+
+			res, err := RunSymbol(ctx, data, args.Symbol, args.Interval, args.Range, s, args.Config)
+			if err != nil {
+				return nil, err
+			}
+			if len(res.Trades) > maxToolTrades {
+				res.Trades = res.Trades[len(res.Trades)-maxToolTrades:]
+			}
+			return res, nil
+		},
+	})
+}
+
+// PeriodsPerYear picks the annualization factor for an interval (crypto trades every day)
+func PeriodsPerYear(symbol, interval string) float64 {
+	if interval == "1wk" {
+		return 52
+	}
+	if interval == "1mo" {
+		return 12
+	}
+	s := strings.ToUpper(symbol)
+	if strings.HasSuffix(s, "USDT") || strings.HasSuffix(s, "-USD") || s == "BTC" || s == "ETH" || s == "SOL" || s == "BNB" {
+		return 365
+	}
+	return 252
+}
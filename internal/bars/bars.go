@@ -0,0 +1,230 @@
+// Package bars persists downloaded price history on disk, one JSON file per
+// symbol and interval, so indicators and backtests read local bars and only
+// the missing tail is downloaded again.
+package bars
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+)
+
+// DefaultTTL is how long a series is served without checking for new bars
+const DefaultTTL = 15 * time.Minute
+
+// Intervals are the bar sizes kept on disk. Intraday bars are not stored:
+// KLineItem dates carry no time of day.
+var Intervals = map[string]bool{"1d": true, "5d": true, "1wk": true, "1mo": true, "3mo": true}
+
+// Series is one stored file
+type Series struct {
+	Symbol    string                  `json:"symbol"`
+	Interval  string                  `json:"interval"`
+	From      string                  `json:"from"` // history is known to be complete from this date on
+	UpdatedAt time.Time               `json:"updated_at"`
+	Bars      []dataservice.KLineItem `json:"bars"` // ascending by date
+}
+
+// Store is a directory of series. It implements dataservice.BarCache.
+type Store struct {
+	Dir string
+	TTL time.Duration
+
+	locks sync.Map // series key -> *sync.Mutex, so one download per series at a time
+	now   func() time.Time
+}
+
+// New opens (and creates) a bar directory
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("bar store: %w", err)
+	}
+	return &Store{Dir: dir, TTL: DefaultTTL, now: time.Now}, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.^=_-]`)
+
+func (s *Store) path(symbol, interval string) string {
+	return filepath.Join(s.Dir, interval, unsafeChars.ReplaceAllString(symbol, "_")+".json")
+}
+
+func (s *Store) lock(symbol, interval string) func() {
+	v, _ := s.locks.LoadOrStore(interval+"/"+symbol, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Load reads a series; a missing file is an empty series
+func (s *Store) Load(symbol, interval string) (*Series, error) {
+	data, err := os.ReadFile(s.path(symbol, interval))
+	if os.IsNotExist(err) {
+		return &Series{Symbol: symbol, Interval: interval}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var series Series
+	if err := json.Unmarshal(data, &series); err != nil {
+		return nil, fmt.Errorf("bar store: %s: %w", s.path(symbol, interval), err)
+	}
+	return &series, nil
+}
+
+// save writes atomically (temp file + rename) like the state store
+func (s *Store) save(series *Series) error {
+	path := s.path(series.Symbol, series.Interval)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(series)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// merge adds bars to the series; a bar for an existing date replaces it, since
+// the last stored bar may have been taken mid-session.
+func merge(existing, fresh []dataservice.KLineItem) []dataservice.KLineItem {
+	byDate := make(map[string]dataservice.KLineItem, len(existing)+len(fresh))
+	for _, b := range existing {
+		byDate[b.Date] = b
+	}
+	for _, b := range fresh {
+		byDate[b.Date] = b
+	}
+	out := make([]dataservice.KLineItem, 0, len(byDate))
+	for _, b := range byDate {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out
+}
+
+// adjustTolerance is the relative change in a stored close that counts as a
+// restatement rather than rounding
+const adjustTolerance = 1e-4
+
+// restated reports whether fresh bars disagree with stored ones for the same
+// date: after a split or dividend the provider adjusts the whole history, so
+// an old close that moved means every stored bar is stale. The last stored
+// bar is not compared, it may have been taken mid-session.
+func restated(existing, fresh []dataservice.KLineItem) bool {
+	if len(existing) < 2 {
+		return false
+	}
+	closes := make(map[string]float64, len(existing)-1)
+	for _, b := range existing[:len(existing)-1] {
+		closes[b.Date] = b.Close
+	}
+	for _, b := range fresh {
+		if c, ok := closes[b.Date]; ok && math.Abs(b.Close-c) > adjustTolerance*math.Abs(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// gapRanges are the ranges tried, smallest first, to download a missing tail
+var gapRanges = []string{"5d", "1mo", "3mo", "6mo", "1y", "2y", "5y", "10y", "max"}
+
+// gapRange is the smallest range reaching back to the last stored bar
+func gapRange(last string, now time.Time) string {
+	for _, r := range gapRanges {
+		if start, _ := dataservice.RangeStart(r, now); start <= last {
+			return r
+		}
+	}
+	return "max"
+}
+
+// History implements dataservice.BarCache: bars come from disk, a missing head
+// is downloaded once with the full range, a missing tail with the smallest
+// range that reaches the last stored bar. When the tail restates stored bars
+// (a split or dividend adjustment) the whole stored range is downloaded again
+// and replaces them. If a download fails the stored bars are served, so
+// history keeps working offline.
+func (s *Store) History(ctx context.Context, symbol, interval, rangeStr string, fetch dataservice.BarFetcher) ([]dataservice.KLineItem, error) {
+	now := s.now()
+	start, ok := dataservice.RangeStart(rangeStr, now)
+	if !Intervals[interval] || !ok {
+		return fetch(ctx, symbol, interval, rangeStr)
+	}
+
+	unlock := s.lock(symbol, interval)
+	defer unlock()
+
+	series, err := s.Load(symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var download string
+	switch {
+	case len(series.Bars) == 0 || series.From > start:
+		download = rangeStr
+	case now.Sub(series.UpdatedAt) > s.TTL:
+		download = gapRange(series.Bars[len(series.Bars)-1].Date, now)
+	}
+
+	if download != "" {
+		fresh, err := fetch(ctx, symbol, interval, download)
+		if err != nil {
+			if len(series.Bars) == 0 {
+				return nil, err
+			}
+		} else if download != rangeStr && restated(series.Bars, fresh) {
+			if err := s.refetch(ctx, series, fetch, now); err != nil {
+				return nil, err
+			}
+		} else {
+			series.Bars = merge(series.Bars, fresh)
+			if download == rangeStr && (series.From == "" || start < series.From) {
+				series.From = start
+			}
+			series.UpdatedAt = now
+			if err := s.save(series); err != nil {
+				return nil, fmt.Errorf("bar store: %w", err)
+			}
+		}
+	}
+
+	i := sort.Search(len(series.Bars), func(i int) bool { return series.Bars[i].Date >= start })
+	return series.Bars[i:], nil
+}
+
+// refetch replaces a restated series with a fresh download of everything it
+// covered. If that download fails the series is left as it was (and not
+// saved, so the next call tries again).
+func (s *Store) refetch(ctx context.Context, series *Series, fetch dataservice.BarFetcher, now time.Time) error {
+	from := series.From
+	if from == "" || series.Bars[0].Date < from {
+		from = series.Bars[0].Date
+	}
+	full := gapRange(from, now)
+	bars, err := fetch(ctx, series.Symbol, series.Interval, full)
+	if err != nil || len(bars) == 0 {
+		return nil
+	}
+	series.Bars = merge(nil, bars)
+	series.From, _ = dataservice.RangeStart(full, now)
+	series.UpdatedAt = now
+	if err := s.save(series); err != nil {
+		return fmt.Errorf("bar store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package bars
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"investor/internal/dataservice"
+)
+
+func bar(date string, close float64) dataservice.KLineItem {
+	return dataservice.KLineItem{Date: date, Close: close, Volume: 100}
+}
+
+func TestMerge(t *testing.T) {
+	existing := []dataservice.KLineItem{bar("2026-10-13", 10), bar("2026-10-14", 11), bar("2026-10-15", 12)}
+	fresh := []dataservice.KLineItem{bar("2026-10-16", 14), bar("2026-10-15", 12.5)}
+	want := []dataservice.KLineItem{bar("2026-10-13", 10), bar("2026-10-14", 11), bar("2026-10-15", 12.5), bar("2026-10-16", 14)}
+	if got := merge(existing, fresh); !reflect.DeepEqual(got, want) {
+		t.Errorf("merge = %v, want %v", got, want)
+	}
+}
+
+func TestGapRange(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cases := []struct{ last, want string }{
+		{"2026-10-15", "5d"},
+		{"2026-10-09", "5d"},
+		{"2026-10-01", "1mo"},
+		{"2026-06-01", "6mo"},
+		{"2020-01-01", "10y"},
+		{"1990-01-01", "max"},
+	}
+	for _, c := range cases {
+		if got := gapRange(c.last, now); got != c.want {
+			t.Errorf("gapRange(%s) = %s, want %s", c.last, got, c.want)
+		}
+	}
+}
+
+func TestRestated(t *testing.T) {
+	stored := []dataservice.KLineItem{bar("2026-10-14", 100), bar("2026-10-15", 102), bar("2026-10-16", 103)}
+	cases := []struct {
+		name  string
+		fresh []dataservice.KLineItem
+		want  bool
+	}{
+		{"same closes", []dataservice.KLineItem{bar("2026-10-15", 102), bar("2026-10-16", 104), bar("2026-10-19", 105)}, false},
+		{"last bar finished", []dataservice.KLineItem{bar("2026-10-16", 110)}, false},
+		{"split", []dataservice.KLineItem{bar("2026-10-15", 51), bar("2026-10-16", 51.5)}, true},
+		{"dividend", []dataservice.KLineItem{bar("2026-10-14", 99.2)}, true},
+		{"no overlap", []dataservice.KLineItem{bar("2026-10-19", 50)}, false},
+	}
+	for _, c := range cases {
+		if got := restated(stored, c.fresh); got != c.want {
+			t.Errorf("%s: restated = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+// fakeFetch serves bars from a function of the requested range and records calls
+type fakeFetch struct {
+	ranges []string
+	bars   func(rangeStr string) ([]dataservice.KLineItem, error)
+}
+
+func (f *fakeFetch) fetch(ctx context.Context, symbol, interval, rangeStr string) ([]dataservice.KLineItem, error) {
+	f.ranges = append(f.ranges, rangeStr)
+	return f.bars(rangeStr)
+}
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	month := []dataservice.KLineItem{bar("2026-09-01", 9), bar("2026-10-14", 10), bar("2026-10-15", 11), bar("2026-10-16", 12)}
+	f := &fakeFetch{bars: func(string) ([]dataservice.KLineItem, error) { return month, nil }}
+
+	// First call downloads the requested range; 5d is then served from disk
+	if _, err := s.History(ctx, "AAPL", "1d", "1mo", f.fetch); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.History(ctx, "AAPL", "1d", "5d", f.fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Date != "2026-10-14" {
+		t.Errorf("5d from disk = %v", got)
+	}
+	if !reflect.DeepEqual(f.ranges, []string{"1mo"}) {
+		t.Errorf("downloads = %v, want [1mo]", f.ranges)
+	}
+
+	// A longer range than stored downloads the head again
+	f.ranges = nil
+	s.History(ctx, "AAPL", "1d", "1y", f.fetch)
+	if !reflect.DeepEqual(f.ranges, []string{"1y"}) {
+		t.Errorf("downloads = %v, want [1y]", f.ranges)
+	}
+
+	// After the TTL only the tail is downloaded and merged
+	now = now.Add(3 * 24 * time.Hour)
+	f.ranges = nil
+	f.bars = func(string) ([]dataservice.KLineItem, error) {
+		return []dataservice.KLineItem{bar("2026-10-15", 11), bar("2026-10-16", 12.5), bar("2026-10-19", 13)}, nil
+	}
+	got, _ = s.History(ctx, "AAPL", "1d", "5d", f.fetch)
+	if !reflect.DeepEqual(f.ranges, []string{"5d"}) {
+		t.Errorf("downloads = %v, want [5d]", f.ranges)
+	}
+	if last := got[len(got)-1]; last.Date != "2026-10-19" || got[len(got)-2].Close != 12.5 {
+		t.Errorf("tail not merged: %v", got)
+	}
+
+	// Offline: stored bars are served
+	now = now.Add(time.Hour)
+	f.bars = func(string) ([]dataservice.KLineItem, error) { return nil, errors.New("offline") }
+	if got, err := s.History(ctx, "AAPL", "1d", "5d", f.fetch); err != nil || len(got) == 0 {
+		t.Errorf("offline: %v, %v", got, err)
+	}
+
+	// A 2:1 split restates the stored closes: the whole stored range (from
+	// 2025-10-16, so 2y) is downloaded again
+	now = now.Add(time.Hour)
+	f.ranges = nil
+	f.bars = func(r string) ([]dataservice.KLineItem, error) {
+		if r == "5d" {
+			return []dataservice.KLineItem{bar("2026-10-15", 5.5), bar("2026-10-16", 6.25), bar("2026-10-19", 6.5)}, nil
+		}
+		return []dataservice.KLineItem{bar("2026-09-01", 4.5), bar("2026-10-14", 5), bar("2026-10-15", 5.5), bar("2026-10-16", 6.25), bar("2026-10-19", 6.5)}, nil
+	}
+	got, _ = s.History(ctx, "AAPL", "1d", "1y", f.fetch)
+	if !reflect.DeepEqual(f.ranges, []string{"5d", "2y"}) {
+		t.Errorf("downloads = %v, want [5d 2y]", f.ranges)
+	}
+	if len(got) != 5 || got[0].Close != 4.5 {
+		t.Errorf("restated history = %v", got)
+	}
+	series, _ := s.Load("AAPL", "1d")
+	if series.From != "2024-10-19" || len(series.Bars) != 5 {
+		t.Errorf("saved series from %s with %d bars", series.From, len(series.Bars))
+	}
+
+	// Intraday intervals are not stored
+	f.ranges = nil
+	s.History(ctx, "AAPL", "5m", "1d", f.fetch)
+	s.History(ctx, "AAPL", "5m", "1d", f.fetch)
+	if len(f.ranges) != 2 {
+		t.Errorf("intraday downloads = %v, want 2", f.ranges)
+	}
+}
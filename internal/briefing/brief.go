@@ -0,0 +1,193 @@
+package briefing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/llm"
+	"investor/internal/render"
+	"investor/internal/watchlist"
+
+	"go.uber.org/zap"
+)
+
+const (
+	newsPerCategory = 3
+	topMovers       = 8
+)
+
+// Snapshot is the raw market data a brief is written from
+type Snapshot struct {
+	Kind      Kind                              `json:"kind"`
+	Market    string                            `json:"market"`
+	Time      string                            `json:"time"` // local time of the market
+	Indices   []dataservice.IndexQuote          `json:"indices,omitempty"`
+	News      map[string][]dataservice.NewsItem `json:"news,omitempty"`
+	Sentiment *dataservice.SentimentData        `json:"sentiment,omitempty"`
+	Movers    []watchlist.Row                   `json:"watchlist_movers,omitempty"`
+	Errors    []string                          `json:"errors,omitempty"`
+}
+
+// Collect gathers indices, news, sentiment and the chat's watchlist moves.
+// Individual source failures are recorded rather than aborting the brief.
+func (m *Manager) Collect(ctx context.Context, platform, chatID string, kind Kind, market string) (*Snapshot, error) {
+	mk, ok := Markets[market]
+	if !ok {
+		return nil, fmt.Errorf("unknown market %q", market)
+	}
+	loc, err := time.LoadLocation(mk.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	snap := &Snapshot{
+		Kind:   kind,
+		Market: market,
+		Time:   time.Now().In(loc).Format("2006-01-02 15:04 MST"),
+		News:   make(map[string][]dataservice.NewsItem),
+	}
+
+	if indices, err := m.Data.GetMarketIndex(ctx); err != nil {
+		snap.Errors = append(snap.Errors, "indices: "+err.Error())
+	} else {
+		snap.Indices = indices
+	}
+
+	seen := make(map[string]bool) // feeds overlap, keep each headline once
+	for _, cat := range mk.News {
+		news, err := m.Data.SearchMarketNews(ctx, cat)
+		if err != nil {
+			snap.Errors = append(snap.Errors, "news "+cat+": "+err.Error())
+			continue
+		}
+		for _, n := range news {
+			if len(snap.News[cat]) >= newsPerCategory {
+				break
+			}
+			if !seen[n.Title] {
+				seen[n.Title] = true
+				snap.News[cat] = append(snap.News[cat], n)
+			}
+		}
+	}
+
+	// Only a reading of the market itself: the US gauge says nothing about A-shares
+	if mk.Sentiment != "" {
+		if sentiment, err := m.Data.GetMarketSentiment(ctx, mk.Sentiment); err != nil {
+			snap.Errors = append(snap.Errors, "sentiment: "+err.Error())
+		} else {
+			snap.Sentiment = sentiment
+		}
+	}
+
+	if m.Watchlists != nil {
+		owner := watchlist.Owner{Scope: watchlist.ScopeGroup, Platform: platform, ID: chatID}
+		if wl, err := m.Watchlists.Get(ctx, owner); err == nil && len(wl.Symbols) > 0 {
+			snap.Movers = movers(m.Watchlists.Quotes(ctx, wl.Symbols))
+		}
+	}
+	return snap, nil
+}
+
+// movers keeps the watchlist rows with the largest absolute moves
+func movers(rows []watchlist.Row) []watchlist.Row {
+	var ok []watchlist.Row
+	for _, r := range rows {
+		if r.Error == "" {
+			ok = append(ok, r)
+		}
+	}
+	sort.SliceStable(ok, func(i, j int) bool {
+		return math.Abs(ok[i].ChangePct) > math.Abs(ok[j].ChangePct)
+	})
+	if len(ok) > topMovers {
+		ok = ok[:topMovers]
+	}
+	return ok
+}
+
+// Build collects the data and has the LLM write the brief.
+// If the LLM is unavailable the raw data card is returned instead.
+func (m *Manager) Build(ctx context.Context, platform, chatID string, kind Kind, market string) (*render.Document, error) {
+	snap, err := m.Collect(ctx, platform, chatID, kind, market)
+	if err != nil {
+		return nil, err
+	}
+	if m.LLM == nil {
+		return snap.Document(), nil
+	}
+
+	text, err := m.summarize(ctx, snap)
+	if err != nil {
+		m.Logger.Warn("Briefing summary failed, sending raw data", zap.Error(err))
+		return snap.Document(), nil
+	}
+	return render.FromMarkdown(text), nil
+}
+
+func (m *Manager) summarize(ctx context.Context, snap *Snapshot) (string, error) {
+	data, _ := json.Marshal(snap)
+	title := fmt.Sprintf("%s%s", Markets[snap.Market].Label, kindLabels[snap.Kind])
+
+	prompt := fmt.Sprintf(`# Role
+You are Investor AI writing the "%s" pushed to an investment group chat at %s.
+
+# Output (Chinese Markdown, under 400 characters of prose)
+1. Title line: "## ☀️ %s"
+2. **🌍 市场概览**: key index moves in one or two lines, bold the numbers.
+3. **📰 要闻**: the 3-5 headlines that matter most for this market, one line each with why it matters.
+4. **🌡️ 情绪**: sentiment score and label, one line (omit the section if there is no sentiment data).
+5. **⭐ 自选异动**: the biggest movers from the group watchlist (omit the section if there is no watchlist data).
+6. **🎯 关注**: what to watch next session, 1-2 bullets.
+
+# Rules
+- Use ONLY the JSON data provided. If a section's data is missing, write "数据暂缺".
+- No trade recommendations. End with "_仅供参考，不构成投资建议_".`, title, snap.Time, title)
+
+	return m.LLM.Chat(ctx, []llm.Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: string(data)},
+	})
+}
+
+// Document renders the snapshot without the LLM
+func (s *Snapshot) Document() *render.Document {
+	doc := render.NewDocument().
+		Heading("☀️", fmt.Sprintf("%s%s", Markets[s.Market].Label, kindLabels[s.Kind])).
+		Paragraph(s.Time).
+		Divider()
+
+	if len(s.Indices) > 0 {
+		var rows [][]string
+		for _, idx := range s.Indices {
+			rows = append(rows, []string{idx.Name, fmt.Sprintf("%.2f", idx.Value), fmt.Sprintf("%+.2f%%", idx.ChangePct)})
+		}
+		doc.Heading("🌍", "市场概览").Table([]string{"指数", "点位", "涨跌"}, rows)
+	}
+
+	if len(s.News) > 0 {
+		doc.Heading("📰", "要闻")
+		for _, cat := range Markets[s.Market].News {
+			for _, n := range s.News[cat] {
+				doc.Item(render.Item{Title: n.Title, Meta: fmt.Sprintf("%s | %s", n.Source, n.Time)})
+			}
+		}
+	}
+
+	if s.Sentiment != nil {
+		doc.Fields("",
+			render.Field{Icon: "🌡️", Key: "情绪", Value: fmt.Sprintf("%.0f (%s)", s.Sentiment.Score, s.Sentiment.Label)},
+		)
+	}
+
+	if len(s.Movers) > 0 {
+		doc.Add(watchlist.RowsDocument("自选异动", s.Movers).Blocks...)
+	}
+
+	return doc.Divider().Note("注: 以上数据仅供参考，不构成投资建议")
+}
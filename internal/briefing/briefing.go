@@ -0,0 +1,353 @@
+package briefing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/llm"
+	"investor/internal/notify"
+	"investor/internal/schedule"
+	"investor/internal/store"
+	"investor/internal/tradinghours"
+	"investor/internal/watchlist"
+
+	"go.uber.org/zap"
+)
+
+// Kind is the type of brief
+type Kind string
+
+const (
+	PreMarket Kind = "premarket" // before the open
+	Close     Kind = "close"     // after the close
+	Weekly    Kind = "weekly"    // end-of-week recap
+)
+
+var kindLabels = map[Kind]string{
+	PreMarket: "盘前早报",
+	Close:     "收盘复盘",
+	Weekly:    "周度回顾",
+}
+
+// Market describes a market's clock and which data feeds a brief about it
+type Market struct {
+	Code      string
+	Label     string
+	Timezone  string
+	Exchange  string          // tradinghours calendar: no pre-market or close brief on its holidays
+	News      []string        // SearchMarketNews categories
+	Sentiment string          // GetMarketSentiment market, empty = no reading of this market's own
+	Crons     map[Kind]string // default schedule per kind, in Timezone
+}
+
+// Markets are the supported markets. Crypto trades 24/7, so its "open" is the UTC day boundary.
+var Markets = map[string]Market{
+	"cn": {
+		Code: "cn", Label: "A股", Timezone: "Asia/Shanghai", Exchange: "SSE",
+		News:  []string{"cn", "macro"},
+		Crons: map[Kind]string{PreMarket: "45 8 * * 1-5", Close: "15 15 * * 1-5", Weekly: "0 18 * * 5"},
+	},
+	"hk": {
+		Code: "hk", Label: "港股", Timezone: "Asia/Hong_Kong", Exchange: "HKEX",
+		News:  []string{"cn", "macro"},
+		Crons: map[Kind]string{PreMarket: "0 9 * * 1-5", Close: "30 16 * * 1-5", Weekly: "0 18 * * 5"},
+	},
+	"us": {
+		Code: "us", Label: "美股", Timezone: "America/New_York", Exchange: "NYSE",
+		News: []string{"us", "macro"}, Sentiment: "us_stock",
+		Crons: map[Kind]string{PreMarket: "0 9 * * 1-5", Close: "15 16 * * 1-5", Weekly: "30 16 * * 5"},
+	},
+	"crypto": {
+		Code: "crypto", Label: "加密货币", Timezone: "UTC", Exchange: "CRYPTO",
+		News: []string{"crypto", "macro"}, Sentiment: "crypto",
+		Crons: map[Kind]string{PreMarket: "0 0 * * *", Close: "0 12 * * *", Weekly: "0 12 * * 0"},
+	},
+}
+
+// Subscription is a brief pushed to one chat on a schedule
+type Subscription struct {
+	ID        string    `json:"id"`
+	Platform  string    `json:"platform"`
+	ChatID    string    `json:"chat_id"`
+	CreatedBy string    `json:"created_by"`
+	Kind      Kind      `json:"kind"`
+	Market    string    `json:"market"`
+	Cron      string    `json:"cron"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+	NextRun   time.Time `json:"next_run"`
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Describe renders the subscription in words
+func (s *Subscription) Describe() string {
+	return fmt.Sprintf("%s%s (%s, %s)", Markets[s.Market].Label, kindLabels[s.Kind], s.Cron, s.Timezone)
+}
+
+// schedule parses the subscription's cron and timezone
+func (s *Subscription) schedule() (*schedule.Cron, *time.Location, error) {
+	cron, err := schedule.Parse(s.Cron)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+	return cron, loc, nil
+}
+
+// tradingDay reports whether a brief due at t has a session to talk about:
+// weekly recaps always run, pre-market and close briefs skip market holidays
+func (s *Subscription) tradingDay(t time.Time) bool {
+	if s.Kind == Weekly {
+		return true
+	}
+	ex, ok := tradinghours.Get(Markets[s.Market].Exchange)
+	return !ok || ex.IsTradingDay(t)
+}
+
+// calendarCovers reports whether the market's holiday table includes t, i.e.
+// whether tradingDay knows about holidays on that date at all
+func (s *Subscription) calendarCovers(t time.Time) bool {
+	ex, ok := tradinghours.Get(Markets[s.Market].Exchange)
+	return !ok || ex.Covers(t)
+}
+
+const (
+	keyPrefix = "briefing:"
+	// A brief more than this late (e.g. the server was down) is skipped rather than sent stale
+	maxLateness = 30 * time.Minute
+	// Briefs built at once in a tick; each can take minutes (data, then the LLM)
+	pushConcurrency = 4
+)
+
+// Manager stores subscriptions, builds briefs and pushes them when due
+type Manager struct {
+	Store      store.Store
+	Data       dataservice.DataService
+	LLM        llm.Provider
+	Watchlists *watchlist.Manager
+	Notifier   *notify.Hub
+	Logger     *zap.Logger
+
+	mu sync.Mutex // serializes ticks with edits
+}
+
+func NewManager(st store.Store, data dataservice.DataService, p llm.Provider, watchlists *watchlist.Manager, notifier *notify.Hub, logger *zap.Logger) *Manager {
+	return &Manager{
+		Store:      st,
+		Data:       data,
+		LLM:        p,
+		Watchlists: watchlists,
+		Notifier:   notifier,
+		Logger:     logger,
+	}
+}
+
+// Subscribe validates a subscription, fills defaults from the market and stores it
+func (m *Manager) Subscribe(ctx context.Context, s *Subscription) (*Subscription, error) {
+	market, ok := Markets[s.Market]
+	if !ok {
+		return nil, fmt.Errorf("unknown market %q (cn/hk/us/crypto)", s.Market)
+	}
+	if _, ok := kindLabels[s.Kind]; !ok {
+		return nil, fmt.Errorf("unknown brief %q (premarket/close/weekly)", s.Kind)
+	}
+	if m.Notifier == nil || !m.Notifier.CanSend(s.Platform) {
+		return nil, fmt.Errorf("当前渠道不支持主动推送")
+	}
+	if s.Cron == "" {
+		s.Cron = market.Crons[s.Kind]
+	}
+	if s.Timezone == "" {
+		s.Timezone = market.Timezone
+	}
+	cron, loc, err := s.schedule()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ID = newID()
+	s.CreatedAt = time.Now()
+	s.NextRun = cron.Next(s.CreatedAt, loc)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.Store.Put(ctx, keyPrefix+s.ID, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// List returns the subscriptions of a chat, oldest first
+func (m *Manager) List(ctx context.Context, platform, chatID string) ([]*Subscription, error) {
+	all, err := m.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var subs []*Subscription
+	for _, s := range all {
+		if s.Platform == platform && s.ChatID == chatID {
+			subs = append(subs, s)
+		}
+	}
+	return subs, nil
+}
+
+// Unsubscribe removes a subscription if it belongs to the chat
+func (m *Manager) Unsubscribe(ctx context.Context, platform, chatID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var s Subscription
+	ok, err := m.Store.Get(ctx, keyPrefix+id, &s)
+	if err != nil {
+		return err
+	}
+	if !ok || s.Platform != platform || s.ChatID != chatID {
+		return fmt.Errorf("subscription not found: %s", id)
+	}
+	return m.Store.Delete(ctx, keyPrefix+id)
+}
+
+func (m *Manager) all(ctx context.Context) ([]*Subscription, error) {
+	keys, err := m.Store.Keys(ctx, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var subs []*Subscription
+	for _, k := range keys {
+		var s Subscription
+		if ok, err := m.Store.Get(ctx, k, &s); err == nil && ok {
+			subs = append(subs, &s)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs, nil
+}
+
+// Run checks for due briefs at the top of every minute until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	m.Logger.Info("Briefing scheduler started")
+	for {
+		now := time.Now()
+		wait := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+			if err := m.Tick(ctx, time.Now()); err != nil {
+				m.Logger.Error("Briefing tick failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick sends every brief due at or before now and schedules its next run.
+// Briefs are built and pushed concurrently, pushConcurrency at a time; a brief
+// waiting for its turn is judged stale against now, the start of the tick.
+func (m *Manager) Tick(ctx context.Context, now time.Time) error {
+	m.mu.Lock()
+	subs, err := m.all(ctx)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, pushConcurrency)
+	var wg sync.WaitGroup
+	for _, s := range subs {
+		if s.NextRun.After(now) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			m.run(ctx, s, now)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// run sends one due brief, unless it is stale or the market is closed, and
+// saves its next run
+func (m *Manager) run(ctx context.Context, s *Subscription, now time.Time) {
+	cron, loc, err := s.schedule()
+	if err != nil {
+		m.Logger.Warn("Invalid briefing schedule", zap.String("id", s.ID), zap.Error(err))
+		return
+	}
+
+	switch {
+	case now.Sub(s.NextRun) > maxLateness:
+		m.Logger.Info("Skipping stale briefing", zap.String("id", s.ID), zap.Time("due", s.NextRun))
+	case !s.tradingDay(s.NextRun):
+		m.Logger.Info("Skipping briefing on a market holiday", zap.String("id", s.ID), zap.Time("due", s.NextRun))
+	default:
+		if !s.calendarCovers(s.NextRun) {
+			m.Logger.Warn("Trading holidays unknown for this date, the brief may go out on a holiday",
+				zap.String("id", s.ID), zap.String("market", s.Market), zap.Time("due", s.NextRun))
+		}
+		s.LastRun = now
+		s.LastError = ""
+		if err := m.push(ctx, s); err != nil {
+			s.LastError = err.Error()
+			m.Logger.Warn("Failed to push briefing", zap.String("id", s.ID), zap.Error(err))
+		}
+	}
+	s.NextRun = cron.Next(now, loc)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Skip the save if the subscription was removed while the brief was being built
+	var current Subscription
+	if ok, _ := m.Store.Get(ctx, keyPrefix+s.ID, &current); ok {
+		if err := m.Store.Put(ctx, keyPrefix+s.ID, s); err != nil {
+			m.Logger.Error("Failed to save briefing", zap.String("id", s.ID), zap.Error(err))
+		}
+	}
+}
+
+func (m *Manager) push(ctx context.Context, s *Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+
+	doc, err := m.Build(ctx, s.Platform, s.ChatID, s.Kind, s.Market)
+	if err != nil {
+		return err
+	}
+	return m.Notifier.Send(ctx, s.Platform, s.ChatID, doc)
+}
+
+// ParseKind accepts the English kind or its Chinese label
+func ParseKind(s string) (Kind, bool) {
+	s = strings.ToLower(s)
+	switch s {
+	case "早报", "盘前", "pre", "morning":
+		return PreMarket, true
+	case "收盘", "复盘", "晚报", "evening":
+		return Close, true
+	case "周报", "week":
+		return Weekly, true
+	}
+	_, ok := kindLabels[Kind(s)]
+	return Kind(s), ok
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,108 @@
+package briefing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"investor/internal/command"
+	"investor/internal/model"
+	"investor/internal/render"
+)
+
+// RegisterCommands adds the /brief slash command:
+//
+//	/brief                                  list this chat's subscriptions
+//	/brief add premarket cn                 subscribe with the market's default schedule
+//	/brief add close us 30 16 * * 1-5       custom cron (in the market's timezone)
+//	/brief add weekly crypto tz=Asia/Shanghai 0 20 * * 0
+//	/brief del <ID>                         unsubscribe
+//	/brief now [premarket|close|weekly] [market]  build a brief right away
+func RegisterCommands(r *command.Router, m *Manager) {
+	r.Register(command.Command{
+		Name:    "brief",
+		Usage:   "/brief [add <premarket|close|weekly> <cn|hk|us|crypto> [tz=时区] [cron]|del <ID>|now]",
+		Summary: "订阅定时市场简报 (盘前/收盘/周报)，推送到当前会话",
+		Handler: func(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error) {
+			if len(args) == 0 || args[0] == "list" {
+				return m.listDocument(ctx, msg)
+			}
+
+			switch args[0] {
+			case "add", "sub":
+				if len(args) < 3 {
+					return nil, fmt.Errorf("用法: /brief add premarket cn [cron]")
+				}
+				kind, ok := ParseKind(args[1])
+				if !ok {
+					return nil, fmt.Errorf("unknown brief %q (premarket/close/weekly)", args[1])
+				}
+				sub := &Subscription{
+					Platform:  msg.Platform,
+					ChatID:    msg.ChatID,
+					CreatedBy: msg.UserID,
+					Kind:      kind,
+					Market:    strings.ToLower(args[2]),
+				}
+				rest := args[3:]
+				if len(rest) > 0 && strings.HasPrefix(rest[0], "tz=") {
+					sub.Timezone = strings.TrimPrefix(rest[0], "tz=")
+					rest = rest[1:]
+				}
+				sub.Cron = strings.Join(rest, " ")
+
+				sub, err := m.Subscribe(ctx, sub)
+				if err != nil {
+					return nil, err
+				}
+				return render.NewDocument().Paragraph(fmt.Sprintf("✅ 已订阅 [%s] %s，下次推送: %s",
+					sub.ID, sub.Describe(), sub.NextRun.Format("2006-01-02 15:04 MST"))), nil
+
+			case "del", "rm", "unsub":
+				if len(args) != 2 {
+					return nil, fmt.Errorf("用法: /brief del <ID>")
+				}
+				if err := m.Unsubscribe(ctx, msg.Platform, msg.ChatID, args[1]); err != nil {
+					return nil, err
+				}
+				return render.NewDocument().Paragraph("🗑️ 已取消订阅 " + args[1]), nil
+
+			case "now":
+				kind, market := PreMarket, "us"
+				for _, a := range args[1:] {
+					if k, ok := ParseKind(a); ok {
+						kind = k
+					} else if _, ok := Markets[strings.ToLower(a)]; ok {
+						market = strings.ToLower(a)
+					} else {
+						return nil, fmt.Errorf("unknown brief or market %q", a)
+					}
+				}
+				return m.Build(ctx, msg.Platform, msg.ChatID, kind, market)
+			}
+			return nil, fmt.Errorf("unknown subcommand %q, see /help", args[0])
+		},
+	})
+}
+
+func (m *Manager) listDocument(ctx context.Context, msg *model.InternalMessage) (*render.Document, error) {
+	subs, err := m.List(ctx, msg.Platform, msg.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return render.NewDocument().Paragraph("当前会话没有简报订阅。示例: /brief add premarket cn"), nil
+	}
+
+	var rows [][]string
+	for _, s := range subs {
+		status := s.NextRun.Format("01-02 15:04 MST")
+		if s.LastError != "" {
+			status += " ⚠️"
+		}
+		rows = append(rows, []string{s.ID, Markets[s.Market].Label + kindLabels[s.Kind], s.Cron + " " + s.Timezone, status})
+	}
+	return render.NewDocument().
+		Heading("🗞️", "简报订阅").
+		Table([]string{"ID", "简报", "计划", "下次推送"}, rows), nil
+}
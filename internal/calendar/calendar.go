@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Kind separates macro releases from company earnings
+type Kind string
+
+const (
+	KindMacro    Kind = "macro"
+	KindEarnings Kind = "earnings"
+)
+
+// Importance of a macro release, 1 (low) to 3 (market moving: CPI, FOMC, NFP)
+const (
+	ImportanceLow    = 1
+	ImportanceMedium = 2
+	ImportanceHigh   = 3
+)
+
+// Event is one scheduled release or report
+type Event struct {
+	Time       time.Time `json:"time"`
+	AllDay     bool      `json:"all_day,omitempty"` // date known, time not
+	Kind       Kind      `json:"kind"`
+	Title      string    `json:"title"`
+	Country    string    `json:"country,omitempty"` // ISO code: US, CN, EU, ...
+	Importance int       `json:"importance,omitempty"`
+	Consensus  string    `json:"consensus,omitempty"`
+	Previous   string    `json:"previous,omitempty"`
+	Actual     string    `json:"actual,omitempty"`
+	Symbol     string    `json:"symbol,omitempty"` // earnings only
+	Source     string    `json:"source,omitempty"`
+}
+
+// Query selects events in [From, To)
+type Query struct {
+	From          time.Time
+	To            time.Time
+	Kind          Kind     // empty = all
+	Countries     []string // empty = all
+	MinImportance int      // macro events below this are dropped
+	Symbols       []string // earnings are looked up for these
+}
+
+func (q Query) match(e Event) bool {
+	if e.Time.Before(q.From) || !e.Time.Before(q.To) {
+		return false
+	}
+	if q.Kind != "" && e.Kind != q.Kind {
+		return false
+	}
+	if e.Kind == KindMacro && e.Importance < q.MinImportance {
+		return false
+	}
+	if len(q.Countries) > 0 && e.Kind == KindMacro {
+		for _, c := range q.Countries {
+			if strings.EqualFold(c, e.Country) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Source provides events. Implementations may ignore parts of the query;
+// the Calendar filters the merged result again.
+type Source interface {
+	Name() string
+	Events(ctx context.Context, q Query) ([]Event, error)
+}
+
+// Calendar merges several sources
+type Calendar struct {
+	Sources []Source
+	Logger  *zap.Logger
+}
+
+func New(logger *zap.Logger, sources ...Source) *Calendar {
+	return &Calendar{Sources: sources, Logger: logger}
+}
+
+// Add registers another source
+func (c *Calendar) Add(s Source) {
+	c.Sources = append(c.Sources, s)
+}
+
+// Upcoming queries all sources concurrently and returns the matching events
+// sorted by time. A failing source is logged and skipped unless all fail.
+func (c *Calendar) Upcoming(ctx context.Context, q Query) ([]Event, error) {
+	if q.From.IsZero() {
+		q.From = time.Now().Truncate(24 * time.Hour)
+	}
+	if q.To.IsZero() {
+		q.To = q.From.AddDate(0, 0, 7)
+	}
+
+	results := make([][]Event, len(c.Sources))
+	errs := make([]error, len(c.Sources))
+	var wg sync.WaitGroup
+	for i, s := range c.Sources {
+		wg.Add(1)
+		go func(i int, s Source) {
+			defer wg.Done()
+			results[i], errs[i] = s.Events(ctx, q)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var (
+		events []Event
+		failed int
+		seen   = make(map[string]bool)
+	)
+	for i, evs := range results {
+		if errs[i] != nil {
+			failed++
+			if c.Logger != nil {
+				c.Logger.Warn("Calendar source failed", zap.String("source", c.Sources[i].Name()), zap.Error(errs[i]))
+			}
+			continue
+		}
+		for _, e := range evs {
+			if e.Source == "" {
+				e.Source = c.Sources[i].Name()
+			}
+			// Sources overlap (e.g. an ICS feed and a JSON export of the same week)
+			key := e.Time.UTC().Format("2006-01-02T15:04") + "|" + strings.ToLower(e.Title) + "|" + e.Symbol
+			if !q.match(e) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			events = append(events, e)
+		}
+	}
+	if failed > 0 && failed == len(c.Sources) {
+		return nil, fmt.Errorf("all calendar sources failed: %v", errs[0])
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Time.Equal(events[j].Time) {
+			return events[i].Time.Before(events[j].Time)
+		}
+		return events[i].Importance > events[j].Importance
+	})
+	return events, nil
+}
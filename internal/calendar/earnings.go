@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+)
+
+// EarningsSource looks up the next earnings date of the query's symbols
+// through the data service's optional FundamentalsProvider.
+type EarningsSource struct {
+	Provider dataservice.FundamentalsProvider
+	TTL      time.Duration // earnings dates move rarely, default 12h
+
+	mu    sync.Mutex
+	cache map[string]earningsEntry
+}
+
+type earningsEntry struct {
+	event   *Event // nil when no date is announced
+	fetched time.Time
+}
+
+// NewEarningsSource returns nil when data has no fundamentals
+func NewEarningsSource(data dataservice.DataService) *EarningsSource {
+	fp, ok := data.(dataservice.FundamentalsProvider)
+	if !ok {
+		return nil
+	}
+	return &EarningsSource{Provider: fp, TTL: 12 * time.Hour, cache: make(map[string]earningsEntry)}
+}
+
+func (s *EarningsSource) Name() string {
+	return "earnings"
+}
+
+// Events fetches the symbols 4 at a time. Symbols without data (ETFs, crypto,
+// indices) are skipped; the source fails only if every lookup fails.
+func (s *EarningsSource) Events(ctx context.Context, q Query) ([]Event, error) {
+	if q.Kind == KindMacro || len(q.Symbols) == 0 {
+		return nil, nil
+	}
+
+	found := make([]*Event, len(q.Symbols))
+	errs := make([]error, len(q.Symbols))
+	sem := make(chan struct{}, 4)
+	var wg sync.WaitGroup
+	for i, sym := range q.Symbols {
+		wg.Add(1)
+		go func(i int, sym string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			found[i], errs[i] = s.lookup(ctx, sym)
+		}(i, sym)
+	}
+	wg.Wait()
+
+	var events []Event
+	failed := 0
+	for i, e := range found {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		if e != nil {
+			events = append(events, *e)
+		}
+	}
+	if failed == len(q.Symbols) {
+		return nil, fmt.Errorf("earnings lookup failed: %v", errs[0])
+	}
+	return events, nil
+}
+
+func (s *EarningsSource) lookup(ctx context.Context, symbol string) (*Event, error) {
+	key := strings.ToUpper(strings.TrimSpace(symbol))
+	s.mu.Lock()
+	if c, ok := s.cache[key]; ok && time.Since(c.fetched) < s.TTL {
+		s.mu.Unlock()
+		return c.event, nil
+	}
+	s.mu.Unlock()
+
+	f, err := s.Provider.GetFundamentals(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	var e *Event
+	if t, err := time.Parse("2006-01-02", f.NextEarningsDate); err == nil {
+		name := f.Name
+		if name == "" {
+			name = f.Symbol
+		}
+		e = &Event{
+			Time:       t,
+			AllDay:     true,
+			Kind:       KindEarnings,
+			Title:      name + " 财报",
+			Importance: ImportanceMedium,
+			Symbol:     f.Symbol,
+		}
+		if len(f.Quarters) > 0 {
+			// The latest reported EPS is the natural "previous" for the next report
+			if eps := f.Quarters[len(f.Quarters)-1].EPS; eps != 0 {
+				e.Previous = fmt.Sprintf("EPS %.2f", eps)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[key] = earningsEntry{event: e, fetched: time.Now()}
+	s.mu.Unlock()
+	return e, nil
+}
@@ -0,0 +1,187 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSource reads events from a local file or an http(s) URL, either JSON
+// (see ParseJSON) or iCalendar (see ParseICS). The content is cached for Refresh.
+type FileSource struct {
+	Location string
+	Refresh  time.Duration
+
+	mu      sync.Mutex
+	events  []Event
+	fetched time.Time
+}
+
+func NewFileSource(location string) *FileSource {
+	return &FileSource{Location: location, Refresh: 15 * time.Minute}
+}
+
+func (f *FileSource) Name() string {
+	return filepath.Base(f.Location)
+}
+
+func (f *FileSource) Events(ctx context.Context, q Query) ([]Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.events != nil && time.Since(f.fetched) < f.Refresh {
+		return f.events, nil
+	}
+
+	raw, err := f.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	if isICS(f.Location, raw) {
+		events, err = ParseICS(bytes.NewReader(raw))
+	} else {
+		events, err = ParseJSON(raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Location, err)
+	}
+	f.events, f.fetched = events, time.Now()
+	return events, nil
+}
+
+func (f *FileSource) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(f.Location, "http://") && !strings.HasPrefix(f.Location, "https://") {
+		return os.ReadFile(f.Location)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", f.Location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", f.Location, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func isICS(location string, raw []byte) bool {
+	if strings.EqualFold(filepath.Ext(location), ".ics") {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(raw), []byte("BEGIN:VCALENDAR"))
+}
+
+// fileEvent is the on-disk JSON shape. Time is RFC3339, "2006-01-02 15:04"
+// (in Timezone, default UTC) or a bare date for all-day events.
+type fileEvent struct {
+	Time       string `json:"time"`
+	Timezone   string `json:"timezone"`
+	Kind       Kind   `json:"kind"`
+	Title      string `json:"title"`
+	Country    string `json:"country"`
+	Importance int    `json:"importance"`
+	Consensus  value  `json:"consensus"`
+	Previous   value  `json:"previous"`
+	Actual     value  `json:"actual"`
+	Symbol     string `json:"symbol"`
+}
+
+// value accepts both 3.4 and "3.4%"
+type value string
+
+func (v *value) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = value(s)
+		return nil
+	}
+	*v = value(strings.TrimSpace(string(b)))
+	if *v == "null" {
+		*v = ""
+	}
+	return nil
+}
+
+// ParseJSON reads a list of events, either a bare array or {"events": [...]}:
+//
+//	[{"time": "2024-06-12 08:30", "timezone": "America/New_York", "title": "CPI YoY",
+//	  "country": "US", "importance": 3, "consensus": "3.4%", "previous": "3.4%"}]
+func ParseJSON(raw []byte) ([]Event, error) {
+	var list []fileEvent
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var wrapped struct {
+			Events []fileEvent `json:"events"`
+		}
+		if err2 := json.Unmarshal(raw, &wrapped); err2 != nil {
+			return nil, err
+		}
+		list = wrapped.Events
+	}
+
+	events := make([]Event, 0, len(list))
+	for i, fe := range list {
+		loc := time.UTC
+		if fe.Timezone != "" {
+			l, err := time.LoadLocation(fe.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("event %d: %w", i, err)
+			}
+			loc = l
+		}
+		t, allDay, err := parseTime(fe.Time, loc)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		e := Event{
+			Time:       t,
+			AllDay:     allDay,
+			Kind:       fe.Kind,
+			Title:      fe.Title,
+			Country:    strings.ToUpper(fe.Country),
+			Importance: fe.Importance,
+			Consensus:  string(fe.Consensus),
+			Previous:   string(fe.Previous),
+			Actual:     string(fe.Actual),
+			Symbol:     fe.Symbol,
+		}
+		if e.Kind == "" {
+			e.Kind = KindMacro
+			if e.Symbol != "" {
+				e.Kind = KindEarnings
+			}
+		}
+		if e.Importance == 0 {
+			e.Importance = ImportanceMedium
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func parseTime(s string, loc *time.Location) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q", s)
+}
@@ -0,0 +1,184 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseICS reads the VEVENTs of an iCalendar feed. Besides the standard
+// SUMMARY, DTSTART, LOCATION, CATEGORIES and PRIORITY properties it understands
+// X-COUNTRY, X-IMPORTANCE, X-CONSENSUS, X-PREVIOUS, X-ACTUAL and X-SYMBOL, and
+// "Consensus: / Previous: / Actual:" lines in the DESCRIPTION.
+func ParseICS(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []Event
+		cur    map[string]icsProp
+	)
+	for _, line := range lines {
+		p, ok := parseProp(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			cur = make(map[string]icsProp)
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if cur == nil {
+				continue
+			}
+			e, err := icsEvent(cur)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, e)
+			cur = nil
+		case cur != nil:
+			if _, dup := cur[p.name]; !dup {
+				cur[p.name] = p
+			}
+		}
+	}
+	return events, nil
+}
+
+type icsProp struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfold joins continuation lines (RFC 5545 3.1)
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+func parseProp(line string) (icsProp, bool) {
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return icsProp{}, false
+	}
+	head := strings.Split(line[:colon], ";")
+	p := icsProp{name: strings.ToUpper(head[0]), params: make(map[string]string), value: line[colon+1:]}
+	for _, kv := range head[1:] {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, true
+}
+
+func icsEvent(props map[string]icsProp) (Event, error) {
+	start, ok := props["DTSTART"]
+	if !ok {
+		return Event{}, fmt.Errorf("ics: VEVENT %q without DTSTART", unescape(props["SUMMARY"].value))
+	}
+	t, allDay, err := icsTime(start)
+	if err != nil {
+		return Event{}, err
+	}
+
+	e := Event{
+		Time:       t,
+		AllDay:     allDay,
+		Kind:       KindMacro,
+		Title:      unescape(props["SUMMARY"].value),
+		Country:    strings.ToUpper(unescape(props["X-COUNTRY"].value)),
+		Importance: ImportanceMedium,
+		Symbol:     unescape(props["X-SYMBOL"].value),
+	}
+	if e.Country == "" {
+		if loc := unescape(props["LOCATION"].value); len(loc) <= 3 {
+			e.Country = strings.ToUpper(loc)
+		}
+	}
+	if e.Symbol != "" || strings.Contains(strings.ToLower(props["CATEGORIES"].value), "earnings") {
+		e.Kind = KindEarnings
+	}
+
+	// X-IMPORTANCE is 1-3; PRIORITY is RFC 5545's 1 (highest) to 9 (lowest)
+	if n, err := strconv.Atoi(props["X-IMPORTANCE"].value); err == nil && n >= 1 && n <= 3 {
+		e.Importance = n
+	} else if n, err := strconv.Atoi(props["PRIORITY"].value); err == nil && n > 0 {
+		switch {
+		case n <= 4:
+			e.Importance = ImportanceHigh
+		case n == 5:
+			e.Importance = ImportanceMedium
+		default:
+			e.Importance = ImportanceLow
+		}
+	}
+
+	desc := unescape(props["DESCRIPTION"].value)
+	e.Consensus = firstOf(unescape(props["X-CONSENSUS"].value), descField(consensusRe, desc))
+	e.Previous = firstOf(unescape(props["X-PREVIOUS"].value), descField(previousRe, desc))
+	e.Actual = firstOf(unescape(props["X-ACTUAL"].value), descField(actualRe, desc))
+	return e, nil
+}
+
+func icsTime(p icsProp) (time.Time, bool, error) {
+	v := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(v) == 8 {
+		t, err := time.Parse("20060102", v)
+		return t, true, err
+	}
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse("20060102T150405Z", v)
+		return t, false, err
+	}
+	loc := time.UTC // floating times are read as UTC
+	if tz := p.params["TZID"]; tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", v, loc)
+	return t, false, err
+}
+
+var (
+	consensusRe = regexp.MustCompile(`(?im)^\s*(?:consensus|forecast|预期|预测)\s*[:：]\s*(.+)$`)
+	previousRe  = regexp.MustCompile(`(?im)^\s*(?:previous|prior|前值)\s*[:：]\s*(.+)$`)
+	actualRe    = regexp.MustCompile(`(?im)^\s*(?:actual|公布值|实际)\s*[:：]\s*(.+)$`)
+)
+
+func descField(re *regexp.Regexp, desc string) string {
+	if m := re.FindStringSubmatch(desc); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
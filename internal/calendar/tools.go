@@ -0,0 +1,148 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"investor/internal/render"
+	"investor/internal/tools"
+	"investor/internal/watchlist"
+)
+
+// MaxDays caps how far ahead a query looks
+const MaxDays = 60
+
+// RegisterTools exposes the calendar to the LLM. Earnings default to the
+// chat's watchlists when no symbols are given; watchlists may be nil.
+func RegisterTools(r *tools.Registry, c *Calendar, watchlists *watchlist.Manager) {
+	r.Register(tools.Tool{
+		Name:        "get_economic_calendar",
+		Description: "查询未来的宏观数据发布 (CPI、FOMC、非农等，含国家、重要性、预期值、前值) 和自选股的财报日期 (返回的 table 可直接展示)",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("向后查询的天数，默认 7，最多 %d", MaxDays),
+				},
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"description": "'macro' 宏观数据, 'earnings' 财报, 默认全部",
+					"enum":        []string{"macro", "earnings"},
+				},
+				"countries": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "国家/地区代码 (可选)，如 ['US', 'CN', 'EU']",
+				},
+				"min_importance": map[string]interface{}{
+					"type":        "integer",
+					"description": "宏观事件最低重要性 1-3 (3 = CPI/FOMC/非农级别)，默认 2",
+				},
+				"symbols": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "查询财报日期的股票 (可选)，默认使用当前用户和群的自选列表",
+				},
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Days          int      `json:"days"`
+				Kind          Kind     `json:"kind"`
+				Countries     []string `json:"countries"`
+				MinImportance int      `json:"min_importance"`
+				Symbols       []string `json:"symbols"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.Days <= 0 {
+				args.Days = 7
+			}
+			if args.Days > MaxDays {
+				args.Days = MaxDays
+			}
+			if args.MinImportance == 0 {
+				args.MinImportance = ImportanceMedium
+			}
+			if len(args.Symbols) == 0 && args.Kind != KindMacro && watchlists != nil {
+				if msg := tools.MessageFrom(ctx); msg != nil {
+					args.Symbols, _ = watchlists.Watched(ctx, msg)
+				}
+			}
+
+			from := time.Now().UTC().Truncate(24 * time.Hour)
+			events, err := c.Upcoming(ctx, Query{
+				From:          from,
+				To:            from.AddDate(0, 0, args.Days+1),
+				Kind:          args.Kind,
+				Countries:     args.Countries,
+				MinImportance: args.MinImportance,
+				Symbols:       args.Symbols,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"events": events,
+				"table":  render.MarkdownRenderer.Render(Document(events, args.Days)), // ready-to-show card
+			}, nil
+		},
+	})
+}
+
+var importanceStars = map[int]string{ImportanceLow: "★", ImportanceMedium: "★★", ImportanceHigh: "★★★"}
+
+// Document renders events as a table, times in UTC
+func Document(events []Event, days int) *render.Document {
+	doc := render.NewDocument().Heading("📅", fmt.Sprintf("未来 %d 天日历", days))
+	if len(events) == 0 {
+		return doc.Paragraph("暂无符合条件的事件。")
+	}
+
+	var rows [][]string
+	for _, e := range events {
+		when := e.Time.UTC().Format("01-02 15:04")
+		if e.AllDay {
+			when = e.Time.Format("01-02") + " 全天"
+		}
+		where := e.Country
+		if e.Kind == KindEarnings {
+			where = e.Symbol
+		}
+		rows = append(rows, []string{
+			when,
+			where,
+			e.Title,
+			importanceStars[e.Importance],
+			dash(e.Consensus),
+			dash(e.Previous),
+		})
+	}
+	return doc.
+		Table([]string{"时间(UTC)", "地区/代码", "事件", "重要性", "预期", "前值"}, rows).
+		Note("数据来源: " + strings.Join(sources(events), ", "))
+}
+
+func sources(events []Event) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if e.Source != "" && !seen[e.Source] {
+			seen[e.Source] = true
+			out = append(out, e.Source)
+		}
+	}
+	return out
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
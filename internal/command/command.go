@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"investor/internal/model"
+	"investor/internal/render"
+)
+
+// Handler runs a slash command. args are the whitespace-separated words after the command.
+type Handler func(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error)
+
+// Command is a slash command such as "/alert BTC > 100000"
+type Command struct {
+	Name    string // without the slash
+	Usage   string // e.g. "/alert <symbol> <condition> <value>"
+	Summary string
+	Handler Handler
+}
+
+// Router maps slash commands to handlers
+type Router struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+func NewRouter() *Router {
+	return &Router{commands: make(map[string]Command)}
+}
+
+func (r *Router) Register(c Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[c.Name] = c
+}
+
+// Match parses text as a slash command. Feishu group mentions may leave a
+// leading "@_user_1 " placeholder, which is skipped.
+func (r *Router) Match(text string) (Command, []string, bool) {
+	fields := strings.Fields(text)
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return Command{}, nil, false
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(fields[0], "/"))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name == "help" {
+		return Command{Name: "help", Handler: r.help}, nil, true
+	}
+	c, ok := r.commands[name]
+	return c, fields[1:], ok
+}
+
+func (r *Router) help(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []render.Field
+	for _, name := range names {
+		c := r.commands[name]
+		fields = append(fields, render.Field{Key: c.Usage, Value: c.Summary})
+	}
+	return render.NewDocument().
+		Heading("📖", "可用指令").
+		Divider().
+		Fields("", fields...), nil
+}
@@ -40,3 +40,24 @@ func (d *Dispatcher) Dispatch(ctx context.Context, msg *model.InternalMessage) (
 
 	return targetAgent.Process(ctx, msg)
 }
+
+// DispatchDetailed is like Dispatch but returns the structured reply.
+// Agents that cannot produce structured data get their text wrapped.
+func (d *Dispatcher) DispatchDetailed(ctx context.Context, msg *model.InternalMessage) (*agent.Reply, error) {
+	d.Logger.Info("Dispatching message (detailed)", zap.String("text", msg.Text))
+
+	targetAgent := d.Agents["ChatAgent"]
+	if targetAgent == nil {
+		return agent.TextReply("系统配置错误：未找到 ChatAgent"), nil
+	}
+
+	if detailed, ok := targetAgent.(agent.DetailedAgent); ok {
+		return detailed.ProcessDetailed(ctx, msg)
+	}
+
+	text, err := targetAgent.Process(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return agent.TextReply(text), nil
+}
@@ -0,0 +1,126 @@
+package dataservice
+
+import (
+	"context"
+	"fmt"
+)
+
+// CompositeDataService routes symbol queries between two sources: those the
+// Match func accepts go to Secondary (e.g. A-shares to the CN source) and
+// fall back to Primary if it fails; everything else, including news, IPOs,
+// sentiment and the index overview, comes from Primary.
+type CompositeDataService struct {
+	Primary   DataService
+	Secondary DataService
+	Match     func(symbol string) bool
+}
+
+func NewCompositeDataService(primary, secondary DataService, match func(symbol string) bool) *CompositeDataService {
+	return &CompositeDataService{Primary: primary, Secondary: secondary, Match: match}
+}
+
+func (c *CompositeDataService) routed(symbol string) bool {
+	return c.Secondary != nil && c.Match != nil && c.Match(symbol)
+}
+
+func (c *CompositeDataService) GetMarketQuote(ctx context.Context, symbol string) (*MarketQuote, error) {
+	if c.routed(symbol) {
+		if q, err := c.Secondary.GetMarketQuote(ctx, symbol); err == nil {
+			return q, nil
+		}
+	}
+	return c.Primary.GetMarketQuote(ctx, symbol)
+}
+
+// GetMarketQuotes implements QuoteBatcher: each source gets its own symbols
+// in one batch, and results are put back in request order.
+func (c *CompositeDataService) GetMarketQuotes(ctx context.Context, symbols []string) ([]QuoteResult, error) {
+	var primary, secondary []int
+	for i, sym := range symbols {
+		if c.routed(sym) {
+			secondary = append(secondary, i)
+		} else {
+			primary = append(primary, i)
+		}
+	}
+
+	results := make([]QuoteResult, len(symbols))
+	fill := func(svc DataService, idx []int) {
+		if len(idx) == 0 {
+			return
+		}
+		batch := make([]string, len(idx))
+		for j, i := range idx {
+			batch[j] = symbols[i]
+		}
+		for j, r := range GetMarketQuotes(ctx, svc, batch) {
+			results[idx[j]] = r
+		}
+	}
+	fill(c.Primary, primary)
+	fill(c.Secondary, secondary)
+
+	// A-shares the CN source missed get a second chance from the primary
+	var retry []int
+	for _, i := range secondary {
+		if results[i].Quote == nil {
+			retry = append(retry, i)
+		}
+	}
+	fill(c.Primary, retry)
+	return results, nil
+}
+
+func (c *CompositeDataService) GetHistoricalQuotes(ctx context.Context, symbol string, interval string, rangeStr string) ([]KLineItem, error) {
+	if c.routed(symbol) {
+		if klines, err := c.Secondary.GetHistoricalQuotes(ctx, symbol, interval, rangeStr); err == nil && len(klines) > 0 {
+			return klines, nil
+		}
+	}
+	return c.Primary.GetHistoricalQuotes(ctx, symbol, interval, rangeStr)
+}
+
+func (c *CompositeDataService) GetSecurityAnalysis(ctx context.Context, symbol string, assetType string) (*SecurityAnalysis, error) {
+	if c.routed(symbol) {
+		if a, err := c.Secondary.GetSecurityAnalysis(ctx, symbol, assetType); err == nil {
+			return a, nil
+		}
+	}
+	return c.Primary.GetSecurityAnalysis(ctx, symbol, assetType)
+}
+
+// GetFundamentals implements FundamentalsProvider when Primary does
+func (c *CompositeDataService) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	fp, ok := c.Primary.(FundamentalsProvider)
+	if !ok {
+		return nil, fmt.Errorf("fundamentals are not available from this data source")
+	}
+	return fp.GetFundamentals(ctx, symbol)
+}
+
+// GetSectors implements SectorProvider when Secondary does
+func (c *CompositeDataService) GetSectors(ctx context.Context, symbol string) ([]Sector, error) {
+	if sp, ok := c.Secondary.(SectorProvider); ok && c.routed(symbol) {
+		return sp.GetSectors(ctx, symbol)
+	}
+	if sp, ok := c.Primary.(SectorProvider); ok {
+		return sp.GetSectors(ctx, symbol)
+	}
+	return nil, fmt.Errorf("sector data is not available for %s", symbol)
+}
+
+func (c *CompositeDataService) GetMarketIndex(ctx context.Context) ([]IndexQuote, error) {
+	return c.Primary.GetMarketIndex(ctx)
+}
+
+func (c *CompositeDataService) GetIPOList(ctx context.Context) ([]IPOInfo, error) {
+	return c.Primary.GetIPOList(ctx)
+}
+
+func (c *CompositeDataService) SearchMarketNews(ctx context.Context, query string) ([]NewsItem, error) {
+	return c.Primary.SearchMarketNews(ctx, query)
+}
+
+func (c *CompositeDataService) GetMarketSentiment(ctx context.Context, market string) (*SentimentData, error) {
+	return c.Primary.GetMarketSentiment(ctx, market)
+}
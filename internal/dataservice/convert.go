@@ -0,0 +1,53 @@
+package dataservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"investor/internal/symbols"
+)
+
+// CurrencyConverter gives exchange rates: amount_in_to = amount_in_from * Rate(from, to)
+type CurrencyConverter interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Converted is a quote's price restated in another currency
+type Converted struct {
+	Currency string  `json:"currency"`
+	Price    float64 `json:"price"`
+	Change   float64 `json:"change"`
+	Rate     float64 `json:"rate"` // units of Currency per unit of the quote currency
+}
+
+// QuoteCurrency is the currency a quote is priced in: the provider's, else
+// the instrument master's. Empty when neither knows.
+func QuoteCurrency(q *MarketQuote) string {
+	if q.Currency != "" {
+		return q.Currency
+	}
+	if res := symbols.Default().Resolve(q.Symbol); res.Best != nil {
+		return res.Best.Instrument.Currency
+	}
+	return ""
+}
+
+// ConvertQuote fills q.Converted with the price in currency to. Nothing is
+// added when the quote is already in that currency.
+func ConvertQuote(ctx context.Context, conv CurrencyConverter, q *MarketQuote, to string) error {
+	from := QuoteCurrency(q)
+	if from == "" {
+		return fmt.Errorf("quote currency of %s is unknown", q.Symbol)
+	}
+	to = strings.ToUpper(strings.TrimSpace(to))
+	if from == to {
+		return nil
+	}
+	rate, err := conv.Rate(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	q.Converted = &Converted{Currency: to, Price: q.Price * rate, Change: q.Change * rate, Rate: rate}
+	return nil
+}
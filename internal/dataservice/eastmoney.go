@@ -0,0 +1,380 @@
+package dataservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"investor/internal/symbols"
+)
+
+// CNDataService is a native A-share source on Eastmoney's public quote API:
+// real-time quotes with turnover rate, traded amount, limit prices and
+// suspension, forward-adjusted bars and sector (board) membership. It only
+// knows Shanghai, Shenzhen and Beijing symbols; CompositeDataService routes
+// those here and everything else to the primary source.
+type CNDataService struct {
+	QuoteURL   string // push2 host, overridable for tests
+	HistoryURL string // push2his host
+	// Bars, if set, keeps downloaded history on disk and only tops it up
+	Bars BarCache
+}
+
+func NewCNDataService() *CNDataService {
+	return &CNDataService{
+		QuoteURL:   "https://push2.eastmoney.com",
+		HistoryURL: "https://push2his.eastmoney.com",
+	}
+}
+
+// CNIndexSymbols is the A-share overview: SSE Composite, SZSE Component, ChiNext, CSI 300
+var CNIndexSymbols = []string{"000001.SS", "399001.SZ", "399006.SZ", "000300.SS"}
+
+// Sector is a board (industry, concept or region) a stock belongs to
+type Sector struct {
+	Code      string  `json:"code"` // e.g. BK0477
+	Name      string  `json:"name"`
+	ChangePct float64 `json:"change_pct"` // the board's move today
+}
+
+// SectorProvider is an optional capability: the boards a stock belongs to
+type SectorProvider interface {
+	GetSectors(ctx context.Context, symbol string) ([]Sector, error)
+}
+
+// IsAShare reports whether the symbol is a Shanghai, Shenzhen or Beijing listing.
+// It only looks at the instrument master and the code itself, never online,
+// so routing a name it does not know costs nothing (the primary resolves it).
+func IsAShare(symbol string) bool {
+	code := strings.ToUpper(strings.TrimSpace(symbol))
+	if res := symbols.Default().Resolve(symbol); res.Best != nil {
+		code = res.Best.Instrument.Code
+	} else if guess, ok := symbols.GuessCode(symbol); ok {
+		code = guess
+	}
+	_, ok := cnSecID(code)
+	return ok
+}
+
+// cnSymbol is normalizeSymbol without the online search for codes that are
+// already listed symbols (600519.SS, 000001.sz, 600519.SH)
+func cnSymbol(symbol string) string {
+	upper := strings.ToUpper(strings.TrimSpace(symbol))
+	if _, ok := cnSecID(upper); ok {
+		return strings.Replace(upper, ".SH", ".SS", 1)
+	}
+	return normalizeSymbol(symbol)
+}
+
+// cnSecID maps 600519.SS to Eastmoney's "1.600519" (1 = Shanghai, 0 = Shenzhen/Beijing)
+func cnSecID(symbol string) (string, bool) {
+	code, suffix, ok := strings.Cut(strings.ToUpper(symbol), ".")
+	if !ok || len(code) != 6 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(code); err != nil {
+		return "", false
+	}
+	switch suffix {
+	case "SS", "SH":
+		return "1." + code, true
+	case "SZ", "BJ":
+		return "0." + code, true
+	}
+	return "", false
+}
+
+// emNumber is a numeric field that Eastmoney sends as "-" when there is no value
+// (e.g. the price of a suspended stock)
+type emNumber struct {
+	Value float64
+	Valid bool
+}
+
+func (n *emNumber) UnmarshalJSON(b []byte) error {
+	var f float64
+	if err := json.Unmarshal(b, &f); err == nil {
+		n.Value, n.Valid = f, true
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		n.Value, n.Valid = f, true
+	}
+	return nil
+}
+
+// emQuoteFields are requested with fltt=2 so prices come as decimals:
+// f43 price, f44 high, f45 low, f46 open, f47 volume (lots), f48 amount,
+// f51/f52 limit up/down, f57 code, f58 name, f60 previous close,
+// f86 update time, f168 turnover %, f169 change, f170 change %
+const emQuoteFields = "f43,f44,f45,f46,f47,f48,f51,f52,f57,f58,f60,f86,f168,f169,f170"
+
+type emQuote struct {
+	Price        emNumber `json:"f43"`
+	High         emNumber `json:"f44"`
+	Low          emNumber `json:"f45"`
+	Open         emNumber `json:"f46"`
+	Volume       emNumber `json:"f47"`
+	Amount       emNumber `json:"f48"`
+	LimitUp      emNumber `json:"f51"`
+	LimitDown    emNumber `json:"f52"`
+	Code         string   `json:"f57"`
+	Name         string   `json:"f58"`
+	PrevClose    emNumber `json:"f60"`
+	Time         int64    `json:"f86"`
+	TurnoverRate emNumber `json:"f168"`
+	Change       emNumber `json:"f169"`
+	ChangePct    emNumber `json:"f170"`
+}
+
+func (s *CNDataService) get(ctx context.Context, base, path string, params url.Values, v interface{}) error {
+	body, err := httpGet(ctx, base+path+"?"+params.Encode())
+	if err != nil {
+		return fmt.Errorf("eastmoney: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("eastmoney: unexpected response: %w", err)
+	}
+	return nil
+}
+
+// resolve maps input to a listed symbol and its secid
+func (s *CNDataService) resolve(symbol string) (string, string, error) {
+	code := cnSymbol(symbol)
+	secid, ok := cnSecID(code)
+	if !ok {
+		return "", "", fmt.Errorf("%s is not an A-share symbol", symbol)
+	}
+	return code, secid, nil
+}
+
+func (s *CNDataService) GetMarketQuote(ctx context.Context, symbol string) (*MarketQuote, error) {
+	code, secid, err := s.resolve(symbol)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data *emQuote `json:"data"`
+	}
+	params := url.Values{"secid": {secid}, "fltt": {"2"}, "invt": {"2"}, "fields": {emQuoteFields}}
+	if err := s.get(ctx, s.QuoteURL, "/api/qt/stock/get", params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, fmt.Errorf("eastmoney: symbol not found: %s", code)
+	}
+	q := resp.Data.quote(code)
+	StampSession(q, time.Now())
+	return q, nil
+}
+
+var cnExchanges = map[string]string{"SS": "SSE", "SZ": "SZSE", "BJ": "BSE"}
+
+func (d *emQuote) quote(symbol string) *MarketQuote {
+	q := &MarketQuote{
+		Symbol:       symbol,
+		Name:         d.Name,
+		Price:        d.Price.Value,
+		Change:       d.Change.Value,
+		ChangePct:    d.ChangePct.Value,
+		Currency:     "CNY",
+		Open:         d.Open.Value,
+		High:         d.High.Value,
+		Low:          d.Low.Value,
+		PrevClose:    d.PrevClose.Value,
+		Volume:       d.Volume.Value * 100, // lots of 100 shares
+		Amount:       d.Amount.Value,
+		TurnoverRate: d.TurnoverRate.Value,
+		LimitUp:      d.LimitUp.Value,
+		LimitDown:    d.LimitDown.Value,
+		UpdatedAt:    time.Now().Format(time.RFC3339),
+	}
+	if _, suffix, ok := strings.Cut(symbol, "."); ok {
+		q.Exchange = cnExchanges[suffix]
+	}
+	if d.Time > 0 {
+		q.UpdatedAt = time.Unix(d.Time, 0).Format(time.RFC3339)
+	}
+	// No price during a halt: show the last close, unchanged
+	if !d.Price.Valid {
+		q.Suspended = true
+		q.Price = d.PrevClose.Value
+		q.Change, q.ChangePct = 0, 0
+	}
+	return q
+}
+
+// emKlineTypes maps Yahoo-style intervals to Eastmoney's klt
+var emKlineTypes = map[string]string{
+	"1d": "101", "1wk": "102", "1mo": "103",
+	"60m": "60", "1h": "60", "30m": "30", "15m": "15", "5m": "5",
+}
+
+func (s *CNDataService) GetHistoricalQuotes(ctx context.Context, symbol string, interval string, rangeStr string) ([]KLineItem, error) {
+	code, _, err := s.resolve(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if s.Bars != nil {
+		return s.Bars.History(ctx, code, interval, rangeStr, s.fetchKlines)
+	}
+	return s.fetchKlines(ctx, code, interval, rangeStr)
+}
+
+// fetchKlines downloads forward-adjusted bars
+func (s *CNDataService) fetchKlines(ctx context.Context, symbol string, interval string, rangeStr string) ([]KLineItem, error) {
+	_, secid, err := s.resolve(symbol)
+	if err != nil {
+		return nil, err
+	}
+	klt, ok := emKlineTypes[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %s", interval)
+	}
+	beg := "0"
+	if start, ok := RangeStart(rangeStr, time.Now()); ok && start != "" {
+		beg = strings.ReplaceAll(start, "-", "")
+	}
+
+	var resp struct {
+		Data *struct {
+			Klines []string `json:"klines"`
+		} `json:"data"`
+	}
+	params := url.Values{
+		"secid":   {secid},
+		"klt":     {klt},
+		"fqt":     {"1"},
+		"beg":     {beg},
+		"end":     {"20500101"},
+		"fields1": {"f1,f2,f3"},
+		"fields2": {"f51,f52,f53,f54,f55,f56,f57"},
+	}
+	if err := s.get(ctx, s.HistoryURL, "/api/qt/stock/kline/get", params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil || len(resp.Data.Klines) == 0 {
+		return nil, fmt.Errorf("no historical data found for %s", symbol)
+	}
+	return parseEmKlines(resp.Data.Klines), nil
+}
+
+// parseEmKlines reads "date,open,close,high,low,volume,amount" rows
+func parseEmKlines(rows []string) []KLineItem {
+	klines := make([]KLineItem, 0, len(rows))
+	for _, row := range rows {
+		f := strings.Split(row, ",")
+		if len(f) < 6 {
+			continue
+		}
+		closeVal, err := strconv.ParseFloat(f[2], 64)
+		if err != nil {
+			continue
+		}
+		volume, _ := strconv.ParseFloat(f[5], 64)
+		date := f[0]
+		if len(date) > 10 {
+			date = date[:10] // intraday rows carry "2006-01-02 15:04"
+		}
+		klines = append(klines, KLineItem{Date: date, Close: closeVal, Volume: volume})
+	}
+	return klines
+}
+
+func (s *CNDataService) GetSecurityAnalysis(ctx context.Context, symbol string, assetType string) (*SecurityAnalysis, error) {
+	q, err := s.GetMarketQuote(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	klines, err := s.GetHistoricalQuotes(ctx, q.Symbol, "1d", "3mo")
+	if err != nil || len(klines) == 0 {
+		return &SecurityAnalysis{Symbol: q.Symbol, AssetType: assetType, CurrentPrice: q.Price, Trend: "unknown"}, nil
+	}
+	return BuildSecurityAnalysis(q.Symbol, assetType, q.Price, klines), nil
+}
+
+// GetSectors implements SectorProvider
+func (s *CNDataService) GetSectors(ctx context.Context, symbol string) ([]Sector, error) {
+	code, secid, err := s.resolve(symbol)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data *struct {
+			Diff json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	params := url.Values{"spt": {"3"}, "np": {"1"}, "fltt": {"2"}, "pn": {"1"}, "pz": {"50"}, "secid": {secid}, "fields": {"f12,f14,f3"}}
+	if err := s.get(ctx, s.QuoteURL, "/api/qt/slist/get", params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, fmt.Errorf("eastmoney: no sector data for %s", code)
+	}
+
+	type board struct {
+		Code      string   `json:"f12"`
+		Name      string   `json:"f14"`
+		ChangePct emNumber `json:"f3"`
+	}
+	// np=1 returns a list; older responses use an object keyed by position
+	var boards []board
+	if err := json.Unmarshal(resp.Data.Diff, &boards); err != nil {
+		var keyed map[string]board
+		if err := json.Unmarshal(resp.Data.Diff, &keyed); err != nil {
+			return nil, fmt.Errorf("eastmoney: unexpected sector list: %w", err)
+		}
+		for i := 0; i < len(keyed); i++ {
+			if b, ok := keyed[strconv.Itoa(i)]; ok {
+				boards = append(boards, b)
+			}
+		}
+	}
+
+	sectors := make([]Sector, 0, len(boards))
+	for _, b := range boards {
+		sectors = append(sectors, Sector{Code: b.Code, Name: b.Name, ChangePct: b.ChangePct.Value})
+	}
+	return sectors, nil
+}
+
+// GetMarketIndex quotes the main A-share indices
+func (s *CNDataService) GetMarketIndex(ctx context.Context) ([]IndexQuote, error) {
+	var indices []IndexQuote
+	for _, r := range FetchQuotes(ctx, s, CNIndexSymbols) {
+		if q := r.Quote; q != nil {
+			name := q.Name
+			if name == "" {
+				name = q.Symbol
+			}
+			indices = append(indices, IndexQuote{Name: name, Value: q.Price, Change: q.Change, ChangePct: q.ChangePct})
+		}
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("eastmoney: no index data")
+	}
+	return indices, nil
+}
+
+// The CN source has no news, IPO calendar or sentiment feed of its own;
+// CompositeDataService takes those from the primary source.
+
+func (s *CNDataService) GetIPOList(ctx context.Context) ([]IPOInfo, error) {
+	return nil, fmt.Errorf("IPO list is not available from the A-share source")
+}
+
+func (s *CNDataService) SearchMarketNews(ctx context.Context, query string) ([]NewsItem, error) {
+	return nil, fmt.Errorf("news is not available from the A-share source")
+}
+
+func (s *CNDataService) GetMarketSentiment(ctx context.Context, market string) (*SentimentData, error) {
+	return nil, fmt.Errorf("sentiment is not available from the A-share source")
+}
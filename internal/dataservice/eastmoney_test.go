@@ -0,0 +1,225 @@
+package dataservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// eastmoneyServer replays recorded responses from testdata/eastmoney, keyed
+// by endpoint and secid.
+func eastmoneyServer(t *testing.T) *httptest.Server {
+	fixtures := map[string]string{
+		"/api/qt/stock/get 1.600519":       "quote_600519.json",
+		"/api/qt/stock/get 0.000979":       "quote_suspended.json",
+		"/api/qt/stock/kline/get 1.600519": "kline_600519.json",
+		"/api/qt/slist/get 1.600519":       "sectors_600519.json",
+		"/api/qt/slist/get 0.000001":       "sectors_keyed.json",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := fixtures[r.URL.Path+" "+r.URL.Query().Get("secid")]
+		if !ok {
+			w.Write([]byte(`{"rc":0,"data":null}`))
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "eastmoney", name))
+		if err != nil {
+			t.Errorf("fixture: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestCN(t *testing.T) *CNDataService {
+	srv := eastmoneyServer(t)
+	return &CNDataService{QuoteURL: srv.URL, HistoryURL: srv.URL}
+}
+
+func TestCNSecID(t *testing.T) {
+	cases := map[string]string{
+		"600519.SS": "1.600519",
+		"600519.sh": "1.600519",
+		"000001.SZ": "0.000001",
+		"830799.BJ": "0.830799",
+		"0700.HK":   "",
+		"AAPL":      "",
+		"60051.SS":  "",
+	}
+	for in, want := range cases {
+		got, ok := cnSecID(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("cnSecID(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+}
+
+func TestIsAShare(t *testing.T) {
+	for _, s := range []string{"600519", "600519.SS", "000001.sz", "茅台"} {
+		if !IsAShare(s) {
+			t.Errorf("IsAShare(%q) = false", s)
+		}
+	}
+	for _, s := range []string{"AAPL", "0700.HK", "BTC", "^GSPC"} {
+		if IsAShare(s) {
+			t.Errorf("IsAShare(%q) = true", s)
+		}
+	}
+}
+
+func TestCNQuote(t *testing.T) {
+	cn := newTestCN(t)
+	q, err := cn.GetMarketQuote(context.Background(), "600519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Symbol != "600519.SS" || q.Name != "贵州茅台" || q.Price != 1712.5 || q.ChangePct != 0.38 {
+		t.Errorf("quote = %+v", q)
+	}
+	if q.TurnoverRate != 0.25 || q.Amount != 5380215040 || q.LimitUp != 1876.6 || q.LimitDown != 1535.4 || q.Suspended {
+		t.Errorf("details = %+v", q)
+	}
+	if !strings.Contains(q.ToMarkdown(), "换手率") {
+		t.Errorf("card misses turnover:\n%s", q.ToMarkdown())
+	}
+}
+
+func TestCNSuspendedQuote(t *testing.T) {
+	cn := newTestCN(t)
+	q, err := cn.GetMarketQuote(context.Background(), "000979.SZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Suspended || q.Price != 12 || q.Change != 0 || q.TurnoverRate != 0 {
+		t.Errorf("suspended quote = %+v", q)
+	}
+	if !strings.Contains(q.ToMarkdown(), "停牌") {
+		t.Errorf("card misses suspension:\n%s", q.ToMarkdown())
+	}
+}
+
+func TestCNQuoteNotFound(t *testing.T) {
+	cn := newTestCN(t)
+	if _, err := cn.GetMarketQuote(context.Background(), "600000.SS"); err == nil {
+		t.Error("expected an error for an empty response")
+	}
+	if _, err := cn.GetMarketQuote(context.Background(), "AAPL"); err == nil {
+		t.Error("expected an error for a non A-share symbol")
+	}
+}
+
+func TestCNHistory(t *testing.T) {
+	cn := newTestCN(t)
+	klines, err := cn.GetHistoricalQuotes(context.Background(), "600519.SS", "1d", "1mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(klines) != 5 {
+		t.Fatalf("got %d bars", len(klines))
+	}
+	if first := klines[0]; first.Date != "2025-10-09" || first.Close != 1695.2 || first.Volume != 28110 {
+		t.Errorf("first bar = %+v", first)
+	}
+	if _, err := cn.GetHistoricalQuotes(context.Background(), "600519.SS", "2m", "1d"); err == nil {
+		t.Error("expected an error for an unsupported interval")
+	}
+}
+
+func TestCNSectors(t *testing.T) {
+	cn := newTestCN(t)
+	sectors, err := cn.GetSectors(context.Background(), "600519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sectors) != 3 || sectors[0] != (Sector{Code: "BK0477", Name: "酿酒行业", ChangePct: 0.86}) || sectors[2].ChangePct != 0 {
+		t.Errorf("sectors = %+v", sectors)
+	}
+
+	// Older responses key the list by position
+	sectors, err = cn.GetSectors(context.Background(), "000001.SZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sectors) != 2 || sectors[0].Name != "银行" || sectors[1].Name != "深圳特区" {
+		t.Errorf("keyed sectors = %+v", sectors)
+	}
+}
+
+// fakeService answers every quote with its own name, or fails for listed symbols
+type fakeService struct {
+	name string
+	fail map[string]bool
+}
+
+func (f *fakeService) GetMarketQuote(ctx context.Context, symbol string) (*MarketQuote, error) {
+	if f.fail[symbol] {
+		return nil, fmt.Errorf("%s: no quote for %s", f.name, symbol)
+	}
+	return &MarketQuote{Symbol: symbol, Name: f.name}, nil
+}
+
+func (f *fakeService) GetHistoricalQuotes(ctx context.Context, symbol, interval, rangeStr string) ([]KLineItem, error) {
+	return []KLineItem{{Date: f.name}}, nil
+}
+
+func (f *fakeService) GetSecurityAnalysis(ctx context.Context, symbol, assetType string) (*SecurityAnalysis, error) {
+	return &SecurityAnalysis{Symbol: symbol, Trend: f.name}, nil
+}
+
+func (f *fakeService) GetMarketIndex(ctx context.Context) ([]IndexQuote, error) {
+	return []IndexQuote{{Name: f.name}}, nil
+}
+
+func (f *fakeService) GetIPOList(ctx context.Context) ([]IPOInfo, error) { return nil, nil }
+
+func (f *fakeService) SearchMarketNews(ctx context.Context, query string) ([]NewsItem, error) {
+	return []NewsItem{{Title: f.name}}, nil
+}
+
+func (f *fakeService) GetMarketSentiment(ctx context.Context, market string) (*SentimentData, error) {
+	return nil, nil
+}
+
+func TestCompositeRouting(t *testing.T) {
+	primary := &fakeService{name: "primary"}
+	secondary := &fakeService{name: "cn", fail: map[string]bool{"000002.SZ": true}}
+	c := NewCompositeDataService(primary, secondary, IsAShare)
+	ctx := context.Background()
+
+	for symbol, want := range map[string]string{"AAPL": "primary", "600519": "cn", "000002.SZ": "primary"} {
+		q, err := c.GetMarketQuote(ctx, symbol)
+		if err != nil || q.Name != want {
+			t.Errorf("quote %s from %v (%v), want %s", symbol, q, err, want)
+		}
+	}
+	if klines, _ := c.GetHistoricalQuotes(ctx, "600519.SS", "1d", "1mo"); klines[0].Date != "cn" {
+		t.Errorf("A-share history from %s", klines[0].Date)
+	}
+	if a, _ := c.GetSecurityAnalysis(ctx, "TSLA", "stock"); a.Trend != "primary" {
+		t.Errorf("US analysis from %s", a.Trend)
+	}
+	if news, _ := c.SearchMarketNews(ctx, "茅台"); news[0].Title != "primary" {
+		t.Errorf("news from %s", news[0].Title)
+	}
+
+	results := GetMarketQuotes(ctx, c, []string{"AAPL", "600519", "000002.SZ", "0700.HK"})
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Symbol+"="+r.Quote.Name)
+	}
+	if want := "AAPL=primary 600519=cn 000002.SZ=primary 0700.HK=primary"; strings.Join(got, " ") != want {
+		t.Errorf("batch = %v, want %s", got, want)
+	}
+
+	if _, err := c.GetSectors(ctx, "600519"); err == nil {
+		t.Error("expected an error: no source provides sectors")
+	}
+}
@@ -0,0 +1,386 @@
+package dataservice
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/symbols"
+)
+
+// FileDataService serves everything from a directory, for offline use,
+// research on fixed data and reproducible tests:
+//
+//	<dir>/AAPL.csv             daily bars (also <dir>/1d/AAPL.csv)
+//	<dir>/1wk/AAPL.csv         other intervals in a sub-directory
+//	<dir>/news.json            [{"title", "summary", "source", "time", "tags": ["us"], "symbols": ["AAPL"]}]
+//	<dir>/ipo.json             [IPOInfo...] (optional)
+//	<dir>/sentiment.json       {"crypto": SentimentData, ...} (optional)
+//
+// CSV files need a header with a date column (date, time, timestamp or
+// datetime) and close; volume is optional, other columns are ignored.
+// Quotes are the last bar against the one before, and ranges are counted back
+// from the last bar, so results do not depend on today's date.
+type FileDataService struct {
+	Dir string
+
+	mu    sync.Mutex
+	cache map[string]fileSeries // path -> parsed bars
+}
+
+type fileSeries struct {
+	modTime time.Time
+	bars    []KLineItem
+}
+
+// fileNews is a news.json entry
+type fileNews struct {
+	NewsItem
+	Tags    []string `json:"tags,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// maxFileNews matches the number of items the RSS sources return
+const maxFileNews = 5
+
+func NewFileDataService(dir string) (*FileDataService, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("file data source: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("file data source: %s is not a directory", dir)
+	}
+	return &FileDataService{Dir: dir, cache: make(map[string]fileSeries)}, nil
+}
+
+// findFile looks a name up case-insensitively in a directory
+func findFile(dir, name string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(e.Name(), name) {
+			return filepath.Join(dir, e.Name()), true
+		}
+	}
+	return "", false
+}
+
+// candidates are the file names tried for user input: as typed, the master
+// code, the Yahoo ticker, then the exchange suffix guess
+func candidates(symbol string) []string {
+	symbol = strings.TrimSpace(symbol)
+	list := []string{symbol}
+	if res := symbols.Default().Resolve(symbol); res.Best != nil {
+		inst := res.Best.Instrument
+		list = append(list, inst.Code, inst.Ticker("yahoo"))
+	}
+	if code, ok := symbols.GuessCode(symbol); ok {
+		list = append(list, code)
+	}
+	return list
+}
+
+// locate returns the CSV for a symbol and interval and the symbol it is named after
+func (s *FileDataService) locate(symbol, interval string) (string, string, error) {
+	if interval == "" {
+		interval = "1d"
+	}
+	dirs := []string{filepath.Join(s.Dir, interval)}
+	if interval == "1d" {
+		dirs = append(dirs, s.Dir)
+	}
+	for _, name := range candidates(symbol) {
+		if name == "" {
+			continue
+		}
+		for _, dir := range dirs {
+			if path, ok := findFile(dir, name+".csv"); ok {
+				return path, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("no %s data for %s in %s", interval, symbol, s.Dir)
+}
+
+// load parses a CSV once and again only when the file changes
+func (s *FileDataService) load(path string) ([]KLineItem, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	cached, ok := s.cache[path]
+	s.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.bars, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	bars, err := ParseBarsCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	s.cache[path] = fileSeries{modTime: info.ModTime(), bars: bars}
+	s.mu.Unlock()
+	return bars, nil
+}
+
+// bars returns the series for a symbol and the name it was found under
+func (s *FileDataService) bars(symbol, interval string) ([]KLineItem, string, error) {
+	path, name, err := s.locate(symbol, interval)
+	if err != nil {
+		return nil, "", err
+	}
+	bars, err := s.load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(bars) == 0 {
+		return nil, "", fmt.Errorf("no bars in %s", path)
+	}
+	return bars, name, nil
+}
+
+var barDateLayouts = []string{"2006-01-02", "2006/01/02", "20060102", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", time.RFC3339}
+
+func parseBarDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range barDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	// Unix seconds or milliseconds
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 1e9 {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC().Format("2006-01-02"), nil
+		}
+		return time.Unix(n, 0).UTC().Format("2006-01-02"), nil
+	}
+	return "", fmt.Errorf("unrecognized date %q", s)
+}
+
+// ParseBarsCSV reads OHLCV rows by header name and returns them sorted by date.
+// Rows without a close (e.g. "null" holidays) are skipped.
+func ParseBarsCSV(r io.Reader) ([]KLineItem, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	pick := func(names ...string) int {
+		for _, n := range names {
+			if i, ok := col[n]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	dateCol := pick("date", "time", "timestamp", "datetime")
+	closeCol := pick("close", "adj close", "adj_close", "price")
+	volCol := pick("volume", "vol")
+	if dateCol < 0 || closeCol < 0 {
+		return nil, fmt.Errorf("csv needs date and close columns, got %v", header)
+	}
+
+	var bars []KLineItem
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if dateCol >= len(rec) || closeCol >= len(rec) {
+			continue
+		}
+		closeVal, err := strconv.ParseFloat(strings.TrimSpace(rec[closeCol]), 64)
+		if err != nil {
+			continue
+		}
+		date, err := parseBarDate(rec[dateCol])
+		if err != nil {
+			return nil, err
+		}
+		bar := KLineItem{Date: date, Close: closeVal}
+		if volCol >= 0 && volCol < len(rec) {
+			bar.Volume, _ = strconv.ParseFloat(strings.TrimSpace(rec[volCol]), 64)
+		}
+		bars = append(bars, bar)
+	}
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Date < bars[j].Date })
+	return bars, nil
+}
+
+// readJSON decodes an optional file; ok is false when it does not exist
+func (s *FileDataService) readJSON(name string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	return true, nil
+}
+
+func (s *FileDataService) GetIPOList(ctx context.Context) ([]IPOInfo, error) {
+	var list []IPOInfo
+	if _, err := s.readJSON("ipo.json", &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *FileDataService) GetMarketQuote(ctx context.Context, symbol string) (*MarketQuote, error) {
+	bars, name, err := s.bars(symbol, "1d")
+	if err != nil {
+		return nil, err
+	}
+	last := bars[len(bars)-1]
+	prev := last.Close
+	if len(bars) > 1 {
+		prev = bars[len(bars)-2].Close
+	}
+	updated, _ := time.Parse("2006-01-02", last.Date)
+	return newQuote(name, last.Close, prev, updated), nil
+}
+
+// SearchMarketNews matches the query against tags and symbols, then against
+// title and summary; "all" (or empty) returns the latest items.
+func (s *FileDataService) SearchMarketNews(ctx context.Context, query string) ([]NewsItem, error) {
+	var items []fileNews
+	if ok, err := s.readJSON("news.json", &items); err != nil || !ok {
+		return nil, err
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Time > items[j].Time })
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	codes := map[string]bool{}
+	for _, c := range candidates(query) {
+		codes[strings.ToLower(c)] = true
+	}
+	var news []NewsItem
+	for _, it := range items {
+		if len(news) >= maxFileNews {
+			break
+		}
+		if q == "" || q == "all" || newsMatches(it, q, codes) {
+			news = append(news, it.NewsItem)
+		}
+	}
+	return news, nil
+}
+
+func newsMatches(it fileNews, q string, codes map[string]bool) bool {
+	for _, t := range it.Tags {
+		if strings.EqualFold(t, q) {
+			return true
+		}
+	}
+	for _, sym := range it.Symbols {
+		if codes[strings.ToLower(sym)] {
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(it.Title), q) || strings.Contains(strings.ToLower(it.Summary), q)
+}
+
+// GetMarketIndex quotes the index symbols that have a file
+func (s *FileDataService) GetMarketIndex(ctx context.Context) ([]IndexQuote, error) {
+	var indices []IndexQuote
+	for _, sym := range IndexSymbols {
+		if q, err := s.GetMarketQuote(ctx, sym); err == nil {
+			indices = append(indices, IndexQuote{Name: sym, Value: q.Price, Change: q.Change, ChangePct: q.ChangePct})
+		}
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no index data in %s", s.Dir)
+	}
+	return indices, nil
+}
+
+func (s *FileDataService) GetSecurityAnalysis(ctx context.Context, symbol string, assetType string) (*SecurityAnalysis, error) {
+	klines, err := s.GetHistoricalQuotes(ctx, symbol, "1d", "3mo")
+	if err != nil {
+		return nil, err
+	}
+	_, name, _ := s.locate(symbol, "1d")
+	return BuildSecurityAnalysis(name, assetType, klines[len(klines)-1].Close, klines), nil
+}
+
+// GetHistoricalQuotes returns the bars within the range, counted back from the
+// last bar in the file; unknown ranges return the whole file.
+func (s *FileDataService) GetHistoricalQuotes(ctx context.Context, symbol string, interval string, rangeStr string) ([]KLineItem, error) {
+	bars, _, err := s.bars(symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+	end, _ := time.Parse("2006-01-02", bars[len(bars)-1].Date)
+	start, ok := RangeStart(rangeStr, end)
+	if !ok {
+		return bars, nil
+	}
+	i := sort.Search(len(bars), func(i int) bool { return bars[i].Date >= start })
+	return bars[i:], nil
+}
+
+// GetMarketSentiment reads sentiment.json, or derives a rough reading from the
+// S&P 500's last daily move like the Yahoo source does
+func (s *FileDataService) GetMarketSentiment(ctx context.Context, market string) (*SentimentData, error) {
+	var all map[string]*SentimentData
+	if _, err := s.readJSON("sentiment.json", &all); err != nil {
+		return nil, err
+	}
+	if sd, ok := all[market]; ok && sd != nil {
+		return sd, nil
+	}
+
+	q, err := s.GetMarketQuote(ctx, "^GSPC")
+	if err != nil {
+		return nil, fmt.Errorf("sentiment data not available for %s", market)
+	}
+	label := "Neutral"
+	if q.ChangePct > 1.0 {
+		label = "Greed"
+	} else if q.ChangePct < -1.0 {
+		label = "Fear"
+	}
+	updated, _ := time.Parse(time.RFC3339, q.UpdatedAt)
+	return &SentimentData{
+		Market:      "us_stock",
+		Score:       50 + q.ChangePct*10,
+		Label:       label,
+		Description: fmt.Sprintf("S&P 500 Daily Change is %.2f%%", q.ChangePct),
+		Timestamp:   updated.Unix(),
+	}, nil
+}
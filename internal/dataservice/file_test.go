@@ -0,0 +1,161 @@
+package dataservice
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testdata/file holds Yahoo-style daily CSVs (AAPL.csv, with a "null" row),
+// daily bars keyed by millisecond timestamps (1d/600519.SS.csv), hourly bars
+// keyed by unix seconds (1h/AAPL.csv) and news.json.
+func newTestFile(t *testing.T) *FileDataService {
+	s, err := NewFileDataService(filepath.Join("testdata", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFileLocate(t *testing.T) {
+	s := newTestFile(t)
+	cases := []struct {
+		symbol, interval string
+		want             string // path under testdata/file, "" = not found
+	}{
+		{"AAPL", "", "AAPL.csv"},
+		{"aapl", "1d", "AAPL.csv"},
+		{"600519.SS", "1d", "1d/600519.SS.csv"},
+		{"茅台", "1d", "1d/600519.SS.csv"},
+		{"600519", "1d", "1d/600519.SS.csv"},
+		{"AAPL", "1h", "1h/AAPL.csv"},
+		{"AAPL", "1wk", ""}, // only daily bars fall back to the top directory
+		{"MSFT", "1d", ""},
+	}
+	for _, c := range cases {
+		path, _, err := s.locate(c.symbol, c.interval)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("locate(%q, %q) = %s, want an error", c.symbol, c.interval, path)
+			}
+			continue
+		}
+		if err != nil || path != filepath.Join(s.Dir, c.want) {
+			t.Errorf("locate(%q, %q) = %s, %v; want %s", c.symbol, c.interval, path, err, c.want)
+		}
+	}
+}
+
+func TestFileLoad(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := NewFileDataService(dir)
+	path := filepath.Join(dir, "AAPL.csv")
+	src, _ := os.ReadFile(filepath.Join("testdata", "file", "AAPL.csv"))
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bars, err := s.load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 8 { // the "null" row is skipped
+		t.Fatalf("got %d bars, want 8", len(bars))
+	}
+	if last := bars[len(bars)-1]; last.Date != "2026-10-16" || last.Close != 232.1 || last.Volume != 44000000 {
+		t.Errorf("last bar = %+v", last)
+	}
+
+	// A changed file is parsed again
+	more := string(src) + "2026-10-19,231.00,233.00,229.00,232.80,232.80,40000000\n"
+	os.WriteFile(path, []byte(more), 0o644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if bars, _ := s.load(path); len(bars) != 9 || bars[8].Date != "2026-10-19" {
+		t.Errorf("reloaded %d bars, want 9", len(bars))
+	}
+
+	os.WriteFile(path, []byte("symbol,close\nAAPL,1\n"), 0o644)
+	os.Chtimes(path, later.Add(time.Minute), later.Add(time.Minute))
+	if _, err := s.load(path); err == nil {
+		t.Error("a CSV without a date column should fail")
+	}
+}
+
+func TestFileHistoricalQuotes(t *testing.T) {
+	s := newTestFile(t)
+	ctx := context.Background()
+	cases := []struct {
+		symbol, interval, rangeStr string
+		first, last                string
+		n                          int
+	}{
+		// ranges count back from the last bar (2026-10-16), not from today
+		{"AAPL", "1d", "5d", "2026-10-14", "2026-10-16", 3},
+		{"AAPL", "1d", "1mo", "2026-09-16", "2026-10-16", 5},
+		{"AAPL", "1d", "max", "2026-06-15", "2026-10-16", 8},
+		{"AAPL", "1d", "forever", "2026-06-15", "2026-10-16", 8},
+		{"茅台", "1d", "1y", "2026-10-14", "2026-10-16", 3},
+		{"AAPL", "1h", "1d", "2026-10-15", "2026-10-16", 4},
+	}
+	for _, c := range cases {
+		bars, err := s.GetHistoricalQuotes(ctx, c.symbol, c.interval, c.rangeStr)
+		if err != nil {
+			t.Errorf("%s %s %s: %v", c.symbol, c.interval, c.rangeStr, err)
+			continue
+		}
+		if len(bars) != c.n || bars[0].Date != c.first || bars[len(bars)-1].Date != c.last {
+			t.Errorf("%s %s %s: %d bars %s..%s, want %d %s..%s", c.symbol, c.interval, c.rangeStr,
+				len(bars), bars[0].Date, bars[len(bars)-1].Date, c.n, c.first, c.last)
+		}
+	}
+	if _, err := s.GetHistoricalQuotes(ctx, "MSFT", "1d", "1mo"); err == nil {
+		t.Error("MSFT has no file")
+	}
+}
+
+func TestFileMarketQuote(t *testing.T) {
+	q, err := newTestFile(t).GetMarketQuote(context.Background(), "茅台")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Symbol != "600519.SS" || q.Price != 1480 || math.Abs(q.Change-8.8) > 1e-9 || !strings.HasPrefix(q.UpdatedAt, "2026-10-16") {
+		t.Errorf("quote = %+v", q)
+	}
+}
+
+func TestFileNews(t *testing.T) {
+	s := newTestFile(t)
+	cases := []struct {
+		query string
+		want  []string // title prefixes, newest first
+	}{
+		{"all", []string{"贵州茅台", "Bitcoin", "Apple", "Fed", "Oil"}}, // capped at 5
+		{"macro", []string{"Fed"}},
+		{"茅台", []string{"贵州茅台"}},
+		{"aapl", []string{"Apple"}},
+		{"inventory", []string{"Oil"}},
+		{"nothing", nil},
+	}
+	for _, c := range cases {
+		news, err := s.SearchMarketNews(context.Background(), c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for i, n := range news {
+			if i < len(c.want) && strings.HasPrefix(n.Title, c.want[i]) {
+				got = append(got, c.want[i])
+			} else {
+				got = append(got, n.Title)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("news %q = %v, want %v", c.query, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dataservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"investor/internal/render"
+)
+
+// FundamentalsProvider is an optional capability of a DataService: company profile,
+// valuation and earnings. Check for it with a type assertion.
+type FundamentalsProvider interface {
+	GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error)
+}
+
+// Fundamentals is a company snapshot. Ratios are plain numbers (PE 25.3),
+// DividendYield is in percent; zero means not reported.
+type Fundamentals struct {
+	Symbol    string `json:"symbol"`
+	Name      string `json:"name"`
+	Sector    string `json:"sector,omitempty"`
+	Industry  string `json:"industry,omitempty"`
+	Country   string `json:"country,omitempty"`
+	Website   string `json:"website,omitempty"`
+	Employees int    `json:"employees,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	Currency  string `json:"currency,omitempty"`
+
+	MarketCap     float64 `json:"market_cap,omitempty"`
+	TrailingPE    float64 `json:"pe_ttm,omitempty"`
+	ForwardPE     float64 `json:"pe_forward,omitempty"`
+	PriceToBook   float64 `json:"pb,omitempty"`
+	PriceToSales  float64 `json:"ps_ttm,omitempty"`
+	DividendYield float64 `json:"dividend_yield,omitempty"`
+	TrailingEPS   float64 `json:"eps_ttm,omitempty"`
+
+	Quarters         []QuarterResult `json:"quarters,omitempty"` // last four, oldest first
+	NextEarningsDate string          `json:"next_earnings_date,omitempty"`
+}
+
+// QuarterResult is one reported quarter
+type QuarterResult struct {
+	Period      string  `json:"period"` // e.g. "3Q2024"
+	Revenue     float64 `json:"revenue,omitempty"`
+	NetIncome   float64 `json:"net_income,omitempty"`
+	EPS         float64 `json:"eps,omitempty"`
+	EPSEstimate float64 `json:"eps_estimate,omitempty"`
+}
+
+// ToDocument builds the fundamentals card as a platform-neutral document
+func (f *Fundamentals) ToDocument() *render.Document {
+	title := f.Symbol
+	if f.Name != "" {
+		title = fmt.Sprintf("%s (%s)", f.Name, f.Symbol)
+	}
+	doc := render.NewDocument().
+		Heading("🏢", title+" 基本面").
+		Divider()
+
+	var profile []string
+	for _, s := range []string{f.Sector, f.Industry, f.Country} {
+		if s != "" {
+			profile = append(profile, s)
+		}
+	}
+	if len(profile) > 0 {
+		doc.Paragraph(strings.Join(profile, " · "))
+	}
+
+	doc.Fields("估值",
+		render.Field{Key: "市值", Value: HumanNumber(f.MarketCap) + " " + f.Currency},
+		render.Field{Key: "PE(TTM)", Value: ratio(f.TrailingPE)},
+		render.Field{Key: "PE(预期)", Value: ratio(f.ForwardPE)},
+		render.Field{Key: "PB", Value: ratio(f.PriceToBook)},
+		render.Field{Key: "PS(TTM)", Value: ratio(f.PriceToSales)},
+		render.Field{Key: "股息率", Value: fmt.Sprintf("%.2f%%", f.DividendYield)},
+	)
+
+	if len(f.Quarters) > 0 {
+		var rows [][]string
+		for _, q := range f.Quarters {
+			eps := ratio(q.EPS)
+			if q.EPSEstimate != 0 {
+				eps += fmt.Sprintf(" (预期 %.2f)", q.EPSEstimate)
+			}
+			rows = append(rows, []string{q.Period, HumanNumber(q.Revenue), HumanNumber(q.NetIncome), eps})
+		}
+		doc.Table([]string{"季度", "营收", "净利润", "EPS"}, rows)
+	}
+	if f.NextEarningsDate != "" {
+		doc.Paragraph("📅 下次财报: " + f.NextEarningsDate)
+	}
+	return doc
+}
+
+// HumanNumber abbreviates large amounts (1.23T, 45.6B, 7.8M)
+func HumanNumber(v float64) string {
+	abs := v
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case v == 0:
+		return "-"
+	case abs >= 1e12:
+		return fmt.Sprintf("%.2fT", v/1e12)
+	case abs >= 1e9:
+		return fmt.Sprintf("%.2fB", v/1e9)
+	case abs >= 1e6:
+		return fmt.Sprintf("%.2fM", v/1e6)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func ratio(v float64) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,145 @@
+package dataservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"investor/internal/render"
+)
+
+// QuoteBatcher is an optional capability of a DataService: many quotes in as
+// few provider requests as possible. Use GetMarketQuotes rather than calling
+// it directly, so services without it still work.
+type QuoteBatcher interface {
+	GetMarketQuotes(ctx context.Context, symbols []string) ([]QuoteResult, error)
+}
+
+// PriceBook is a source of live prices kept up to date elsewhere (e.g. an
+// exchange WebSocket stream). It only answers when its price is fresh.
+type PriceBook interface {
+	Quote(symbol string) (*MarketQuote, bool)
+}
+
+// QuoteResult is the outcome for one requested symbol
+type QuoteResult struct {
+	Symbol string       `json:"symbol"` // as requested
+	Quote  *MarketQuote `json:"quote,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// MaxBatchQuotes caps one batch request
+const MaxBatchQuotes = 50
+
+// quoteConcurrency bounds per-symbol fallbacks
+const quoteConcurrency = 4
+
+// GetMarketQuotes returns one result per symbol, in request order. It uses the
+// service's QuoteBatcher when available and falls back to concurrent single quotes.
+func GetMarketQuotes(ctx context.Context, data DataService, symbols []string) []QuoteResult {
+	if b, ok := data.(QuoteBatcher); ok {
+		if results, err := b.GetMarketQuotes(ctx, symbols); err == nil && len(results) == len(symbols) {
+			return results
+		}
+	}
+	return FetchQuotes(ctx, data, symbols)
+}
+
+// FetchQuotes quotes every symbol with GetMarketQuote, a few at a time
+func FetchQuotes(ctx context.Context, data DataService, symbols []string) []QuoteResult {
+	results := make([]QuoteResult, len(symbols))
+	sem := make(chan struct{}, quoteConcurrency)
+	var wg sync.WaitGroup
+
+	for i, sym := range symbols {
+		wg.Add(1)
+		go func(i int, sym string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = QuoteResult{Symbol: sym}
+			q, err := data.GetMarketQuote(ctx, sym)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Quote = q
+		}(i, sym)
+	}
+	wg.Wait()
+	return results
+}
+
+// QuotesDocument renders batch results as one table; failed symbols stay in place
+// and prices that are not live are marked
+func QuotesDocument(results []QuoteResult) *render.Document {
+	var rows [][]string
+	live := true
+	converted := ""
+	for _, r := range results {
+		if r.Quote != nil && r.Quote.Converted != nil {
+			converted = r.Quote.Converted.Currency
+		}
+	}
+	for _, r := range results {
+		if r.Quote == nil {
+			row := []string{r.Symbol, "-", "-", "数据不可用"}
+			if converted != "" {
+				row = append(row, "-")
+			}
+			rows = append(rows, row)
+			live = false
+			continue
+		}
+		icon := "🟢"
+		if r.Quote.ChangePct < 0 {
+			icon = "🔴"
+		}
+		name := r.Quote.Symbol
+		if !strings.EqualFold(r.Symbol, r.Quote.Symbol) {
+			name = fmt.Sprintf("%s (%s)", r.Symbol, r.Quote.Symbol)
+		}
+		price := fmt.Sprintf("%.2f", r.Quote.Price)
+		switch r.Quote.Freshness {
+		case FreshClosed:
+			price += " (收盘)"
+		case FreshDelayed:
+			price += " (延时)"
+		case FreshBreak:
+			price += " (休市中)"
+		}
+		if r.Quote.Freshness != FreshLive {
+			live = false
+		}
+		row := []string{
+			name,
+			price,
+			fmt.Sprintf("%s %+.2f%%", icon, r.Quote.ChangePct),
+			fmt.Sprintf("%+.2f", r.Quote.Change),
+		}
+		if converted != "" {
+			// quotes already in the display currency have nothing to convert
+			value := "-"
+			if c := r.Quote.Converted; c != nil {
+				value = fmt.Sprintf("%.2f", c.Price)
+			} else if QuoteCurrency(r.Quote) == converted {
+				value = fmt.Sprintf("%.2f", r.Quote.Price)
+			}
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+	title := "行情"
+	if live && len(rows) > 0 {
+		title = "实时行情"
+	}
+	headers := []string{"标的", "价格", "涨跌幅", "涨跌"}
+	if converted != "" {
+		headers = append(headers, "折合 "+converted)
+	}
+	return render.NewDocument().
+		Heading("📊", title).
+		Table(headers, rows)
+}
@@ -55,12 +55,3 @@ func (r *DataServiceRegistry) GetDefault() DataService {
 	defer r.mu.RUnlock()
 	return r.defaultSvc
 }
-
-// CompositeDataService can try multiple sources or specific sources based on query
-// This is an example of how to extend capabilities
-type CompositeDataService struct {
-	Primary   DataService
-	Secondary DataService
-}
-
-// Implement DataService interface for CompositeDataService... (omitted for brevity, can be added if requested)
@@ -31,8 +31,47 @@ type MarketQuote struct {
 	Change    float64 `json:"change"`
 	ChangePct float64 `json:"change_pct"`
 	UpdatedAt string  `json:"updated_at"`
+
+	// Optional details, zero when the provider has none
+	Name         string  `json:"name,omitempty"`
+	Currency     string  `json:"currency,omitempty"`
+	Exchange     string  `json:"exchange,omitempty"`
+	Open         float64 `json:"open,omitempty"`
+	High         float64 `json:"high,omitempty"` // today's range
+	Low          float64 `json:"low,omitempty"`
+	PrevClose    float64 `json:"prev_close,omitempty"`
+	Volume       float64 `json:"volume,omitempty"`   // shares (or units) traded today
+	Amount       float64 `json:"amount,omitempty"`   // traded value (turnover) in the quote currency
+	High52w      float64 `json:"high_52w,omitempty"` // 52-week range
+	Low52w       float64 `json:"low_52w,omitempty"`
+	TurnoverRate float64 `json:"turnover_rate,omitempty"` // % of tradable shares changing hands (A-shares)
+	LimitUp      float64 `json:"limit_up,omitempty"`      // daily price limits (A-shares)
+	LimitDown    float64 `json:"limit_down,omitempty"`
+	Suspended    bool    `json:"suspended,omitempty"` // trading halted: Price is the last close
+
+	// Extended hours (US): the session the exchange is in and the latest
+	// pre/post-market trade; Price stays the regular-session price
+	MarketState     string  `json:"market_state,omitempty"` // PRE, REGULAR, POST or CLOSED
+	PreMarketPrice  float64 `json:"pre_market_price,omitempty"`
+	PostMarketPrice float64 `json:"post_market_price,omitempty"`
+
+	// Freshness says whether Price is a live, delayed or last-close price,
+	// from the exchange's trading calendar (see StampSession)
+	Freshness string `json:"freshness,omitempty"`
+	NextOpen  string `json:"next_open,omitempty"` // RFC3339, while the market is closed
+
+	// Converted is the price in a second currency, when one was asked for (see ConvertQuote)
+	Converted *Converted `json:"converted,omitempty"`
 }
 
+// Market states reported in MarketQuote.MarketState
+const (
+	MarketPre     = "PRE"
+	MarketRegular = "REGULAR"
+	MarketPost    = "POST"
+	MarketClosed  = "CLOSED"
+)
+
 type NewsItem struct {
 	Title   string `json:"title"`
 	Summary string `json:"summary"`
@@ -62,9 +101,10 @@ type SecurityAnalysis struct {
 	CurrentPrice    float64     `json:"current_price"`
 	MA20            float64     `json:"ma20"`
 	MA60            float64     `json:"ma60"`
-	RSI             float64     `json:"rsi"`       // 14-day RSI
-	VolumeRatio     float64     `json:"vol_ratio"` // Today vol / Avg vol
-	Trend           string      `json:"trend"`     // "bullish", "bearish", "sideways"
+	RSI             float64     `json:"rsi"`                     // 14-day RSI
+	VolumeRatio     float64     `json:"vol_ratio"`               // Today vol / Avg vol
+	VolumeProjected bool        `json:"vol_projected,omitempty"` // today's bar is partial: its volume was projected to the full session
+	Trend           string      `json:"trend"`                   // "bullish", "bearish", "sideways"
 	SupportLevel    float64     `json:"support"`
 	ResistanceLevel float64     `json:"resistance"`
 	RecentKLines    []KLineItem `json:"recent_klines"` // Last 5 days for context
@@ -76,6 +116,48 @@ type KLineItem struct {
 	Volume float64 `json:"volume"`
 }
 
+// BarFetcher downloads bars for a Yahoo-style interval and range
+type BarFetcher func(ctx context.Context, symbol, interval, rangeStr string) ([]KLineItem, error)
+
+// BarCache serves history from local storage, calling fetch for what is missing
+type BarCache interface {
+	History(ctx context.Context, symbol, interval, rangeStr string, fetch BarFetcher) ([]KLineItem, error)
+}
+
+// RangeStart is the first date (2006-01-02) a Yahoo-style range such as
+// "3mo" or "5y" covers when it ends at now; ok is false for unknown ranges.
+// "max" starts at the empty date.
+func RangeStart(rangeStr string, now time.Time) (string, bool) {
+	var t time.Time
+	switch rangeStr {
+	case "1d":
+		t = now.AddDate(0, 0, -1)
+	case "5d":
+		t = now.AddDate(0, 0, -7)
+	case "1mo":
+		t = now.AddDate(0, -1, 0)
+	case "3mo":
+		t = now.AddDate(0, -3, 0)
+	case "6mo":
+		t = now.AddDate(0, -6, 0)
+	case "1y":
+		t = now.AddDate(-1, 0, 0)
+	case "2y":
+		t = now.AddDate(-2, 0, 0)
+	case "5y":
+		t = now.AddDate(-5, 0, 0)
+	case "10y":
+		t = now.AddDate(-10, 0, 0)
+	case "ytd":
+		t = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	case "max":
+		return "", true
+	default:
+		return "", false
+	}
+	return t.Format("2006-01-02"), true
+}
+
 // MockDataService implements DataService with mock data
 type MockDataService struct{}
 
@@ -286,6 +368,33 @@ var ToolsDefinition = []map[string]interface{}{
 			},
 		},
 	},
+	{
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":        "get_historical_quotes",
+			"description": "获取标的的历史K线数据（日期、收盘价、成交量）",
+			"parameters": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"symbol": map[string]interface{}{
+						"type":        "string",
+						"description": "标的代码，如 'AAPL', 'BTC-USD', '600519.SS'",
+					},
+					"interval": map[string]interface{}{
+						"type":        "string",
+						"description": "K线周期，默认 '1d'",
+						"enum":        []string{"1h", "1d", "1wk", "1mo"},
+					},
+					"range": map[string]interface{}{
+						"type":        "string",
+						"description": "时间范围，默认 '1mo'",
+						"enum":        []string{"5d", "1mo", "3mo", "6mo", "1y", "2y", "5y"},
+					},
+				},
+				"required": []string{"symbol"},
+			},
+		},
+	},
 	{
 		"type": "function",
 		"function": map[string]interface{}{
@@ -304,4 +413,56 @@ var ToolsDefinition = []map[string]interface{}{
 			},
 		},
 	},
+	{
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":        "get_market_quotes",
+			"description": "一次获取多个标的的实时行情 (返回的 table 可直接展示)，比逐个调用 get_market_quote 更快；单个标的失败不影响其他标的",
+			"parameters": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"symbols": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "标的代码或名称列表 (最多 50 个)，如 ['AAPL', 'BTC', '茅台', '0700.HK']",
+					},
+				},
+				"required": []string{"symbols"},
+			},
+		},
+	},
+	{
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":        "get_fundamentals",
+			"description": "获取公司基本面: 行业简介、市值、PE/PB/PS、股息率、近四季度营收与EPS、下次财报日期 (仅股票)",
+			"parameters": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"symbol": map[string]interface{}{
+						"type":        "string",
+						"description": "股票代码或名称, 如 'AAPL', '腾讯', '600519'",
+					},
+				},
+				"required": []string{"symbol"},
+			},
+		},
+	},
+	{
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":        "get_sectors",
+			"description": "获取A股所属板块 (行业、概念、地域) 及板块今日涨跌幅",
+			"parameters": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"symbol": map[string]interface{}{
+						"type":        "string",
+						"description": "A股代码或名称, 如 '600519', '茅台', '000001.SZ'",
+					},
+				},
+				"required": []string{"symbol"},
+			},
+		},
+	},
 }
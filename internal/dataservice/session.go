@@ -0,0 +1,88 @@
+package dataservice
+
+import (
+	"time"
+
+	"investor/internal/tradinghours"
+)
+
+// Quote freshness, from the trading calendar of the symbol's exchange
+const (
+	FreshLive    = "live"    // the market is trading and the price is current
+	FreshDelayed = "delayed" // the market is trading but the last trade is older than DelayThreshold
+	FreshBreak   = "break"   // the day's trading is paused (lunch, futures daily break): the price is the last trade before it
+	FreshClosed  = "closed"  // the regular session is closed: the price is the last close
+	FreshUnknown = "unknown" // the date is past the exchange's holiday table: it may be a holiday
+)
+
+// DelayThreshold is how old the last trade may be and still count as live.
+// Feeds delayed by 15 minutes (Yahoo's HK and A-share quotes) fall beyond it.
+const DelayThreshold = 5 * time.Minute
+
+// StampSession sets Freshness, NextOpen and, when the provider had none,
+// MarketState from the symbol's trading calendar. Quotes of venues without a
+// calendar stay untagged.
+func StampSession(q *MarketQuote, now time.Time) {
+	ex, ok := tradinghours.ForSymbol(q.Symbol)
+	if !ok {
+		return
+	}
+	st := ex.Status(now)
+	if st.Uncovered {
+		q.Freshness = FreshUnknown
+		return
+	}
+	if q.MarketState == "" {
+		q.MarketState = st.State
+	}
+	if !st.Open() && !st.NextOpen.IsZero() {
+		q.NextOpen = st.NextOpen.Format(time.RFC3339)
+	}
+	switch {
+	case st.Break:
+		q.Freshness = FreshBreak
+	case !st.Open():
+		q.Freshness = FreshClosed
+	case quoteAge(q, now) > DelayThreshold:
+		q.Freshness = FreshDelayed
+	default:
+		q.Freshness = FreshLive
+	}
+}
+
+func quoteAge(q *MarketQuote, now time.Time) time.Duration {
+	t, err := time.Parse(time.RFC3339, q.UpdatedAt)
+	if err != nil {
+		return 0
+	}
+	return now.Sub(t)
+}
+
+// minProgress keeps the first minutes of a session from being extrapolated
+// into an absurd full-day volume
+const minProgress = 0.1
+
+// projectedVolume scales today's partial daily bar to a full session while
+// the market is trading, so comparing it with past days' volume is fair.
+// False when the last bar is not today's or the session has ended.
+func projectedVolume(symbol string, klines []KLineItem, now time.Time) (float64, bool) {
+	if len(klines) == 0 {
+		return 0, false
+	}
+	ex, ok := tradinghours.ForSymbol(symbol)
+	if !ok {
+		return 0, false
+	}
+	last := klines[len(klines)-1]
+	if last.Date != now.In(ex.Location).Format("2006-01-02") {
+		return 0, false
+	}
+	progress, ok := ex.Progress(now)
+	if !ok || progress >= 1 {
+		return 0, false
+	}
+	if progress < minProgress {
+		progress = minProgress
+	}
+	return last.Volume / progress, true
+}
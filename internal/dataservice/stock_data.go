@@ -1,97 +0,0 @@
-package dataservice
-
-// StockMap contains common stock name to symbol mapping
-// In a real production environment, this should be loaded from a database or external CSV.
-var StockMap = map[string]string{
-	// A-Shares (Top Holdings & Popular)
-	"贵州茅台": "600519.SS", "茅台": "600519.SS",
-	"宁德时代": "300750.SZ", "宁王": "300750.SZ",
-	"中国平安": "601318.SS", "平安": "601318.SS",
-	"招商银行": "600036.SS", "招行": "600036.SS",
-	"五粮液":  "000858.SZ",
-	"比亚迪":  "002594.SZ",
-	"美的集团": "000333.SZ",
-	"长江电力": "600900.SS",
-	"迈瑞医疗": "300760.SZ",
-	"恒瑞医药": "600276.SS",
-	"兴业银行": "601166.SS",
-	"东方财富": "300059.SZ", "东财": "300059.SZ",
-	"格力电器": "000651.SZ",
-	"紫金矿业": "601899.SS",
-	"中信证券": "600030.SS",
-	"立讯精密": "002475.SZ",
-	"海康威视": "002415.SZ",
-	"伊利股份": "600887.SS",
-	"万华化学": "600309.SS",
-	"隆基绿能": "601012.SS",
-	"顺丰控股": "002352.SZ",
-	"工业富联": "601138.SS",
-	"药明康德": "603259.SS",
-	"中微公司": "688012.SS",
-	"金山办公": "688111.SS",
-	"海光信息": "688041.SS",
-	"寒武纪":  "688256.SS",
-	"中芯国际": "688981.SS",
-	"北方华创": "002371.SZ",
-	"韦尔股份": "603501.SS",
-	"科大讯飞": "002230.SZ",
-	"京东方":  "000725.SZ", "京东方A": "000725.SZ",
-	"中兴通讯": "000063.SZ",
-	"中国移动": "600941.SS",
-	"中国电信": "601728.SS",
-	"中国联通": "600050.SS",
-	"工商银行": "601398.SS", "工行": "601398.SS",
-	"建设银行": "601939.SS", "建行": "601939.SS",
-	"农业银行": "601288.SS", "农行": "601288.SS",
-	"中国银行": "601988.SS", "中行": "601988.SS",
-	"中国石油": "601857.SS",
-	"中国石化": "600028.SS",
-	"中国海油": "600938.SS",
-	"中国神华": "601088.SS",
-	"宝钢股份": "600019.SS",
-	"万科":   "000002.SZ", "万科A": "000002.SZ",
-	"保利发展": "600048.SS",
-
-	// HK Stocks
-	"腾讯控股": "0700.HK", "腾讯": "0700.HK",
-	"阿里巴巴": "9988.HK",
-	"美团":   "3690.HK",
-	"小米集团": "1810.HK", "小米": "1810.HK",
-	"京东集团": "9618.HK",
-	"网易":   "9999.HK",
-	"百度集团": "9888.HK",
-	"快手":   "1024.HK",
-	"理想汽车": "2015.HK",
-	"小鹏汽车": "9868.HK",
-	"蔚来":   "9866.HK",
-	"中芯国际HK": "0981.HK",
-	"商汤":   "0020.HK",
-	"哔哩哔哩": "9626.HK", "B站": "9626.HK",
-
-	// US Stocks
-	"英伟达": "NVDA",
-	"微软":  "MSFT",
-	"苹果":  "AAPL",
-	"谷歌":  "GOOG",
-	"亚马逊": "AMZN",
-	"特斯拉": "TSLA",
-	"Meta": "META", "脸书": "META",
-	"奈飞": "NFLX",
-	"台积电": "TSM",
-	"超威半导体": "AMD", "AMD": "AMD",
-	"英特尔": "INTC",
-	"高通":  "QCOM",
-	"博通":  "AVGO",
-	"美光":  "MU",
-	"甲骨文": "ORCL",
-	"赛富时": "CRM",
-	"Adobe": "ADBE",
-	"拼多多": "PDD",
-	"京东":  "JD",
-	"百度":  "BIDU",
-	"网易US": "NTES",
-	"携程":  "TCOM",
-	"贝壳":  "BEKE",
-	"Coinbase": "COIN",
-	"MicroStrategy": "MSTR",
-}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"investor/internal/render"
+	"investor/internal/tradinghours"
 )
 
-// ToMarkdown formats MarketQuote to markdown
-func (m *MarketQuote) ToMarkdown() string {
+// ToDocument builds the quote card as a platform-neutral document
+func (m *MarketQuote) ToDocument() *render.Document {
 	icon := "📈"
 	if m.Change < 0 {
 		icon = "📉"
@@ -18,7 +21,102 @@ func (m *MarketQuote) ToMarkdown() string {
 		tStr = t.Format("2006-01-02 15:04:05")
 	}
 
-	// Determine Chart Link
+	title := m.Symbol
+	if m.Name != "" {
+		title = fmt.Sprintf("%s %s", m.Name, m.Symbol)
+	}
+	price := fmt.Sprintf("%.2f", m.Price)
+	if m.Currency != "" {
+		price += " " + m.Currency
+	}
+	fields := []render.Field{
+		{Icon: "💰", Key: "价格", Value: price},
+		{Icon: icon, Key: "涨跌", Value: fmt.Sprintf("%.2f (%.2f%%)", m.Change, m.ChangePct)},
+	}
+	if c := m.Converted; c != nil {
+		fields = append(fields, render.Field{Icon: "💱", Key: "折合", Value: fmt.Sprintf("%.2f %s (%+.2f, 汇率 %.4f)", c.Price, c.Currency, c.Change, c.Rate)})
+	}
+	if m.PreMarketPrice > 0 {
+		fields = append(fields, render.Field{Icon: "🌅", Key: "盘前", Value: m.extendedHours(m.PreMarketPrice)})
+	}
+	if m.PostMarketPrice > 0 {
+		fields = append(fields, render.Field{Icon: "🌙", Key: "盘后", Value: m.extendedHours(m.PostMarketPrice)})
+	}
+	if m.Suspended {
+		fields = append(fields, render.Field{Icon: "⛔", Key: "状态", Value: "停牌 (价格为最近收盘)"})
+	} else if m.MarketState != "" {
+		fields = append(fields, render.Field{Icon: "🕒", Key: "状态", Value: tradinghours.StateLabel(m.MarketState)})
+	}
+	if t, err := time.Parse(time.RFC3339, m.NextOpen); err == nil {
+		fields = append(fields, render.Field{Icon: "⏭", Key: "下次开盘", Value: t.Format("01-02 15:04") + " (当地时间)"})
+	}
+	if day := m.dayRange(); day != "" {
+		fields = append(fields, render.Field{Icon: "🕯", Key: "今日", Value: day})
+	}
+	if m.PrevClose > 0 {
+		fields = append(fields, render.Field{Icon: "↩️", Key: "昨收", Value: fmt.Sprintf("%.2f", m.PrevClose)})
+	}
+	if m.Volume > 0 {
+		fields = append(fields, render.Field{Icon: "📦", Key: "成交量", Value: HumanNumber(m.Volume)})
+	}
+	if m.Amount > 0 {
+		fields = append(fields, render.Field{Icon: "💵", Key: "成交额", Value: HumanNumber(m.Amount)})
+	}
+	if m.TurnoverRate > 0 {
+		fields = append(fields, render.Field{Icon: "🔄", Key: "换手率", Value: fmt.Sprintf("%.2f%%", m.TurnoverRate)})
+	}
+	if m.LimitUp > 0 && m.LimitDown > 0 {
+		fields = append(fields, render.Field{Icon: "🚦", Key: "涨停/跌停", Value: fmt.Sprintf("%.2f / %.2f", m.LimitUp, m.LimitDown)})
+	}
+	if m.High52w > 0 && m.Low52w > 0 {
+		fields = append(fields, render.Field{Icon: "📏", Key: "52周", Value: fmt.Sprintf("%.2f - %.2f", m.Low52w, m.High52w)})
+	}
+	if m.Exchange != "" {
+		fields = append(fields, render.Field{Icon: "🏛", Key: "交易所", Value: m.Exchange})
+	}
+	fields = append(fields, render.Field{Icon: "⏰", Key: "更新", Value: tStr})
+
+	return render.NewDocument().
+		Heading("📊", fmt.Sprintf("%s %s", title, freshnessTitles[m.Freshness])).
+		Divider().
+		Fields("", fields...).
+		Link("🔗", "查看K线图表", m.ChartLink())
+}
+
+// freshnessTitles only call a quote real-time when the market is trading
+var freshnessTitles = map[string]string{
+	FreshLive:    "实时行情",
+	FreshDelayed: "延时行情",
+	FreshBreak:   "行情 (休市中)",
+	FreshClosed:  "收盘行情",
+	FreshUnknown: "行情",
+	"":           "行情",
+}
+
+// extendedHours shows a pre/post-market price and its move from the regular price
+func (m *MarketQuote) extendedHours(price float64) string {
+	if m.Price == 0 {
+		return fmt.Sprintf("%.2f", price)
+	}
+	return fmt.Sprintf("%.2f (%+.2f%%)", price, (price-m.Price)/m.Price*100)
+}
+
+// dayRange is "开 1.00 / 高 2.00 / 低 0.50" with whichever values are known
+func (m *MarketQuote) dayRange() string {
+	var parts []string
+	for _, p := range []struct {
+		key   string
+		value float64
+	}{{"开", m.Open}, {"高", m.High}, {"低", m.Low}} {
+		if p.value > 0 {
+			parts = append(parts, fmt.Sprintf("%s %.2f", p.key, p.value))
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// ChartLink determines the best chart page for the symbol
+func (m *MarketQuote) ChartLink() string {
 	chartLink := fmt.Sprintf("https://finance.yahoo.com/quote/%s/chart", m.Symbol)
 
 	// Special handling for Crypto
@@ -31,43 +129,21 @@ func (m *MarketQuote) ToMarkdown() string {
 			chartLink = fmt.Sprintf("https://www.tradingview.com/chart/?symbol=BINANCE:%s", m.Symbol)
 		}
 	}
+	return chartLink
+}
 
-	return fmt.Sprintf("📊 **%s 实时行情**\n-------------------\n💰 价格: %.2f\n%s 涨跌: %.2f (%.2f%%)\n⏰ 更新: %s\n🔗 [查看K线图表](%s)",
-		m.Symbol, m.Price, icon, m.Change, m.ChangePct, tStr, chartLink)
+// ToMarkdown formats MarketQuote to markdown
+func (m *MarketQuote) ToMarkdown() string {
+	return render.MarkdownRenderer.Render(m.ToDocument())
 }
 
 // GenerateSparkline creates a unicode sparkline from data
 func GenerateSparkline(data []float64) string {
-	if len(data) == 0 {
-		return ""
-	}
-	min := data[0]
-	max := data[0]
-	for _, v := range data {
-		if v < min {
-			min = v
-		}
-		if v > max {
-			max = v
-		}
-	}
-
-	rangeVal := max - min
-	if rangeVal == 0 {
-		return strings.Repeat("▅", len(data))
-	}
-
-	blocks := []string{" ", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
-	var sb strings.Builder
-	for _, v := range data {
-		idx := int((v - min) / rangeVal * float64(len(blocks)-1))
-		sb.WriteString(blocks[idx])
-	}
-	return sb.String()
+	return render.SparklineText(data)
 }
 
-// ToMarkdown formats SecurityAnalysis to markdown
-func (s *SecurityAnalysis) ToMarkdown() string {
+// ToDocument builds the technical analysis card as a platform-neutral document
+func (s *SecurityAnalysis) ToDocument() *render.Document {
 	trendIcon := "➡️"
 	if s.Trend == "bullish" {
 		trendIcon = "🐂"
@@ -75,62 +151,82 @@ func (s *SecurityAnalysis) ToMarkdown() string {
 		trendIcon = "🐻"
 	}
 
-	// Generate Sparkline from RecentKLines
+	doc := render.NewDocument().
+		Heading("🔍", fmt.Sprintf("%s 深度技术分析", s.Symbol)).
+		Divider().
+		Paragraph(fmt.Sprintf("当前价: %.2f | 趋势: %s %s", s.CurrentPrice, trendIcon, s.Trend))
+
+	// Sparkline from RecentKLines
 	var closes []float64
 	for _, k := range s.RecentKLines {
 		closes = append(closes, k.Close)
 	}
-	sparkline := GenerateSparkline(closes)
-	if sparkline != "" {
-		sparkline = "\n📈 走势: " + sparkline
-	}
-
-	return fmt.Sprintf(`🔍 **%s 深度技术分析**
--------------------
-当前价: %.2f | 趋势: %s %s%s
--------------------
-• **均线系统**:
-  MA20: %.2f
-  MA60: %.2f
-• **技术指标**:
-  RSI(14): %.2f
-  量比: %.2f
-• **关键点位**:
-  压力位: %.2f
-  支撑位: %.2f
--------------------
-*注: 以上数据仅供参考，不构成投资建议*`,
-		s.Symbol, s.CurrentPrice, trendIcon, s.Trend, sparkline,
-		s.MA20, s.MA60,
-		s.RSI, s.VolumeRatio,
-		s.ResistanceLevel, s.SupportLevel)
+	if len(closes) > 0 {
+		doc.Sparkline("📈", "走势", closes)
+	}
+
+	return doc.
+		Divider().
+		Fields("均线系统",
+			render.Field{Key: "MA20", Value: fmt.Sprintf("%.2f", s.MA20)},
+			render.Field{Key: "MA60", Value: fmt.Sprintf("%.2f", s.MA60)},
+		).
+		Fields("技术指标",
+			render.Field{Key: "RSI(14)", Value: fmt.Sprintf("%.2f", s.RSI)},
+			render.Field{Key: "量比", Value: s.volumeRatio()},
+		).
+		Fields("关键点位",
+			render.Field{Key: "压力位", Value: fmt.Sprintf("%.2f", s.ResistanceLevel)},
+			render.Field{Key: "支撑位", Value: fmt.Sprintf("%.2f", s.SupportLevel)},
+		).
+		Divider().
+		Note("注: 以上数据仅供参考，不构成投资建议")
 }
 
-// ToMarkdownList formats a slice of NewsItem to markdown
-func ToMarkdownNewsList(news []NewsItem) string {
+func (s *SecurityAnalysis) volumeRatio() string {
+	if s.VolumeProjected {
+		return fmt.Sprintf("%.2f (盘中折算全天)", s.VolumeRatio)
+	}
+	return fmt.Sprintf("%.2f", s.VolumeRatio)
+}
+
+// ToMarkdown formats SecurityAnalysis to markdown
+func (s *SecurityAnalysis) ToMarkdown() string {
+	return render.MarkdownRenderer.Render(s.ToDocument())
+}
+
+// NewsListDocument builds the news digest as a platform-neutral document
+func NewsListDocument(news []NewsItem) *render.Document {
 	if len(news) == 0 {
-		return "暂无相关新闻资讯。"
+		return render.NewDocument().Paragraph("暂无相关新闻资讯。")
 	}
 
-	var sb strings.Builder
-	sb.WriteString("📰 **最新市场资讯**\n-------------------\n")
+	doc := render.NewDocument().
+		Heading("📰", "最新市场资讯").
+		Divider()
 
 	for i, n := range news {
 		if i >= 5 {
 			break
 		}
 
-		sb.WriteString(fmt.Sprintf("• **%s**\n", n.Title))
-		sb.WriteString(fmt.Sprintf("  *来源: %s | 时间: %s*\n", n.Source, n.Time))
-		if n.Summary != "" {
-			// Truncate summary
-			summary := n.Summary
-			if len(summary) > 100 {
-				summary = summary[:100] + "..."
-			}
-			sb.WriteString(fmt.Sprintf("  > %s\n", summary))
+		// Truncate summary (rune-safe)
+		summary := []rune(n.Summary)
+		quote := string(summary)
+		if len(summary) > 100 {
+			quote = string(summary[:100]) + "..."
 		}
-		sb.WriteString("\n")
+
+		doc.Item(render.Item{
+			Title: n.Title,
+			Meta:  fmt.Sprintf("来源: %s | 时间: %s", n.Source, n.Time),
+			Quote: quote,
+		})
 	}
-	return sb.String()
+	return doc
+}
+
+// ToMarkdownNewsList formats a slice of NewsItem to markdown
+func ToMarkdownNewsList(news []NewsItem) string {
+	return render.MarkdownRenderer.Render(NewsListDocument(news))
 }
@@ -11,11 +11,19 @@ import (
 	"strings"
 	"time"
 
+	"investor/internal/indicator"
+
 	"github.com/mmcdole/gofeed"
 	"github.com/piquette/finance-go/quote"
 )
 
-type YahooDataService struct{}
+type YahooDataService struct {
+	// Live, if set, answers crypto quotes from a streamed price book before
+	// polling the exchanges' REST APIs
+	Live PriceBook
+	// Bars, if set, keeps downloaded history on disk and only tops it up
+	Bars BarCache
+}
 
 func NewYahooDataService() *YahooDataService {
 	return &YahooDataService{}
@@ -74,14 +82,8 @@ func searchYahooSymbol(query string) string {
 type YahooChartResponse struct {
 	Chart struct {
 		Result []struct {
-			Meta struct {
-				RegularMarketPrice float64 `json:"regularMarketPrice"`
-				PreviousClose      float64 `json:"previousClose"`
-				ChartPreviousClose float64 `json:"chartPreviousClose"` // Added this field
-				Symbol             string  `json:"symbol"`
-				RegularMarketTime  int64   `json:"regularMarketTime"`
-			} `json:"meta"`
-			Timestamp  []int64 `json:"timestamp"`
+			Meta       yahooMeta `json:"meta"`
+			Timestamp  []int64   `json:"timestamp"`
 			Indicators struct {
 				Quote []struct {
 					Open   []float64 `json:"open"`
@@ -99,9 +101,138 @@ type YahooChartResponse struct {
 	} `json:"chart"`
 }
 
-func getYahooPriceV8(symbol string) (*MarketQuote, error) {
-	// Use Yahoo Chart API V8 as it is more stable than Quote API
-	apiURL := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=1d", symbol)
+// yahooMeta is the quote part of a chart response
+type yahooMeta struct {
+	RegularMarketPrice   float64 `json:"regularMarketPrice"`
+	PreviousClose        float64 `json:"previousClose"`
+	ChartPreviousClose   float64 `json:"chartPreviousClose"`
+	Symbol               string  `json:"symbol"`
+	RegularMarketTime    int64   `json:"regularMarketTime"`
+	ShortName            string  `json:"shortName"`
+	Currency             string  `json:"currency"`
+	ExchangeName         string  `json:"exchangeName"`
+	FullExchangeName     string  `json:"fullExchangeName"`
+	RegularMarketDayHigh float64 `json:"regularMarketDayHigh"`
+	RegularMarketDayLow  float64 `json:"regularMarketDayLow"`
+	RegularMarketVolume  float64 `json:"regularMarketVolume"`
+	FiftyTwoWeekHigh     float64 `json:"fiftyTwoWeekHigh"`
+	FiftyTwoWeekLow      float64 `json:"fiftyTwoWeekLow"`
+	// The sessions of the exchange's current (or last) trading day
+	CurrentTradingPeriod struct {
+		Pre     yahooPeriod `json:"pre"`
+		Regular yahooPeriod `json:"regular"`
+		Post    yahooPeriod `json:"post"`
+	} `json:"currentTradingPeriod"`
+}
+
+// yahooPeriod is a session in unix seconds, end exclusive
+type yahooPeriod struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+func (p yahooPeriod) contains(t int64) bool {
+	return t >= p.Start && t < p.End
+}
+
+// prevClose falls back to the chart's previous close when the day's is missing
+func (m *yahooMeta) prevClose() float64 {
+	if m.PreviousClose != 0 {
+		return m.PreviousClose
+	}
+	return m.ChartPreviousClose
+}
+
+// marketState places now in the current trading day; empty when the meta has no sessions
+func (m *yahooMeta) marketState(now time.Time) string {
+	p := m.CurrentTradingPeriod
+	if p.Regular.End == 0 {
+		return ""
+	}
+	t := now.Unix()
+	switch {
+	case p.Pre.contains(t):
+		return MarketPre
+	case p.Regular.contains(t):
+		return MarketRegular
+	case p.Post.contains(t):
+		return MarketPost
+	}
+	return MarketClosed
+}
+
+// quote is the regular-session quote with every detail the meta carries
+func (m *yahooMeta) quote(symbol string, now time.Time) *MarketQuote {
+	q := newQuote(symbol, m.RegularMarketPrice, m.prevClose(), time.Unix(m.RegularMarketTime, 0))
+	q.Name = m.ShortName
+	q.Currency = m.Currency
+	q.Exchange = m.FullExchangeName
+	if q.Exchange == "" {
+		q.Exchange = m.ExchangeName
+	}
+	q.High = m.RegularMarketDayHigh
+	q.Low = m.RegularMarketDayLow
+	q.PrevClose = m.prevClose()
+	q.Volume = m.RegularMarketVolume
+	q.High52w = m.FiftyTwoWeekHigh
+	q.Low52w = m.FiftyTwoWeekLow
+	q.MarketState = m.marketState(now)
+	return q
+}
+
+// extendedSession is the pre- or post-market session worth fetching bars for
+// at now: the one in progress, or the post-market session once it has ended.
+// False for exchanges without extended hours.
+func (m *yahooMeta) extendedSession(now time.Time) (yahooPeriod, bool) {
+	p := m.CurrentTradingPeriod
+	t := now.Unix()
+	switch {
+	case p.Pre.End > p.Pre.Start && p.Pre.contains(t):
+		return p.Pre, true
+	case p.Post.End > p.Post.Start && t >= p.Post.Start:
+		return p.Post, true
+	}
+	return yahooPeriod{}, false
+}
+
+// applyDaily takes today's open from the daily bar of a 1d chart
+func (q *MarketQuote) applyDaily(m *yahooMeta, timestamps []int64, open []float64) {
+	for i := len(timestamps) - 1; i >= 0; i-- {
+		if i < len(open) && open[i] != 0 && m.CurrentTradingPeriod.Regular.contains(timestamps[i]) {
+			q.Open = open[i]
+			return
+		}
+	}
+}
+
+// applyIntraday sets the latest pre- or post-market trade from 5-minute bars
+// fetched with includePrePost. An extended-hours price is only news while that
+// session is the latest one.
+func (q *MarketQuote) applyIntraday(m *yahooMeta, timestamps []int64, closes []float64) {
+	p := m.CurrentTradingPeriod
+	var pre, post float64
+	for i, ts := range timestamps {
+		if i >= len(closes) || closes[i] == 0 { // null bar
+			continue
+		}
+		switch {
+		case p.Pre.contains(ts):
+			pre = closes[i]
+		case p.Post.contains(ts):
+			post = closes[i]
+		}
+	}
+	switch q.MarketState {
+	case MarketPre:
+		q.PreMarketPrice = pre
+	case MarketPost, MarketClosed:
+		q.PostMarketPrice = post
+	}
+}
+
+// getYahooChart fetches a v8 chart; query holds interval and range or periods
+func getYahooChart(symbol, query string) (*YahooChartResponse, error) {
+	apiURL := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?%s", symbol, query)
 
 	client := &http.Client{Timeout: 10 * time.Second}
 	req, _ := http.NewRequest("GET", apiURL, nil)
@@ -126,25 +257,47 @@ func getYahooPriceV8(symbol string) (*MarketQuote, error) {
 	if err := json.Unmarshal(body, &chartResp); err != nil {
 		return nil, err
 	}
-
 	if len(chartResp.Chart.Result) == 0 {
 		return nil, fmt.Errorf("symbol not found or no data: %s", symbol)
 	}
+	return &chartResp, nil
+}
 
-	meta := chartResp.Chart.Result[0].Meta
-	price := meta.RegularMarketPrice
+func getYahooPriceV8(symbol string) (*MarketQuote, error) {
+	// Use Yahoo Chart API V8 as it is more stable than Quote API. The daily
+	// chart's meta and single bar carry the quote and today's open.
+	chart, err := getYahooChart(symbol, "interval=1d&range=1d")
+	if err != nil {
+		return nil, err
+	}
+	result := chart.Chart.Result[0]
+	meta := result.Meta
 
 	// Validate price data
-	if price == 0 && meta.PreviousClose == 0 {
+	if meta.RegularMarketPrice == 0 && meta.PreviousClose == 0 {
 		return nil, fmt.Errorf("invalid price data (0.0) for symbol: %s", symbol)
 	}
 
-	// Fallback to ChartPreviousClose if PreviousClose is missing/zero
-	prevClose := meta.PreviousClose
-	if prevClose == 0 {
-		prevClose = meta.ChartPreviousClose
+	now := time.Now()
+	q := meta.quote(meta.Symbol, now)
+	if len(result.Indicators.Quote) > 0 {
+		q.applyDaily(&meta, result.Timestamp, result.Indicators.Quote[0].Open)
 	}
 
+	// Pre/post-market prices need 5-minute bars: only that session's are
+	// fetched, and only while it is the latest one. Best effort.
+	if period, ok := meta.extendedSession(now); ok {
+		query := fmt.Sprintf("interval=5m&includePrePost=true&period1=%d&period2=%d", period.Start, period.End)
+		if ext, err := getYahooChart(symbol, query); err == nil && len(ext.Chart.Result[0].Indicators.Quote) > 0 {
+			r := ext.Chart.Result[0]
+			q.applyIntraday(&meta, r.Timestamp, r.Indicators.Quote[0].Close)
+		}
+	}
+	return q, nil
+}
+
+// newQuote derives the change from the previous close
+func newQuote(symbol string, price, prevClose float64, updated time.Time) *MarketQuote {
 	change := price - prevClose
 	changePct := 0.0
 	if prevClose != 0 {
@@ -152,12 +305,12 @@ func getYahooPriceV8(symbol string) (*MarketQuote, error) {
 	}
 
 	return &MarketQuote{
-		Symbol:    meta.Symbol,
+		Symbol:    symbol,
 		Price:     price,
 		Change:    change,
 		ChangePct: changePct,
-		UpdatedAt: time.Unix(meta.RegularMarketTime, 0).Format(time.RFC3339),
-	}, nil
+		UpdatedAt: updated.Format(time.RFC3339),
+	}
 }
 
 // Binance API response
@@ -256,10 +409,24 @@ func getOkxPrice(symbol string) (*MarketQuote, error) {
 }
 
 func (s *YahooDataService) GetMarketQuote(ctx context.Context, symbol string) (*MarketQuote, error) {
+	q, err := s.getMarketQuote(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	StampSession(q, time.Now())
+	return q, nil
+}
+
+func (s *YahooDataService) getMarketQuote(ctx context.Context, symbol string) (*MarketQuote, error) {
 	symbol = normalizeSymbol(symbol)
 
 	// Strategy 1: Binance for Crypto
 	if strings.HasSuffix(strings.ToUpper(symbol), "USDT") {
+		if s.Live != nil {
+			if q, ok := s.Live.Quote(symbol); ok {
+				return q, nil
+			}
+		}
 		// Try OKX First (User requested OKX fix/support)
 		q, err := getOkxPrice(symbol)
 		if err == nil {
@@ -375,16 +542,16 @@ func (s *YahooDataService) SearchMarketNews(ctx context.Context, query string) (
 	return news, nil
 }
 
-func (s *YahooDataService) GetMarketIndex(ctx context.Context) ([]IndexQuote, error) {
-	// Re-implement GetMarketIndex using new getYahooPriceV8 logic to avoid finance-go issues
-	symbols := []string{"^GSPC", "^IXIC", "^HSI", "000001.SS", "BTC-USD", "GC=F"}
+// IndexSymbols is the market overview shown by GetMarketIndex
+var IndexSymbols = []string{"^GSPC", "^IXIC", "^HSI", "000001.SS", "BTC-USD", "GC=F"}
 
+func (s *YahooDataService) GetMarketIndex(ctx context.Context) ([]IndexQuote, error) {
+	// One batch for all indices instead of one request each
 	var indices []IndexQuote
-	for _, sym := range symbols {
-		q, err := s.GetMarketQuote(ctx, sym)
-		if err == nil {
+	for _, r := range GetMarketQuotes(ctx, s, IndexSymbols) {
+		if q := r.Quote; q != nil {
 			indices = append(indices, IndexQuote{
-				Name:      sym, // Ideally map to shortname
+				Name:      r.Symbol, // Ideally map to shortname
 				Value:     q.Price,
 				Change:    q.Change,
 				ChangePct: q.ChangePct,
@@ -416,94 +583,24 @@ func (s *YahooDataService) GetSecurityAnalysis(ctx context.Context, symbol strin
 		}, nil
 	}
 
-	var closes []float64
-	for _, k := range klines {
-		closes = append(closes, k.Close)
-	}
-
-	// 3. Calculate Indicators
-	ma20 := calculateSMA(closes, 20)
-	ma60 := calculateSMA(closes, 60)
-	rsi := calculateRSI(closes, 14)
-
-	// Calculate Volume Ratio (Last Volume / MA5 Volume)
-	volRatio := 0.0
-	if len(klines) >= 6 {
-		lastVol := klines[len(klines)-1].Volume
-		sumVol := 0.0
-		for _, k := range klines[len(klines)-6 : len(klines)-1] {
-			sumVol += k.Volume
-		}
-		avgVol := sumVol / 5.0
-		if avgVol > 0 {
-			volRatio = lastVol / avgVol
-		}
-	}
-
-	trend := "sideways"
-	if ma20 > 0 && ma60 > 0 {
-		if ma20 > ma60 && q.Price > ma20 {
-			trend = "bullish"
-		} else if ma20 < ma60 && q.Price < ma20 {
-			trend = "bearish"
-		}
-	}
-
-	recentKLines := klines
-	if len(klines) > 5 {
-		recentKLines = klines[len(klines)-5:]
-	}
-
-	// Simple Support/Resistance based on recent high/low
-	support := q.Price
-	resistance := q.Price
-	if len(klines) > 0 {
-		// Default to recent low/high
-		support = klines[len(klines)-1].Close
-		resistance = klines[len(klines)-1].Close
-
-		// Look back 20 periods or max available
-		lookback := 20
-		if len(klines) < 20 {
-			lookback = len(klines)
-		}
-
-		lows := 1000000.0
-		highs := 0.0
-		for _, k := range klines[len(klines)-lookback:] {
-			if k.Close < lows {
-				lows = k.Close
-			}
-			if k.Close > highs {
-				highs = k.Close
-			}
-		}
-		support = lows
-		resistance = highs
-	}
-
-	return &SecurityAnalysis{
-		Symbol:          symbol,
-		AssetType:       assetType,
-		CurrentPrice:    q.Price,
-		MA20:            ma20,
-		MA60:            ma60,
-		RSI:             rsi,
-		VolumeRatio:     volRatio,
-		Trend:           trend,
-		SupportLevel:    support,
-		ResistanceLevel: resistance,
-		RecentKLines:    recentKLines,
-	}, nil
+	return BuildSecurityAnalysis(symbol, assetType, q.Price, klines), nil
 }
 
 func (s *YahooDataService) GetHistoricalQuotes(ctx context.Context, symbol string, interval string, rangeStr string) ([]KLineItem, error) {
 	symbol = normalizeSymbol(symbol)
+	if s.Bars != nil {
+		return s.Bars.History(ctx, symbol, interval, rangeStr, getYahooHistory)
+	}
+	return getYahooHistory(ctx, symbol, interval, rangeStr)
+}
+
+// getYahooHistory downloads bars from the chart API
+func getYahooHistory(ctx context.Context, symbol string, interval string, rangeStr string) ([]KLineItem, error) {
 	// Yahoo API: https://query1.finance.yahoo.com/v8/finance/chart/{symbol}?interval={interval}&range={range}
 	apiURL := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=%s&range=%s", symbol, interval, rangeStr)
 
 	client := &http.Client{Timeout: 10 * time.Second}
-	req, _ := http.NewRequest("GET", apiURL, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
 	resp, err := client.Do(req)
@@ -618,40 +715,102 @@ func (s *YahooDataService) GetMarketSentiment(ctx context.Context, market string
 	return nil, fmt.Errorf("sentiment data not available for %s", market)
 }
 
-// Helpers (Same as before)
-func calculateSMA(data []float64, period int) float64 {
-	if len(data) < period {
-		return 0
+// BuildSecurityAnalysis derives the indicators (MA20/60, RSI14, volume ratio,
+// trend, 20-bar support/resistance) from daily bars and the current price.
+// Data sources share it so analyses are comparable whatever the provider.
+func BuildSecurityAnalysis(symbol, assetType string, price float64, klines []KLineItem) *SecurityAnalysis {
+	var closes []float64
+	for _, k := range klines {
+		closes = append(closes, k.Close)
+	}
+
+	// 3. Calculate Indicators
+	ma20 := calculateSMA(closes, 20)
+	ma60 := calculateSMA(closes, 60)
+	rsi := calculateRSI(closes, 14)
+
+	// Calculate Volume Ratio (Last Volume / MA5 Volume); a bar still being
+	// traded is projected to the full session first
+	volRatio := 0.0
+	projected := false
+	if len(klines) >= 6 {
+		lastVol := klines[len(klines)-1].Volume
+		if v, ok := projectedVolume(symbol, klines, time.Now()); ok {
+			lastVol, projected = v, true
+		}
+		sumVol := 0.0
+		for _, k := range klines[len(klines)-6 : len(klines)-1] {
+			sumVol += k.Volume
+		}
+		avgVol := sumVol / 5.0
+		if avgVol > 0 {
+			volRatio = lastVol / avgVol
+		}
 	}
-	sum := 0.0
-	for _, v := range data[len(data)-period:] {
-		sum += v
+
+	trend := "sideways"
+	if ma20 > 0 && ma60 > 0 {
+		if ma20 > ma60 && price > ma20 {
+			trend = "bullish"
+		} else if ma20 < ma60 && price < ma20 {
+			trend = "bearish"
+		}
 	}
-	return sum / float64(period)
-}
 
-func calculateRSI(data []float64, period int) float64 {
-	if len(data) < period+1 {
-		return 50 // default
+	recentKLines := klines
+	if len(klines) > 5 {
+		recentKLines = klines[len(klines)-5:]
 	}
 
-	gains := 0.0
-	losses := 0.0
+	// Simple Support/Resistance based on recent high/low
+	support := price
+	resistance := price
+	if len(klines) > 0 {
+		// Default to recent low/high
+		support = klines[len(klines)-1].Close
+		resistance = klines[len(klines)-1].Close
 
-	// First period
-	for i := len(data) - period; i < len(data); i++ {
-		change := data[i] - data[i-1]
-		if change > 0 {
-			gains += change
-		} else {
-			losses -= change
+		// Look back 20 periods or max available
+		lookback := 20
+		if len(klines) < 20 {
+			lookback = len(klines)
 		}
+
+		lows := 1000000.0
+		highs := 0.0
+		for _, k := range klines[len(klines)-lookback:] {
+			if k.Close < lows {
+				lows = k.Close
+			}
+			if k.Close > highs {
+				highs = k.Close
+			}
+		}
+		support = lows
+		resistance = highs
 	}
 
-	if losses == 0 {
-		return 100
+	return &SecurityAnalysis{
+		Symbol:          symbol,
+		AssetType:       assetType,
+		CurrentPrice:    price,
+		MA20:            ma20,
+		MA60:            ma60,
+		RSI:             rsi,
+		VolumeRatio:     volRatio,
+		VolumeProjected: projected,
+		Trend:           trend,
+		SupportLevel:    support,
+		ResistanceLevel: resistance,
+		RecentKLines:    recentKLines,
 	}
+}
+
+// Helpers (indicator math lives in internal/indicator)
+func calculateSMA(data []float64, period int) float64 {
+	return indicator.SMA(data, period)
+}
 
-	rs := gains / losses
-	return 100 - (100 / (1 + rs))
+func calculateRSI(data []float64, period int) float64 {
+	return indicator.RSI(data, period)
 }
@@ -0,0 +1,237 @@
+package dataservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const yahooUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// yahooSession holds the cookie + crumb pair that quoteSummary requires
+type yahooSession struct {
+	mu     sync.Mutex
+	client *http.Client
+	crumb  string
+}
+
+var yahooAuth = newYahooSession()
+
+func newYahooSession() *yahooSession {
+	jar, _ := cookiejar.New(nil)
+	return &yahooSession{client: &http.Client{Timeout: 10 * time.Second, Jar: jar}}
+}
+
+// getCrumb returns the cached crumb, fetching a new cookie and crumb when needed
+func (y *yahooSession) getCrumb(ctx context.Context, refresh bool) (string, error) {
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	if y.crumb != "" && !refresh {
+		return y.crumb, nil
+	}
+
+	// fc.yahoo.com answers 404 but sets the session cookie
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://fc.yahoo.com", nil)
+	req.Header.Set("User-Agent", yahooUserAgent)
+	if resp, err := y.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+
+	req, _ = http.NewRequestWithContext(ctx, "GET", "https://query1.finance.yahoo.com/v1/test/getcrumb", nil)
+	req.Header.Set("User-Agent", yahooUserAgent)
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	crumb := strings.TrimSpace(string(body))
+	if resp.StatusCode != 200 || crumb == "" || strings.Contains(crumb, "<") {
+		return "", fmt.Errorf("yahoo crumb unavailable: %d", resp.StatusCode)
+	}
+	y.crumb = crumb
+	return crumb, nil
+}
+
+// yahooNum is Yahoo's {"raw": 1.23, "fmt": "1.23"} value
+type yahooNum struct {
+	Raw float64 `json:"raw"`
+}
+
+type quoteSummaryResponse struct {
+	QuoteSummary struct {
+		Result []struct {
+			Price struct {
+				LongName  string   `json:"longName"`
+				ShortName string   `json:"shortName"`
+				Currency  string   `json:"currency"`
+				MarketCap yahooNum `json:"marketCap"`
+			} `json:"price"`
+			AssetProfile struct {
+				Sector              string `json:"sector"`
+				Industry            string `json:"industry"`
+				Country             string `json:"country"`
+				Website             string `json:"website"`
+				LongBusinessSummary string `json:"longBusinessSummary"`
+				FullTimeEmployees   int    `json:"fullTimeEmployees"`
+			} `json:"assetProfile"`
+			SummaryDetail struct {
+				TrailingPE    yahooNum `json:"trailingPE"`
+				ForwardPE     yahooNum `json:"forwardPE"`
+				DividendYield yahooNum `json:"dividendYield"`
+				PriceToSales  yahooNum `json:"priceToSalesTrailing12Months"`
+			} `json:"summaryDetail"`
+			DefaultKeyStatistics struct {
+				PriceToBook yahooNum `json:"priceToBook"`
+				TrailingEps yahooNum `json:"trailingEps"`
+			} `json:"defaultKeyStatistics"`
+			Earnings struct {
+				EarningsChart struct {
+					Quarterly []struct {
+						Date     string   `json:"date"`
+						Actual   yahooNum `json:"actual"`
+						Estimate yahooNum `json:"estimate"`
+					} `json:"quarterly"`
+				} `json:"earningsChart"`
+				FinancialsChart struct {
+					Quarterly []struct {
+						Date     string   `json:"date"`
+						Revenue  yahooNum `json:"revenue"`
+						Earnings yahooNum `json:"earnings"`
+					} `json:"quarterly"`
+				} `json:"financialsChart"`
+			} `json:"earnings"`
+			CalendarEvents struct {
+				Earnings struct {
+					EarningsDate []yahooNum `json:"earningsDate"`
+				} `json:"earnings"`
+			} `json:"calendarEvents"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteSummary"`
+}
+
+const quoteSummaryModules = "price,assetProfile,summaryDetail,defaultKeyStatistics,earnings,calendarEvents"
+
+// GetFundamentals implements FundamentalsProvider using Yahoo quoteSummary
+func (s *YahooDataService) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	symbol = normalizeSymbol(symbol)
+
+	var (
+		qs  quoteSummaryResponse
+		err error
+	)
+	for attempt := 0; attempt < 2; attempt++ {
+		// A stale crumb answers 401: refresh it once
+		qs, err = fetchQuoteSummary(ctx, symbol, attempt > 0)
+		if err == nil || !strings.Contains(err.Error(), "401") {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if qs.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("yahoo quoteSummary: %s", qs.QuoteSummary.Error.Description)
+	}
+	if len(qs.QuoteSummary.Result) == 0 {
+		return nil, fmt.Errorf("no fundamentals found for %s", symbol)
+	}
+	r := qs.QuoteSummary.Result[0]
+
+	f := &Fundamentals{
+		Symbol:        symbol,
+		Name:          r.Price.LongName,
+		Sector:        r.AssetProfile.Sector,
+		Industry:      r.AssetProfile.Industry,
+		Country:       r.AssetProfile.Country,
+		Website:       r.AssetProfile.Website,
+		Employees:     r.AssetProfile.FullTimeEmployees,
+		Summary:       truncateRunes(r.AssetProfile.LongBusinessSummary, 300),
+		Currency:      r.Price.Currency,
+		MarketCap:     r.Price.MarketCap.Raw,
+		TrailingPE:    r.SummaryDetail.TrailingPE.Raw,
+		ForwardPE:     r.SummaryDetail.ForwardPE.Raw,
+		PriceToBook:   r.DefaultKeyStatistics.PriceToBook.Raw,
+		PriceToSales:  r.SummaryDetail.PriceToSales.Raw,
+		DividendYield: r.SummaryDetail.DividendYield.Raw * 100,
+		TrailingEPS:   r.DefaultKeyStatistics.TrailingEps.Raw,
+	}
+	if f.Name == "" {
+		f.Name = r.Price.ShortName
+	}
+
+	// Merge revenue/net income and EPS by quarter label
+	byPeriod := make(map[string]*QuarterResult)
+	for _, q := range r.Earnings.FinancialsChart.Quarterly {
+		f.Quarters = append(f.Quarters, QuarterResult{Period: q.Date, Revenue: q.Revenue.Raw, NetIncome: q.Earnings.Raw})
+	}
+	for i := range f.Quarters {
+		byPeriod[f.Quarters[i].Period] = &f.Quarters[i]
+	}
+	for _, q := range r.Earnings.EarningsChart.Quarterly {
+		if qr, ok := byPeriod[q.Date]; ok {
+			qr.EPS, qr.EPSEstimate = q.Actual.Raw, q.Estimate.Raw
+		} else {
+			f.Quarters = append(f.Quarters, QuarterResult{Period: q.Date, EPS: q.Actual.Raw, EPSEstimate: q.Estimate.Raw})
+		}
+	}
+	if len(f.Quarters) > 4 {
+		f.Quarters = f.Quarters[len(f.Quarters)-4:]
+	}
+
+	now := time.Now().Unix()
+	for _, d := range r.CalendarEvents.Earnings.EarningsDate {
+		if int64(d.Raw) >= now-86400 {
+			f.NextEarningsDate = time.Unix(int64(d.Raw), 0).Format("2006-01-02")
+			break
+		}
+	}
+	return f, nil
+}
+
+func fetchQuoteSummary(ctx context.Context, symbol string, refresh bool) (quoteSummaryResponse, error) {
+	var qs quoteSummaryResponse
+	crumb, err := yahooAuth.getCrumb(ctx, refresh)
+	if err != nil {
+		return qs, err
+	}
+
+	apiURL := fmt.Sprintf("https://query2.finance.yahoo.com/v10/finance/quoteSummary/%s?modules=%s&crumb=%s",
+		url.PathEscape(symbol), quoteSummaryModules, url.QueryEscape(crumb))
+	req, _ := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	req.Header.Set("User-Agent", yahooUserAgent)
+
+	resp, err := yahooAuth.client.Do(req)
+	if err != nil {
+		return qs, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return qs, fmt.Errorf("yahoo quoteSummary error: 401")
+	}
+	if err := json.Unmarshal(body, &qs); err != nil {
+		return qs, fmt.Errorf("yahoo quoteSummary error: %d", resp.StatusCode)
+	}
+	return qs, nil
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
@@ -0,0 +1,234 @@
+package dataservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sparkChunk is the most symbols Yahoo's spark endpoint takes per request
+const sparkChunk = 20
+
+// GetMarketQuotes implements QuoteBatcher: the live price book and then
+// Binance's multi-symbol ticker for crypto pairs, Yahoo's spark endpoint for
+// everything else. Symbols a
+// batch misses are retried one by one with the usual fallbacks.
+func (s *YahooDataService) GetMarketQuotes(ctx context.Context, symbols []string) ([]QuoteResult, error) {
+	if len(symbols) > MaxBatchQuotes {
+		return nil, fmt.Errorf("at most %d symbols per request", MaxBatchQuotes)
+	}
+
+	results := make([]QuoteResult, len(symbols))
+	normalized := make([]string, len(symbols))
+	var crypto, others []string
+	for i, sym := range symbols {
+		results[i].Symbol = sym
+		normalized[i] = normalizeSymbol(sym)
+		if strings.HasSuffix(strings.ToUpper(normalized[i]), "USDT") {
+			crypto = append(crypto, strings.ToUpper(normalized[i]))
+		} else {
+			others = append(others, normalized[i])
+		}
+	}
+
+	found := make(map[string]*MarketQuote)
+	if s.Live != nil {
+		var polled []string
+		for _, sym := range crypto {
+			if q, ok := s.Live.Quote(sym); ok {
+				found[sym] = q
+			} else {
+				polled = append(polled, sym)
+			}
+		}
+		crypto = polled
+	}
+	if len(crypto) > 0 {
+		if quotes, err := getBinancePrices(ctx, crypto); err == nil {
+			for _, q := range quotes {
+				found[strings.ToUpper(q.Symbol)] = q
+			}
+		}
+	}
+	for start := 0; start < len(others); start += sparkChunk {
+		end := start + sparkChunk
+		if end > len(others) {
+			end = len(others)
+		}
+		if quotes, err := getYahooSpark(ctx, others[start:end]); err == nil {
+			for _, q := range quotes {
+				found[strings.ToUpper(q.Symbol)] = q
+			}
+		}
+	}
+
+	var missing []int
+	for i, sym := range normalized {
+		if q, ok := found[strings.ToUpper(sym)]; ok {
+			results[i].Quote = q
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		retry := make([]string, len(missing))
+		for j, i := range missing {
+			retry[j] = normalized[i]
+		}
+		for j, r := range FetchQuotes(ctx, s, retry) {
+			results[missing[j]].Quote, results[missing[j]].Error = r.Quote, r.Error
+		}
+	}
+	now := time.Now()
+	for _, r := range results {
+		if r.Quote != nil {
+			StampSession(r.Quote, now)
+		}
+	}
+	return results, nil
+}
+
+// getBinancePrices fetches several 24h tickers in one request. Binance rejects
+// the whole batch if one symbol is unknown.
+func getBinancePrices(ctx context.Context, symbols []string) ([]*MarketQuote, error) {
+	list, _ := json.Marshal(symbols)
+	apiURL := "https://api.binance.com/api/v3/ticker/24hr?symbols=" + url.QueryEscape(string(list))
+
+	body, err := httpGet(ctx, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("binance batch: %w", err)
+	}
+	var tickers []BinanceTicker
+	if err := json.Unmarshal(body, &tickers); err != nil {
+		return nil, err
+	}
+
+	quotes := make([]*MarketQuote, 0, len(tickers))
+	for _, t := range tickers {
+		price, _ := strconv.ParseFloat(t.LastPrice, 64)
+		change, _ := strconv.ParseFloat(t.PriceChange, 64)
+		changePct, _ := strconv.ParseFloat(t.PriceChangePercent, 64)
+		quotes = append(quotes, &MarketQuote{
+			Symbol:    t.Symbol,
+			Price:     price,
+			Change:    change,
+			ChangePct: changePct,
+			UpdatedAt: time.Now().Format(time.RFC3339),
+		})
+	}
+	return quotes, nil
+}
+
+// sparkSeries is the flat per-symbol shape of the spark endpoint
+type sparkSeries struct {
+	Symbol             string     `json:"symbol"`
+	Timestamp          []int64    `json:"timestamp"`
+	Close              []*float64 `json:"close"`
+	PreviousClose      *float64   `json:"previousClose"`
+	ChartPreviousClose *float64   `json:"chartPreviousClose"`
+}
+
+// sparkEnvelope is the older shape: one chart result per symbol
+type sparkEnvelope struct {
+	Spark struct {
+		Result []struct {
+			Symbol   string `json:"symbol"`
+			Response []struct {
+				Meta yahooMeta `json:"meta"`
+			} `json:"response"`
+		} `json:"result"`
+	} `json:"spark"`
+}
+
+// getYahooSpark fetches last price and previous close for up to 20 symbols
+func getYahooSpark(ctx context.Context, symbols []string) ([]*MarketQuote, error) {
+	apiURL := "https://query1.finance.yahoo.com/v8/finance/spark?range=1d&interval=5m&symbols=" +
+		url.QueryEscape(strings.Join(symbols, ","))
+	body, err := httpGet(ctx, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("yahoo spark: %w", err)
+	}
+	return parseSpark(body)
+}
+
+func parseSpark(body []byte) ([]*MarketQuote, error) {
+	var quotes []*MarketQuote
+
+	var env sparkEnvelope
+	if err := json.Unmarshal(body, &env); err == nil && len(env.Spark.Result) > 0 {
+		for _, r := range env.Spark.Result {
+			if len(r.Response) == 0 {
+				continue
+			}
+			meta := r.Response[0].Meta
+			if meta.RegularMarketPrice == 0 {
+				continue
+			}
+			quotes = append(quotes, meta.quote(r.Symbol, time.Now()))
+		}
+		return quotes, nil
+	}
+
+	var flat map[string]sparkSeries
+	if err := json.Unmarshal(body, &flat); err != nil {
+		return nil, fmt.Errorf("yahoo spark: unexpected response: %w", err)
+	}
+	for key, series := range flat {
+		var price float64
+		var updated time.Time
+		for i := len(series.Close) - 1; i >= 0; i-- {
+			if series.Close[i] != nil {
+				price = *series.Close[i]
+				if i < len(series.Timestamp) {
+					updated = time.Unix(series.Timestamp[i], 0)
+				}
+				break
+			}
+		}
+		if price == 0 {
+			continue
+		}
+		prev := 0.0
+		if series.PreviousClose != nil {
+			prev = *series.PreviousClose
+		} else if series.ChartPreviousClose != nil {
+			prev = *series.ChartPreviousClose
+		}
+		sym := series.Symbol
+		if sym == "" {
+			sym = key
+		}
+		quotes = append(quotes, newQuote(sym, price, prev, updated))
+	}
+	return quotes, nil
+}
+
+// httpGet performs a GET with the browser User-Agent Yahoo requires
+func httpGet(ctx context.Context, apiURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", yahooUserAgent)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return body, nil
+}
@@ -0,0 +1,139 @@
+package dataservice
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testdata/yahoo holds recorded v8 chart responses for AAPL on Friday
+// 2026-10-16: the daily chart (chart_aapl_1d.json) and the post-market
+// 5-minute bars (chart_aapl_post.json, with a null bar).
+func loadChart(t *testing.T, name string) *YahooChartResponse {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "yahoo", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chart YahooChartResponse
+	if err := json.Unmarshal(body, &chart); err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Chart.Result) == 0 {
+		t.Fatalf("%s has no result", name)
+	}
+	return &chart
+}
+
+func utc(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+func TestYahooMetaQuote(t *testing.T) {
+	r := loadChart(t, "chart_aapl_1d.json").Chart.Result[0]
+	q := r.Meta.quote("AAPL", utc("2026-10-16T21:00:00Z"))
+	q.applyDaily(&r.Meta, r.Timestamp, r.Indicators.Quote[0].Open)
+
+	if q.Symbol != "AAPL" || q.Name != "Apple Inc." || q.Currency != "USD" || q.Exchange != "NasdaqGS" {
+		t.Errorf("identity = %q %q %q %q", q.Symbol, q.Name, q.Currency, q.Exchange)
+	}
+	if q.Price != 232.1 || q.PrevClose != 235.4 || math.Abs(q.Change+3.3) > 1e-9 {
+		t.Errorf("price %v prev %v change %v", q.Price, q.PrevClose, q.Change)
+	}
+	if q.Open != 234.9 || q.High != 236 || q.Low != 231.2 || q.Volume != 44012345 {
+		t.Errorf("day = open %v high %v low %v volume %v", q.Open, q.High, q.Low, q.Volume)
+	}
+	if q.High52w != 260.1 || q.Low52w != 169.21 {
+		t.Errorf("52w = %v - %v", q.Low52w, q.High52w)
+	}
+	if q.MarketState != MarketPost {
+		t.Errorf("state = %s, want POST", q.MarketState)
+	}
+	if updated, _ := time.Parse(time.RFC3339, q.UpdatedAt); !updated.Equal(utc("2026-10-16T20:00:00Z")) {
+		t.Errorf("updated = %s, want the regular close", q.UpdatedAt)
+	}
+	if q.Amount != 0 {
+		t.Errorf("amount = %v: Yahoo reports no turnover", q.Amount)
+	}
+}
+
+func TestYahooMarketState(t *testing.T) {
+	meta := loadChart(t, "chart_aapl_1d.json").Chart.Result[0].Meta
+	cases := []struct {
+		at       string
+		state    string
+		extended string // session fetched for pre/post prices, "" = none
+	}{
+		{"2026-10-16T07:00:00Z", MarketClosed, ""},
+		{"2026-10-16T09:00:00Z", MarketPre, "pre"},
+		{"2026-10-16T13:30:00Z", MarketRegular, ""},
+		{"2026-10-16T19:59:00Z", MarketRegular, ""},
+		{"2026-10-16T20:00:00Z", MarketPost, "post"},
+		{"2026-10-17T01:00:00Z", MarketClosed, "post"}, // the last session was post-market
+	}
+	p := meta.CurrentTradingPeriod
+	for _, c := range cases {
+		now := utc(c.at)
+		if got := meta.marketState(now); got != c.state {
+			t.Errorf("%s: state %s, want %s", c.at, got, c.state)
+		}
+		period, ok := meta.extendedSession(now)
+		var got string
+		switch {
+		case ok && period == p.Pre:
+			got = "pre"
+		case ok && period == p.Post:
+			got = "post"
+		case ok:
+			got = "?"
+		}
+		if got != c.extended {
+			t.Errorf("%s: extended session %q, want %q", c.at, got, c.extended)
+		}
+	}
+
+	// Exchanges without extended hours have empty pre/post periods
+	hk := meta
+	hk.CurrentTradingPeriod.Pre = yahooPeriod{Start: p.Regular.Start, End: p.Regular.Start}
+	hk.CurrentTradingPeriod.Post = yahooPeriod{Start: p.Regular.End, End: p.Regular.End}
+	if _, ok := hk.extendedSession(utc("2026-10-16T21:00:00Z")); ok {
+		t.Error("no extended session to fetch without extended hours")
+	}
+	if got := (&yahooMeta{}).marketState(utc("2026-10-16T14:00:00Z")); got != "" {
+		t.Errorf("meta without sessions: state %q, want none", got)
+	}
+}
+
+func TestYahooApplyIntraday(t *testing.T) {
+	meta := loadChart(t, "chart_aapl_1d.json").Chart.Result[0].Meta
+	bars := loadChart(t, "chart_aapl_post.json").Chart.Result[0]
+	closes := bars.Indicators.Quote[0].Close
+
+	cases := []struct {
+		state     string
+		pre, post float64
+	}{
+		{MarketPost, 0, 231.45}, // the null bar is skipped, the last trade wins
+		{MarketClosed, 0, 231.45},
+		{MarketPre, 0, 0}, // no pre-market bars
+		{MarketRegular, 0, 0},
+	}
+	for _, c := range cases {
+		q := &MarketQuote{Price: 232.1, MarketState: c.state}
+		q.applyIntraday(&meta, bars.Timestamp, closes)
+		if q.PreMarketPrice != c.pre || q.PostMarketPrice != c.post {
+			t.Errorf("%s: pre %v post %v, want %v %v", c.state, q.PreMarketPrice, q.PostMarketPrice, c.pre, c.post)
+		}
+	}
+
+	pre := meta.CurrentTradingPeriod.Pre.Start
+	q := &MarketQuote{Price: 232.1, MarketState: MarketPre}
+	q.applyIntraday(&meta, []int64{pre + 300, pre + 600}, []float64{233, 233.8})
+	if q.PreMarketPrice != 233.8 || q.PostMarketPrice != 0 {
+		t.Errorf("pre-market: pre %v post %v, want 233.8 0", q.PreMarketPrice, q.PostMarketPrice)
+	}
+}
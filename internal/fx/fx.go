@@ -0,0 +1,183 @@
+// Package fx converts between currencies with rates derived from Yahoo
+// "XXX=X" quotes (units of XXX per USD). Cross rates are triangulated
+// through USD, e.g. HKD->CNY = CNY per USD / HKD per USD.
+package fx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+)
+
+// Service caches each currency's USD rate for TTL. When a refresh fails the
+// last known rate is served rather than failing the conversion.
+type Service struct {
+	Data dataservice.DataService
+	TTL  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedRate
+	now   func() time.Time
+}
+
+type cachedRate struct {
+	perUSD  float64
+	quoted  time.Time // the quote's own time, reported as AsOf
+	fetched time.Time // when it was downloaded, for the TTL
+}
+
+func NewService(data dataservice.DataService) *Service {
+	return &Service{
+		Data:  data,
+		TTL:   5 * time.Minute,
+		cache: make(map[string]cachedRate),
+		now:   time.Now,
+	}
+}
+
+// Conversion is the result of converting an amount
+type Conversion struct {
+	Amount float64   `json:"amount"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Rate   float64   `json:"rate"` // units of To per unit of From
+	Result float64   `json:"result"`
+	AsOf   time.Time `json:"as_of"` // time of the oldest quote used
+}
+
+// Convert restates amount in another currency. From and To are ISO codes,
+// except minor units which keep their own code ("GBp"), as the rate is per pence.
+func (s *Service) Convert(ctx context.Context, amount float64, from, to string) (*Conversion, error) {
+	rate, asOf, err := s.rate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &Conversion{Amount: amount, From: unit(from), To: unit(to), Rate: rate, Result: amount * rate, AsOf: asOf}, nil
+}
+
+// unit is the code a currency is reported under: the minor unit as written, else the ISO code
+func unit(currency string) string {
+	c := strings.TrimSpace(currency)
+	if _, ok := minorUnits[c]; ok {
+		return c
+	}
+	code, _, _ := Normalize(c)
+	return code
+}
+
+// Rate implements dataservice.CurrencyConverter and portfolio.RateProvider
+func (s *Service) Rate(ctx context.Context, from, to string) (float64, error) {
+	rate, _, err := s.rate(ctx, from, to)
+	return rate, err
+}
+
+func (s *Service) rate(ctx context.Context, from, to string) (float64, time.Time, error) {
+	fromCode, fromScale, err := Normalize(from)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	toCode, toScale, err := Normalize(to)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	scale := fromScale / toScale
+	if fromCode == toCode {
+		return scale, s.now(), nil
+	}
+
+	fromPerUSD, fromAt, err := s.perUSD(ctx, fromCode)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	toPerUSD, toAt, err := s.perUSD(ctx, toCode)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	asOf := fromAt
+	if toAt.Before(asOf) {
+		asOf = toAt
+	}
+	return toPerUSD / fromPerUSD * scale, asOf, nil
+}
+
+func (s *Service) perUSD(ctx context.Context, currency string) (float64, time.Time, error) {
+	now := s.now()
+	if currency == "USD" {
+		return 1, now, nil
+	}
+
+	s.mu.Lock()
+	c, ok := s.cache[currency]
+	s.mu.Unlock()
+	if ok && now.Sub(c.fetched) < s.TTL {
+		return c.perUSD, c.quoted, nil
+	}
+
+	quote, err := s.Data.GetMarketQuote(ctx, currency+"=X")
+	if err == nil && quote.Price <= 0 {
+		err = fmt.Errorf("no price")
+	}
+	if err != nil {
+		if ok {
+			return c.perUSD, c.quoted, nil // stale beats nothing
+		}
+		return 0, time.Time{}, fmt.Errorf("fx rate USD/%s unavailable: %w", currency, err)
+	}
+
+	quoted, perr := time.Parse(time.RFC3339, quote.UpdatedAt)
+	if perr != nil {
+		quoted = now
+	}
+	s.mu.Lock()
+	s.cache[currency] = cachedRate{perUSD: quote.Price, quoted: quoted, fetched: now}
+	s.mu.Unlock()
+	return quote.Price, quoted, nil
+}
+
+// names maps what users type to ISO codes
+var names = map[string]string{
+	"人民币": "CNY", "RMB": "CNY", "元": "CNY", "离岸人民币": "CNH",
+	"美元": "USD", "美金": "USD", "USDT": "USD", "USDC": "USD",
+	"港币": "HKD", "港元": "HKD", "日元": "JPY", "欧元": "EUR", "英镑": "GBP",
+	"澳元": "AUD", "加元": "CAD", "瑞郎": "CHF", "瑞士法郎": "CHF", "纽元": "NZD",
+	"新加坡元": "SGD", "新元": "SGD", "台币": "TWD", "新台币": "TWD", "韩元": "KRW",
+	"卢布": "RUB", "印度卢比": "INR", "泰铢": "THB",
+}
+
+// minorUnits are the sub-unit codes Yahoo prices some exchanges in
+// (London in pence, Johannesburg in cents, Tel Aviv in agorot)
+var minorUnits = map[string]string{"GBp": "GBP", "GBX": "GBP", "ZAc": "ZAR", "ILA": "ILS"}
+
+// Normalize turns a currency as written by users or providers into an ISO
+// code and the factor from the input unit to it: "港币" -> HKD, 1;
+// "GBp" -> GBP, 0.01; "usdt" -> USD, 1.
+func Normalize(currency string) (code string, scale float64, err error) {
+	c := strings.TrimSpace(currency)
+	if major, ok := minorUnits[c]; ok {
+		return major, 0.01, nil
+	}
+	if c == "" {
+		return "", 0, fmt.Errorf("currency is required")
+	}
+	upper := strings.ToUpper(c)
+	if code, ok := names[upper]; ok {
+		return code, 1, nil
+	}
+	if len(upper) != 3 || !isLetters(upper) {
+		return "", 0, fmt.Errorf("unknown currency %q", currency)
+	}
+	return upper, 1, nil
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
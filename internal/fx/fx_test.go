@@ -0,0 +1,153 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"investor/internal/dataservice"
+)
+
+// fakeData quotes "XXX=X" pairs from a table and counts the calls. Only
+// GetMarketQuote is implemented.
+type fakeData struct {
+	dataservice.DataService
+	perUSD map[string]float64
+	quoted time.Time
+	calls  int
+	down   bool
+}
+
+func (f *fakeData) GetMarketQuote(ctx context.Context, symbol string) (*dataservice.MarketQuote, error) {
+	f.calls++
+	if f.down {
+		return nil, errors.New("provider down")
+	}
+	p, ok := f.perUSD[symbol]
+	if !ok {
+		return nil, errors.New("no such pair")
+	}
+	return &dataservice.MarketQuote{Symbol: symbol, Price: p, UpdatedAt: f.quoted.Format(time.RFC3339)}, nil
+}
+
+func newTestService() (*Service, *fakeData, *time.Time) {
+	data := &fakeData{
+		perUSD: map[string]float64{"CNY=X": 7.2, "HKD=X": 7.8, "JPY=X": 150, "GBP=X": 0.8},
+		quoted: time.Date(2026, 10, 16, 20, 55, 0, 0, time.UTC),
+	}
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	s := NewService(data)
+	s.now = func() time.Time { return now }
+	return s, data, &now
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		in    string
+		code  string
+		scale float64
+	}{
+		{"HKD", "HKD", 1},
+		{" usd ", "USD", 1},
+		{"港币", "HKD", 1},
+		{"usdt", "USD", 1},
+		{"GBp", "GBP", 0.01},
+		{"GBX", "GBP", 0.01},
+		{"ZAc", "ZAR", 0.01},
+		{"GBP", "GBP", 1},
+		{"", "", 0},
+		{"dollars", "", 0},
+		{"U$D", "", 0},
+	}
+	for _, c := range cases {
+		code, scale, err := Normalize(c.in)
+		if code != c.code || scale != c.scale || (err != nil) != (c.code == "") {
+			t.Errorf("Normalize(%q) = %s, %v, %v; want %s, %v", c.in, code, scale, err, c.code, c.scale)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	s, _, _ := newTestService()
+	ctx := context.Background()
+	cases := []struct {
+		amount   float64
+		from, to string
+		wantFrom string
+		wantTo   string
+		rate     float64
+	}{
+		{1000, "港币", "人民币", "HKD", "CNY", 7.2 / 7.8}, // triangulated through USD
+		{100, "USD", "JPY", "USD", "JPY", 150},
+		{100, "CNY", "USD", "CNY", "USD", 1 / 7.2},
+		{1, "HKD", "HKD", "HKD", "HKD", 1},
+		{250, "GBp", "USD", "GBp", "USD", 0.01 / 0.8}, // pence keep their unit
+		{1, "USD", "GBX", "USD", "GBX", 0.8 / 0.01},
+	}
+	for _, c := range cases {
+		conv, err := s.Convert(ctx, c.amount, c.from, c.to)
+		if err != nil {
+			t.Errorf("%s->%s: %v", c.from, c.to, err)
+			continue
+		}
+		if conv.From != c.wantFrom || conv.To != c.wantTo || !near(conv.Rate, c.rate) || !near(conv.Result, c.amount*c.rate) {
+			t.Errorf("%s->%s = %+v, want %s->%s at %v", c.from, c.to, conv, c.wantFrom, c.wantTo, c.rate)
+		}
+	}
+
+	if _, err := s.Convert(ctx, 1, "USD", "XYZ"); err == nil {
+		t.Error("XYZ has no quote")
+	}
+	if _, err := s.Convert(ctx, 1, "dollars", "USD"); err == nil {
+		t.Error("unknown currency name")
+	}
+}
+
+func TestAsOfIsQuoteTime(t *testing.T) {
+	s, data, _ := newTestService()
+	conv, err := s.Convert(context.Background(), 1, "HKD", "CNY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !conv.AsOf.Equal(data.quoted) {
+		t.Errorf("AsOf = %v, want the quote time %v", conv.AsOf, data.quoted)
+	}
+}
+
+func TestTTLAndStaleFallback(t *testing.T) {
+	s, data, now := newTestService()
+	ctx := context.Background()
+
+	s.Rate(ctx, "USD", "HKD")
+	s.Rate(ctx, "HKD", "USD")
+	if data.calls != 1 {
+		t.Errorf("within the TTL: %d downloads, want 1", data.calls)
+	}
+
+	*now = now.Add(s.TTL + time.Second)
+	data.perUSD["HKD=X"] = 7.75
+	data.quoted = now.Add(-time.Minute)
+	if rate, _ := s.Rate(ctx, "USD", "HKD"); rate != 7.75 || data.calls != 2 {
+		t.Errorf("after the TTL: rate %v after %d downloads, want 7.75 after 2", rate, data.calls)
+	}
+
+	// The provider fails: the last rate is served, dated by its quote
+	*now = now.Add(s.TTL + time.Second)
+	data.down = true
+	conv, err := s.Convert(ctx, 1, "USD", "HKD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.Rate != 7.75 || !conv.AsOf.Equal(data.quoted) {
+		t.Errorf("stale fallback = %+v", conv)
+	}
+	if _, err := s.Rate(ctx, "USD", "JPY"); err == nil {
+		t.Error("a rate never fetched cannot fall back")
+	}
+}
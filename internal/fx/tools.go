@@ -0,0 +1,121 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+
+	"investor/internal/dataservice"
+	"investor/internal/render"
+	"investor/internal/tools"
+)
+
+// RegisterTools exposes currency conversion to the LLM, and adds a "currency"
+// option to the quote tools already in r for showing prices in a second currency
+func RegisterTools(r *tools.Registry, svc *Service) {
+	r.Register(tools.Tool{
+		Name:        "convert_currency",
+		Description: "货币换算：按最新汇率把金额从一种货币换算为另一种 (经美元交叉换算)，如 1000 港币是多少人民币、100 USD to JPY",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"amount": map[string]interface{}{
+					"type":        "number",
+					"description": "金额，默认 1 (即返回汇率)",
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "源货币，ISO 代码或中文名，如 HKD、港币、USDT",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "目标货币，如 CNY、人民币",
+				},
+			},
+			"required": []string{"from", "to"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Amount float64 `json:"amount"`
+				From   string  `json:"from"`
+				To     string  `json:"to"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.Amount == 0 {
+				args.Amount = 1
+			}
+			return svc.Convert(ctx, args.Amount, args.From, args.To)
+		},
+	})
+
+	if t, ok := r.Get("get_market_quote"); ok {
+		r.Register(withCurrency(t, func(ctx context.Context, result interface{}, to string) (interface{}, error) {
+			q, ok := result.(*dataservice.MarketQuote)
+			if !ok {
+				return result, nil
+			}
+			if err := dataservice.ConvertQuote(ctx, svc, q, to); err != nil {
+				return map[string]interface{}{"quote": q, "fx_error": err.Error()}, nil
+			}
+			return q, nil
+		}))
+	}
+	if t, ok := r.Get("get_market_quotes"); ok {
+		r.Register(withCurrency(t, func(ctx context.Context, result interface{}, to string) (interface{}, error) {
+			m, ok := result.(map[string]interface{})
+			if !ok {
+				return result, nil
+			}
+			results, _ := m["quotes"].([]dataservice.QuoteResult)
+			for _, qr := range results {
+				if qr.Quote != nil {
+					dataservice.ConvertQuote(ctx, svc, qr.Quote, to) // unconverted rows show "-"
+				}
+			}
+			m["table"] = render.MarkdownRenderer.Render(dataservice.QuotesDocument(results)) // ready-to-show card
+			return m, nil
+		}))
+	}
+}
+
+// withCurrency wraps a quote tool with an optional "currency" argument: the
+// tool runs as before, then convert restates its result in that currency
+func withCurrency(t tools.Tool, convert func(ctx context.Context, result interface{}, to string) (interface{}, error)) tools.Tool {
+	props := map[string]interface{}{}
+	if old, ok := t.Parameters["properties"].(map[string]interface{}); ok {
+		for k, v := range old {
+			props[k] = v
+		}
+	}
+	props["currency"] = map[string]interface{}{
+		"type":        "string",
+		"description": "可选，同时折算显示为该货币，如 USD、人民币 (用户说“用美元显示”时传入)",
+	}
+	params := map[string]interface{}{}
+	for k, v := range t.Parameters {
+		params[k] = v
+	}
+	params["properties"] = props
+
+	inner := t.Handler
+	t.Parameters = params
+	t.Handler = func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		var args struct {
+			Currency string `json:"currency"`
+		}
+		if err := tools.Decode(raw, &args); err != nil {
+			return nil, err
+		}
+		result, err := inner(ctx, raw)
+		if err != nil || args.Currency == "" {
+			return result, err
+		}
+		to, _, err := Normalize(args.Currency)
+		if err != nil {
+			return nil, err
+		}
+		return convert(ctx, result, to)
+	}
+	return t
+}
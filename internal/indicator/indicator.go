@@ -0,0 +1,158 @@
+package indicator
+
+import "math"
+
+// SMA returns the simple moving average of the last period values (0 if not enough data)
+func SMA(data []float64, period int) float64 {
+	if period <= 0 || len(data) < period {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range data[len(data)-period:] {
+		sum += v
+	}
+	return sum / float64(period)
+}
+
+// SMASeries returns the SMA at every index; values before the warm-up are 0
+func SMASeries(data []float64, period int) []float64 {
+	out := make([]float64, len(data))
+	if period <= 0 {
+		return out
+	}
+	sum := 0.0
+	for i, v := range data {
+		sum += v
+		if i >= period {
+			sum -= data[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// EMASeries returns the exponential moving average seeded with the first SMA; values before the warm-up are 0
+func EMASeries(data []float64, period int) []float64 {
+	out := make([]float64, len(data))
+	if period <= 0 || len(data) < period {
+		return out
+	}
+	k := 2 / float64(period+1)
+	out[period-1] = SMA(data[:period], period)
+	for i := period; i < len(data); i++ {
+		out[i] = data[i]*k + out[i-1]*(1-k)
+	}
+	return out
+}
+
+// RSI returns the relative strength index over the last period changes
+// (simple-average variant). Returns 50 when there is not enough data.
+func RSI(data []float64, period int) float64 {
+	if period <= 0 || len(data) < period+1 {
+		return 50 // default
+	}
+
+	gains := 0.0
+	losses := 0.0
+	for i := len(data) - period; i < len(data); i++ {
+		change := data[i] - data[i-1]
+		if change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+
+	if losses == 0 {
+		return 100
+	}
+	rs := gains / losses
+	return 100 - (100 / (1 + rs))
+}
+
+// RSISeries returns RSI at every index; values before the warm-up are 50
+func RSISeries(data []float64, period int) []float64 {
+	out := make([]float64, len(data))
+	for i := range data {
+		out[i] = RSI(data[:i+1], period)
+	}
+	return out
+}
+
+// SupportResistance returns the lowest and highest value of the last lookback points
+func SupportResistance(data []float64, lookback int) (support, resistance float64) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	if lookback <= 0 || lookback > len(data) {
+		lookback = len(data)
+	}
+	support, resistance = math.Inf(1), math.Inf(-1)
+	for _, v := range data[len(data)-lookback:] {
+		support = math.Min(support, v)
+		resistance = math.Max(resistance, v)
+	}
+	return support, resistance
+}
+
+// VolumeRatio compares the last volume with the average of the previous n (0 if not enough data)
+func VolumeRatio(volumes []float64, n int) float64 {
+	if n <= 0 || len(volumes) < n+1 {
+		return 0
+	}
+	avg := SMA(volumes[:len(volumes)-1], n)
+	if avg == 0 {
+		return 0
+	}
+	return volumes[len(volumes)-1] / avg
+}
+
+// Returns converts prices to simple period returns (len-1 values)
+func Returns(data []float64) []float64 {
+	if len(data) < 2 {
+		return nil
+	}
+	out := make([]float64, 0, len(data)-1)
+	for i := 1; i < len(data); i++ {
+		if data[i-1] == 0 {
+			out = append(out, 0)
+			continue
+		}
+		out = append(out, data[i]/data[i-1]-1)
+	}
+	return out
+}
+
+// MeanStd returns the mean and sample standard deviation
+func MeanStd(data []float64) (mean, std float64) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	for _, v := range data {
+		mean += v
+	}
+	mean /= float64(len(data))
+	if len(data) < 2 {
+		return mean, 0
+	}
+	for _, v := range data {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(data)-1))
+}
+
+// MaxDrawdown returns the largest peak-to-trough decline of a value series, as a positive fraction
+func MaxDrawdown(data []float64) float64 {
+	peak, maxDD := 0.0, 0.0
+	for _, v := range data {
+		if v > peak {
+			peak = v
+		}
+		if peak > 0 {
+			maxDD = math.Max(maxDD, (peak-v)/peak)
+		}
+	}
+	return maxDD
+}
@@ -0,0 +1,124 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func nearAll(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !near(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMovingAverages(t *testing.T) {
+	data := []float64{10, 11, 12, 13, 14}
+	if got := SMA(data, 3); !near(got, 13) {
+		t.Errorf("SMA(3) = %v, want 13", got)
+	}
+	if got := SMA(data, 6); got != 0 {
+		t.Errorf("SMA past the data = %v, want 0", got)
+	}
+	if got := SMASeries(data, 3); !nearAll(got, []float64{0, 0, 11, 12, 13}) {
+		t.Errorf("SMASeries(3) = %v", got)
+	}
+	// k = 2/(3+1) = 0.5, seeded with SMA(10, 11, 12) = 11
+	if got := EMASeries(data, 3); !nearAll(got, []float64{0, 0, 11, 12, 13}) {
+		t.Errorf("EMASeries(3) = %v", got)
+	}
+	if got := EMASeries([]float64{10, 11, 12, 20}, 3); !near(got[3], 20*0.5+11*0.5) {
+		t.Errorf("EMASeries(3) after a jump = %v, want 15.5", got[3])
+	}
+}
+
+func TestRSI(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   []float64
+		period int
+		want   float64
+	}{
+		// changes +1, -0.5, +1, +0.5: RS = 2.5/0.5 = 5, RSI = 100 - 100/6
+		{"mixed", []float64{10, 11, 10.5, 11.5, 12}, 4, 100 - 100.0/6},
+		// only the last 2 changes (-0.5, +1) count: RS = 2, RSI = 100 - 100/3
+		{"window", []float64{10, 11, 10.5, 11.5}, 2, 100 - 100.0/3},
+		{"only gains", []float64{1, 2, 3}, 2, 100},
+		{"only losses", []float64{3, 2, 1}, 2, 0},
+		{"flat", []float64{5, 5, 5}, 2, 100},
+		{"not enough data", []float64{1, 2}, 2, 50},
+	}
+	for _, c := range cases {
+		if got := RSI(c.data, c.period); !near(got, c.want) {
+			t.Errorf("%s: RSI = %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	series := RSISeries([]float64{10, 11, 10.5, 11.5}, 2)
+	if !nearAll(series, []float64{50, 50, 100 - 100.0/3, 100 - 100.0/3}) {
+		t.Errorf("RSISeries = %v", series)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	cases := []struct {
+		name string
+		data []float64
+		want float64
+	}{
+		// the deepest fall runs from the 1.2 peak to 0.6, not from the later 1.1
+		{"two dips", []float64{1, 1.2, 0.9, 1.1, 0.6, 1.3}, 0.5},
+		{"rising", []float64{1, 2, 3}, 0},
+		{"falling", []float64{4, 3, 1}, 0.75},
+		{"empty", nil, 0},
+	}
+	for _, c := range cases {
+		if got := MaxDrawdown(c.data); !near(got, c.want) {
+			t.Errorf("%s: MaxDrawdown = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReturnsMeanStd(t *testing.T) {
+	if got := Returns([]float64{100, 110, 99, 0, 5}); !nearAll(got, []float64{0.1, -0.1, -1, 0}) {
+		t.Errorf("Returns = %v", got)
+	}
+	if got := Returns([]float64{1}); got != nil {
+		t.Errorf("Returns of one value = %v, want nil", got)
+	}
+
+	// squared deviations from 5 sum to 32, sample variance 32/7
+	mean, std := MeanStd([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	if !near(mean, 5) || !near(std, math.Sqrt(32.0/7)) {
+		t.Errorf("MeanStd = %v, %v", mean, std)
+	}
+	if mean, std := MeanStd([]float64{3}); mean != 3 || std != 0 {
+		t.Errorf("MeanStd of one value = %v, %v", mean, std)
+	}
+}
+
+func TestLevelsAndVolume(t *testing.T) {
+	data := []float64{5, 9, 7, 6, 8}
+	if s, r := SupportResistance(data, 3); s != 6 || r != 8 {
+		t.Errorf("SupportResistance(3) = %v, %v; want 6, 8", s, r)
+	}
+	if s, r := SupportResistance(data, 0); s != 5 || r != 9 {
+		t.Errorf("SupportResistance(all) = %v, %v; want 5, 9", s, r)
+	}
+	// last volume 300 against the average of the previous 2 (100, 200)
+	if got := VolumeRatio([]float64{900, 100, 200, 300}, 2); !near(got, 2) {
+		t.Errorf("VolumeRatio = %v, want 2", got)
+	}
+	if got := VolumeRatio([]float64{100, 200}, 2); got != 0 {
+		t.Errorf("VolumeRatio without enough data = %v, want 0", got)
+	}
+}
@@ -0,0 +1,47 @@
+package journal
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes mounts the journal REST endpoints:
+//
+//	GET /journal/stats?symbol=&model=&prompt_version=&action=
+//	GET /journal/entries?symbol=&model=&prompt_version=&action=&limit=50
+func RegisterRoutes(r gin.IRouter, m *Manager) {
+	r.GET("/journal/stats", func(c *gin.Context) {
+		var f Filter
+		if err := c.ShouldBindQuery(&f); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		stats, err := m.Stats(c.Request.Context(), f)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	})
+
+	r.GET("/journal/entries", func(c *gin.Context) {
+		var f Filter
+		if err := c.ShouldBindQuery(&f); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		entries, err := m.List(c.Request.Context(), f, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"entries": entries})
+	})
+}
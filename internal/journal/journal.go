@@ -0,0 +1,323 @@
+package journal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/store"
+
+	"go.uber.org/zap"
+)
+
+// Horizons are the evaluation windows, in trading days (bars) after the signal
+var Horizons = []struct {
+	Name string
+	Bars int
+}{
+	{"1d", 1},
+	{"5d", 5},
+	{"20d", 20},
+}
+
+// WaitBand is the largest move (in %) over which a WAIT call still counts as correct
+const WaitBand = 3.0
+
+// Entry is one recorded BUY/SELL/WAIT call
+type Entry struct {
+	ID            string    `json:"id"`
+	Symbol        string    `json:"symbol"`
+	Action        string    `json:"action"` // BUY, SELL, WAIT
+	Confidence    int       `json:"confidence,omitempty"`
+	Price         float64   `json:"price"` // market price when the call was made
+	Entry         float64   `json:"entry,omitempty"`
+	StopLoss      float64   `json:"stop_loss,omitempty"`
+	TakeProfit    float64   `json:"take_profit,omitempty"`
+	Model         string    `json:"model,omitempty"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Platform      string    `json:"platform,omitempty"`
+	ChatID        string    `json:"chat_id,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	Outcomes map[string]*Outcome `json:"outcomes,omitempty"` // by horizon name
+}
+
+// Outcome is how a call looked after a horizon
+type Outcome struct {
+	Date        string    `json:"date"` // bar the horizon closed on
+	Price       float64   `json:"price"`
+	ReturnPct   float64   `json:"return_pct"` // price change since the call
+	Hit         bool      `json:"hit"`
+	HitStop     bool      `json:"hit_stop,omitempty"`   // stop loss touched (on closes) within the horizon
+	HitTarget   bool      `json:"hit_target,omitempty"` // take profit touched within the horizon
+	EvaluatedAt time.Time `json:"evaluated_at"`
+}
+
+// Done reports whether every horizon has been evaluated
+func (e *Entry) Done() bool {
+	return len(e.Outcomes) == len(Horizons)
+}
+
+const keyPrefix = "journal:"
+
+// Manager stores signal calls and scores them as bars come in
+type Manager struct {
+	Store    store.Store
+	Data     dataservice.DataService
+	Logger   *zap.Logger
+	Interval time.Duration
+	Model    string // LLM model name stamped on new entries
+
+	mu sync.Mutex
+}
+
+func NewManager(st store.Store, data dataservice.DataService, logger *zap.Logger, model string, interval time.Duration) *Manager {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Manager{
+		Store:    st,
+		Data:     data,
+		Logger:   logger,
+		Interval: interval,
+		Model:    model,
+	}
+}
+
+// Record validates and stores a call. The price is fetched if missing.
+func (m *Manager) Record(ctx context.Context, e *Entry) (*Entry, error) {
+	e.Action = strings.ToUpper(e.Action)
+	if e.Action != "BUY" && e.Action != "SELL" && e.Action != "WAIT" {
+		return nil, fmt.Errorf("invalid action %q", e.Action)
+	}
+	if e.Symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if e.Price <= 0 {
+		q, err := m.Data.GetMarketQuote(ctx, e.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("no price for %s: %w", e.Symbol, err)
+		}
+		e.Price = q.Price
+	}
+	if e.Model == "" {
+		e.Model = m.Model
+	}
+
+	e.ID = newID()
+	e.CreatedAt = time.Now()
+	e.Outcomes = make(map[string]*Outcome)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return e, m.Store.Put(ctx, keyPrefix+e.ID, e)
+}
+
+// Filter selects entries; empty fields match everything
+type Filter struct {
+	Symbol        string `form:"symbol" json:"symbol,omitempty"`
+	Model         string `form:"model" json:"model,omitempty"`
+	PromptVersion string `form:"prompt_version" json:"prompt_version,omitempty"`
+	Action        string `form:"action" json:"action,omitempty"`
+}
+
+func (f Filter) match(e *Entry) bool {
+	return (f.Symbol == "" || strings.EqualFold(f.Symbol, e.Symbol)) &&
+		(f.Model == "" || f.Model == e.Model) &&
+		(f.PromptVersion == "" || f.PromptVersion == e.PromptVersion) &&
+		(f.Action == "" || strings.EqualFold(f.Action, e.Action))
+}
+
+// List returns matching entries, newest first
+func (m *Manager) List(ctx context.Context, f Filter, limit int) ([]*Entry, error) {
+	all, err := m.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Entry
+	for i := len(all) - 1; i >= 0; i-- {
+		if f.match(all[i]) {
+			out = append(out, all[i])
+		}
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// all returns every entry, oldest first
+func (m *Manager) all(ctx context.Context) ([]*Entry, error) {
+	keys, err := m.Store.Keys(ctx, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, k := range keys {
+		var e Entry
+		if ok, err := m.Store.Get(ctx, k, &e); err == nil && ok {
+			entries = append(entries, &e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+// Run evaluates pending entries every Interval until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	m.Logger.Info("Signal journal evaluator started", zap.Duration("interval", m.Interval))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Evaluate(ctx); err != nil {
+				m.Logger.Error("Signal evaluation failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Evaluate scores every pending horizon that has enough bars, fetching each symbol once
+func (m *Manager) Evaluate(ctx context.Context) error {
+	entries, err := m.all(ctx)
+	if err != nil {
+		return err
+	}
+
+	bySymbol := make(map[string][]*Entry)
+	for _, e := range entries {
+		if !e.Done() {
+			bySymbol[e.Symbol] = append(bySymbol[e.Symbol], e)
+		}
+	}
+
+	for symbol, list := range bySymbol {
+		bars, err := m.Data.GetHistoricalQuotes(ctx, symbol, "1d", rangeFor(list))
+		if err != nil {
+			m.Logger.Warn("Journal bars unavailable", zap.String("symbol", symbol), zap.Error(err))
+			continue
+		}
+		for _, e := range list {
+			if !score(e, bars, time.Now()) {
+				continue
+			}
+			m.mu.Lock()
+			if err := m.Store.Put(ctx, keyPrefix+e.ID, e); err != nil {
+				m.Logger.Error("Failed to save journal entry", zap.String("id", e.ID), zap.Error(err))
+			}
+			m.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// rangeFor picks a history range long enough to cover the oldest pending entry
+func rangeFor(list []*Entry) string {
+	oldest := time.Now()
+	for _, e := range list {
+		if e.CreatedAt.Before(oldest) {
+			oldest = e.CreatedAt
+		}
+	}
+	switch age := time.Since(oldest); {
+	case age < 80*24*time.Hour:
+		return "3mo"
+	case age < 170*24*time.Hour:
+		return "6mo"
+	case age < 350*24*time.Hour:
+		return "1y"
+	}
+	return "5y"
+}
+
+// score fills in the horizons that have closed. Bars dated on the signal day are
+// skipped because the call was made intraday. Returns whether anything changed.
+func score(e *Entry, bars []dataservice.KLineItem, now time.Time) bool {
+	day := e.CreatedAt.Format("2006-01-02")
+	start := len(bars)
+	for i, b := range bars {
+		if b.Date > day {
+			start = i
+			break
+		}
+	}
+	after := bars[start:]
+	// The last bar may still be forming during the session
+	if len(after) > 0 && after[len(after)-1].Date == now.Format("2006-01-02") {
+		after = after[:len(after)-1]
+	}
+
+	changed := false
+	for _, h := range Horizons {
+		if e.Outcomes[h.Name] != nil || len(after) < h.Bars {
+			continue
+		}
+		window := after[:h.Bars]
+		last := window[len(window)-1]
+
+		o := &Outcome{
+			Date:        last.Date,
+			Price:       last.Close,
+			ReturnPct:   math.Round((last.Close/e.Price-1)*10000) / 100,
+			EvaluatedAt: now,
+		}
+		for _, b := range window {
+			if touched(e.Action, b.Close, e.StopLoss, true) {
+				o.HitStop = true
+			}
+			if touched(e.Action, b.Close, e.TakeProfit, false) {
+				o.HitTarget = true
+			}
+		}
+		o.Hit = hit(e.Action, o.ReturnPct)
+
+		if e.Outcomes == nil {
+			e.Outcomes = make(map[string]*Outcome)
+		}
+		e.Outcomes[h.Name] = o
+		changed = true
+	}
+	return changed
+}
+
+// hit decides whether the call was right: BUY needs a gain, SELL a loss,
+// WAIT a move within WaitBand
+func hit(action string, ret float64) bool {
+	switch action {
+	case "BUY":
+		return ret > 0
+	case "SELL":
+		return ret < 0
+	}
+	return math.Abs(ret) <= WaitBand
+}
+
+// touched reports whether a close reached the stop (or target) level for the call's direction
+func touched(action string, price, level float64, stop bool) bool {
+	if level <= 0 || action == "WAIT" {
+		return false
+	}
+	long := action == "BUY"
+	if stop == long {
+		return price <= level
+	}
+	return price >= level
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
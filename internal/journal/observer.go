@@ -0,0 +1,117 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"investor/internal/agent"
+	"investor/internal/model"
+	"investor/internal/symbols"
+
+	"go.uber.org/zap"
+)
+
+// signalTools are the tools whose symbol a Signal-mode answer is about, in order of preference
+var signalTools = []string{"get_security_analysis", "get_market_quote"}
+
+// Observe is an agent.Observer that records every Signal-mode reply carrying
+// a BUY/SELL/WAIT call. The symbol is the one the Signal line names; its
+// price comes from the tool calls behind the answer when they fetched it.
+func (m *Manager) Observe(ctx context.Context, msg *model.InternalMessage, reply *agent.Reply) {
+	if reply.Signal == nil || reply.Intent.Level != agent.IntentSignal {
+		return
+	}
+	symbol, price := subject(reply.Signal.Symbol, reply.ToolCalls)
+	if symbol == "" {
+		m.Logger.Debug("Signal without a symbol, not journaled")
+		return
+	}
+
+	e := &Entry{
+		Symbol:        symbol,
+		Action:        reply.Signal.Action,
+		Confidence:    reply.Signal.Confidence,
+		Price:         price,
+		Entry:         reply.Signal.Entry,
+		StopLoss:      reply.Signal.StopLoss,
+		TakeProfit:    reply.Signal.TakeProfit,
+		PromptVersion: agent.PromptVersion,
+		Platform:      msg.Platform,
+		ChatID:        msg.ChatID,
+		UserID:        msg.UserID,
+	}
+
+	// Recording may need a quote; don't hold up the reply
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if _, err := m.Record(ctx, e); err != nil {
+			m.Logger.Warn("Failed to journal signal", zap.String("symbol", symbol), zap.Error(err))
+		}
+	}()
+}
+
+// looked is a symbol a tool call fetched: as asked, as returned, and its price
+type looked struct {
+	asked, symbol string
+	price         float64
+}
+
+// subject finds the symbol (and its price, if a tool returned one) a signal
+// refers to. The symbol named on the Signal line wins when it matches a tool
+// call that fetched it or the instrument master knows it; anything else is
+// not a symbol and the signal is skipped. Without a named symbol, the answer
+// must be about a single symbol.
+func subject(named string, calls []agent.ToolCallRecord) (string, float64) {
+	var seen []looked
+	for _, name := range signalTools {
+		for _, tc := range calls {
+			if tc.Name != name || tc.Error != "" {
+				continue
+			}
+			var args struct {
+				Symbol string `json:"symbol"`
+			}
+			var result struct {
+				Symbol       string  `json:"symbol"`
+				CurrentPrice float64 `json:"current_price"`
+				Price        float64 `json:"price"`
+			}
+			json.Unmarshal(tc.Arguments, &args)
+			json.Unmarshal(tc.Result, &result)
+			l := looked{asked: args.Symbol, symbol: result.Symbol, price: result.CurrentPrice}
+			if l.symbol == "" {
+				l.symbol = l.asked
+			}
+			if l.price == 0 {
+				l.price = result.Price
+			}
+			if l.symbol != "" {
+				seen = append(seen, l)
+			}
+		}
+	}
+
+	if named != "" {
+		for _, l := range seen {
+			if strings.EqualFold(named, l.asked) || strings.EqualFold(named, l.symbol) {
+				return l.symbol, l.price
+			}
+		}
+		if res := symbols.Default().Resolve(named); res.Best != nil && !res.Ambiguous {
+			return res.Best.Instrument.Code, 0
+		}
+		return "", 0
+	}
+	if len(seen) == 0 {
+		return "", 0
+	}
+	for _, l := range seen[1:] {
+		if !strings.EqualFold(l.symbol, seen[0].symbol) {
+			return "", 0 // several symbols and the signal does not say which
+		}
+	}
+	return seen[0].symbol, seen[0].price
+}
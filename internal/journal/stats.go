@@ -0,0 +1,127 @@
+package journal
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// HitStats aggregates the outcomes of one horizon
+type HitStats struct {
+	Evaluated  int     `json:"evaluated"`
+	Hits       int     `json:"hits"`
+	HitRate    float64 `json:"hit_rate"`   // %
+	AvgReturn  float64 `json:"avg_return"` // % price change since the call (sign not adjusted for SELL)
+	StopRate   float64 `json:"stop_rate"`  // % of evaluated calls whose stop was touched
+	TargetRate float64 `json:"target_rate"`
+}
+
+// Group is the statistics of a slice of calls
+type Group struct {
+	Key      string               `json:"key"`
+	Calls    int                  `json:"calls"`
+	Actions  map[string]int       `json:"actions"`
+	Horizons map[string]*HitStats `json:"horizons"`
+}
+
+// Stats is the journal summary: overall, per symbol and per model/prompt version
+type Stats struct {
+	Filter   Filter   `json:"filter"`
+	Overall  *Group   `json:"overall"`
+	BySymbol []*Group `json:"by_symbol"`
+	ByModel  []*Group `json:"by_model"` // key is "model@prompt_version"
+}
+
+// Stats computes hit rates for the entries matching the filter
+func (m *Manager) Stats(ctx context.Context, f Filter) (*Stats, error) {
+	entries, err := m.List(ctx, f, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stats{Filter: f, Overall: newGroup("all")}
+	symbols := make(map[string]*Group)
+	models := make(map[string]*Group)
+	for _, e := range entries {
+		s.Overall.add(e)
+		groupFor(symbols, e.Symbol).add(e)
+		groupFor(models, e.Model+"@"+e.PromptVersion).add(e)
+	}
+
+	s.Overall.finish()
+	s.BySymbol = sortedGroups(symbols)
+	s.ByModel = sortedGroups(models)
+	return s, nil
+}
+
+func newGroup(key string) *Group {
+	g := &Group{Key: key, Actions: make(map[string]int), Horizons: make(map[string]*HitStats)}
+	for _, h := range Horizons {
+		g.Horizons[h.Name] = &HitStats{}
+	}
+	return g
+}
+
+func groupFor(groups map[string]*Group, key string) *Group {
+	g, ok := groups[key]
+	if !ok {
+		g = newGroup(key)
+		groups[key] = g
+	}
+	return g
+}
+
+func (g *Group) add(e *Entry) {
+	g.Calls++
+	g.Actions[e.Action]++
+	for name, o := range e.Outcomes {
+		hs := g.Horizons[name]
+		if hs == nil {
+			continue
+		}
+		hs.Evaluated++
+		if o.Hit {
+			hs.Hits++
+		}
+		// Running sums, turned into rates in finish
+		hs.AvgReturn += o.ReturnPct
+		if e.StopLoss > 0 && o.HitStop {
+			hs.StopRate++
+		}
+		if e.TakeProfit > 0 && o.HitTarget {
+			hs.TargetRate++
+		}
+	}
+}
+
+func (g *Group) finish() {
+	for _, hs := range g.Horizons {
+		if hs.Evaluated == 0 {
+			continue
+		}
+		n := float64(hs.Evaluated)
+		hs.HitRate = round(float64(hs.Hits) / n * 100)
+		hs.AvgReturn = round(hs.AvgReturn / n)
+		hs.StopRate = round(hs.StopRate / n * 100)
+		hs.TargetRate = round(hs.TargetRate / n * 100)
+	}
+}
+
+func sortedGroups(groups map[string]*Group) []*Group {
+	out := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		g.finish()
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Calls != out[j].Calls {
+			return out[i].Calls > out[j].Calls
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,47 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+
+	"investor/internal/tools"
+)
+
+// RegisterTools exposes the signal track record to the LLM
+func RegisterTools(r *tools.Registry, m *Manager) {
+	r.Register(tools.Tool{
+		Name:        "get_signal_stats",
+		Description: "查询历史 BUY/SELL/WAIT 信号的事后表现：1日/5日/20日命中率、平均涨跌幅、止损/止盈触及率，可按标的筛选",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "标的代码 (可选)，如 'AAPL', 'BTCUSDT'",
+				},
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var f Filter
+			if err := tools.Decode(raw, &f); err != nil {
+				return nil, err
+			}
+			// Entries are stored under the quoted symbol ("BTC" -> "BTCUSDT")
+			if f.Symbol != "" {
+				if q, err := m.Data.GetMarketQuote(ctx, f.Symbol); err == nil && q.Symbol != "" {
+					f.Symbol = q.Symbol
+				}
+			}
+			stats, err := m.Stats(ctx, f)
+			if err != nil {
+				return nil, err
+			}
+			// Per-model breakdown is for operators (REST); keep the LLM payload small
+			stats.ByModel = nil
+			if len(stats.BySymbol) > 10 {
+				stats.BySymbol = stats.BySymbol[:10]
+			}
+			return stats, nil
+		},
+	})
+}
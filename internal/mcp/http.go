@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// HTTPHandler serves the Streamable HTTP transport on a single endpoint.
+// Every POST gets a plain JSON response (no server-initiated SSE stream),
+// which the spec allows for servers that never push.
+type HTTPHandler struct {
+	Server *Server
+	// AllowedOrigins are the browser origins (e.g. "https://agent.example.com")
+	// accepted besides localhost. Requests without an Origin header come from
+	// non-browser clients and are always accepted.
+	AllowedOrigins []string
+
+	mu       sync.Mutex
+	sessions map[string]bool
+}
+
+func NewHTTPHandler(s *Server) *HTTPHandler {
+	return &HTTPHandler{
+		Server:   s,
+		sessions: make(map[string]bool),
+	}
+}
+
+const sessionHeader = "Mcp-Session-Id"
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// A web page must not reach a local server through the user's browser (DNS rebinding)
+	if !h.originAllowed(r.Header.Get("Origin")) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		h.mu.Lock()
+		delete(h.sessions, r.Header.Get(sessionHeader))
+		h.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 4*1024*1024))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	var req request
+	isInit := false
+	if err := json.Unmarshal(body, &req); err == nil && req.Method == "initialize" {
+		isInit = true
+	}
+
+	sessionID := r.Header.Get(sessionHeader)
+	if isInit {
+		sessionID = newSessionID()
+		h.mu.Lock()
+		h.sessions[sessionID] = true
+		h.mu.Unlock()
+	} else if sessionID != "" {
+		h.mu.Lock()
+		known := h.sessions[sessionID]
+		h.mu.Unlock()
+		if !known {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+	}
+
+	out := h.Server.Handle(r.Context(), body)
+	if sessionID != "" {
+		w.Header().Set(sessionHeader, sessionID)
+	}
+	if out == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+func (h *HTTPHandler) originAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, o := range h.AllowedOrigins {
+		if strings.EqualFold(strings.TrimRight(o, "/"), origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"investor/internal/tools"
+)
+
+// Server exposes a tool registry over the Model Context Protocol (JSON-RPC 2.0).
+// Only the tools capability is implemented; transports live in stdio.go and http.go.
+type Server struct {
+	Name    string
+	Version string
+	Tools   *tools.Registry
+}
+
+func NewServer(name, version string, registry *tools.Registry) *Server {
+	return &Server{
+		Name:    name,
+		Version: version,
+		Tools:   registry,
+	}
+}
+
+// Protocol versions we can speak, newest first
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// isNotification reports whether the request expects no response
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0 || string(r.ID) == "null"
+}
+
+// Handle processes one JSON-RPC message (or batch) and returns the encoded response.
+// Returns nil when nothing must be sent back (notifications only).
+func (s *Server) Handle(ctx context.Context, data []byte) []byte {
+	trimmed := json.RawMessage(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return encode(errorResponse(nil, codeParseError, err.Error()))
+		}
+		var out []response
+		for _, item := range batch {
+			if resp := s.handleOne(ctx, item); resp != nil {
+				out = append(out, *resp)
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return encode(out)
+	}
+
+	resp := s.handleOne(ctx, data)
+	if resp == nil {
+		return nil
+	}
+	return encode(resp)
+}
+
+func (s *Server) handleOne(ctx context.Context, data []byte) *response {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, codeParseError, err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid JSON-RPC request")
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(ctx context.Context, req *request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		return map[string]interface{}{
+			"protocolVersion": negotiateVersion(params.ProtocolVersion),
+			"capabilities": map[string]interface{}{
+				"tools": map[string]interface{}{"listChanged": false},
+			},
+			"serverInfo": map[string]interface{}{
+				"name":    s.Name,
+				"version": s.Version,
+			},
+			"instructions": "Market data tools: quotes, technical analysis, historical bars, news, sentiment, indices and IPOs.",
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		var list []map[string]interface{}
+		for _, t := range s.Tools.List() {
+			list = append(list, map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"inputSchema": t.Parameters,
+			})
+		}
+		return map[string]interface{}{"tools": list}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "tools/call requires a tool name"}
+		}
+		if _, ok := s.Tools.Get(params.Name); !ok {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+		}
+		return s.callTool(ctx, params.Name, params.Arguments), nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+// callTool runs a tool; tool failures are reported in-band (isError) as the spec requires
+func (s *Server) callTool(ctx context.Context, name string, args json.RawMessage) map[string]interface{} {
+	result, err := s.Tools.Call(ctx, name, args)
+	if err != nil {
+		return map[string]interface{}{
+			"content": []map[string]interface{}{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}
+	}
+	data, _ := json.Marshal(result)
+	return map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": string(data)}},
+		"isError": false,
+	}
+}
+
+func negotiateVersion(requested string) string {
+	for _, v := range supportedVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return supportedVersions[0]
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
+
+func encode(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+)
+
+// ServeStdio runs the server over newline-delimited JSON-RPC on r/w (usually stdin/stdout).
+// Requests are handled concurrently; writes are serialized.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	defer wg.Wait()
+
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		if len(line) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out := s.Handle(ctx, line)
+			if out == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			w.Write(append(out, '\n'))
+		}()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return scanner.Err()
+}
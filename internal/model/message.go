@@ -9,6 +9,19 @@ type InternalMessage struct {
 	Text        string `json:"text"`         // Message Content
 	IsMentioned bool   `json:"is_mentioned"` // Is mentioned?
 	Timestamp   int64  `json:"timestamp"`
+	Format      string `json:"format,omitempty"` // Reply format override: "markdown", "feishu", "telegram", "slack", "plain"
+
+	// Stateless callers (e.g. OpenAI-compatible clients) keep the conversation
+	// themselves: no server-side session is read or written for the message,
+	// including pending disambiguation questions and remembered picks.
+	Stateless bool `json:"stateless,omitempty"`
+	// History is the prior conversation supplied by a stateless caller
+	History []HistoryMessage `json:"history,omitempty"`
+}
+
+type HistoryMessage struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
 }
 
 type ReplyMessage struct {
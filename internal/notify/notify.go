@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"investor/internal/render"
+)
+
+// Sender pushes a message to a chat proactively (not as a reply).
+// Implemented by adapters that can initiate conversations, e.g. feishu.Adapter.
+type Sender interface {
+	Send(ctx context.Context, chatID string, text string) error
+}
+
+// Hub routes proactive messages to the adapter of the originating platform
+type Hub struct {
+	mu      sync.RWMutex
+	senders map[string]Sender
+}
+
+func NewHub() *Hub {
+	return &Hub{senders: make(map[string]Sender)}
+}
+
+// Register adds the sender for a platform ("feishu", ...)
+func (h *Hub) Register(platform string, s Sender) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.senders[platform] = s
+}
+
+// CanSend reports whether a platform supports proactive messages
+func (h *Hub) CanSend(platform string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.senders[platform]
+	return ok
+}
+
+// Send renders the document for the platform and pushes it to the chat
+func (h *Hub) Send(ctx context.Context, platform, chatID string, doc *render.Document) error {
+	h.mu.RLock()
+	s, ok := h.senders[platform]
+	h.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("platform %s cannot push messages", platform)
+	}
+	return s.Send(ctx, chatID, render.ForPlatform(platform).Render(doc))
+}
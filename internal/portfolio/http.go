@@ -0,0 +1,93 @@
+package portfolio
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes mounts the portfolio REST endpoints. Each API key owns one
+// portfolio: principal returns the authenticated key of a request, and a
+// user_id that names anyone else is refused. Mount it only behind authentication.
+//
+//	GET    /portfolio[?base=CNY]
+//	POST   /portfolio/positions           {"symbol", "quantity", "cost_basis", "currency"}
+//	DELETE /portfolio/positions/:symbol[?quantity=5]
+func RegisterRoutes(r gin.IRouter, m *Manager, principal func(c *gin.Context) string) {
+	r.GET("/portfolio", func(c *gin.Context) {
+		owner, ok := ownerParam(c, principal(c), c.Query("user_id"), c.Query("platform"))
+		if !ok {
+			return
+		}
+		if base := c.Query("base"); base != "" {
+			if _, err := m.SetBase(c.Request.Context(), owner, base); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		report, err := m.Valuate(c.Request.Context(), owner)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	})
+
+	r.POST("/portfolio/positions", func(c *gin.Context) {
+		var req struct {
+			UserID   string `json:"user_id"`
+			Platform string `json:"platform"`
+			Position
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		owner, ok := ownerParam(c, principal(c), req.UserID, req.Platform)
+		if !ok {
+			return
+		}
+		pos, err := m.Buy(c.Request.Context(), owner, req.Position)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, pos)
+	})
+
+	r.DELETE("/portfolio/positions/:symbol", func(c *gin.Context) {
+		owner, ok := ownerParam(c, principal(c), c.Query("user_id"), c.Query("platform"))
+		if !ok {
+			return
+		}
+		var qty float64
+		if s := c.Query("quantity"); s != "" {
+			var err error
+			if qty, err = strconv.ParseFloat(s, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be a number"})
+				return
+			}
+		}
+		p, err := m.Sell(c.Request.Context(), owner, c.Param("symbol"), qty)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, p)
+	})
+}
+
+// ownerParam binds the request to the caller's own portfolio. user_id and
+// platform are optional; when given they must name the caller.
+func ownerParam(c *gin.Context, principal, userID, platform string) (Owner, bool) {
+	if principal == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return Owner{}, false
+	}
+	if (userID != "" && userID != principal) || (platform != "" && platform != "api") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "an API key can only access its own portfolio"})
+		return Owner{}, false
+	}
+	return Owner{Platform: "api", UserID: principal}, true
+}
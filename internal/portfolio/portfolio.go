@@ -0,0 +1,401 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/fx"
+	"investor/internal/store"
+)
+
+// DefaultBase is the reporting currency of a new portfolio
+const DefaultBase = "USD"
+
+// Concentration thresholds (share of total market value)
+const (
+	MaxPositionWeight = 0.25
+	MaxGroupWeight    = 0.60
+)
+
+// Position is a holding entered by the user
+type Position struct {
+	Symbol    string    `json:"symbol"`
+	Quantity  float64   `json:"quantity"`
+	CostBasis float64   `json:"cost_basis"` // average cost per unit, in Currency
+	Currency  string    `json:"currency"`
+	AssetType string    `json:"asset_type"` // stock, crypto, forex, commodity, index
+	Market    string    `json:"market"`     // cn, hk, us, crypto, global
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Portfolio is one user's positions
+type Portfolio struct {
+	BaseCurrency string      `json:"base_currency"`
+	Positions    []*Position `json:"positions"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// Owner identifies a portfolio (a user on a platform)
+type Owner struct {
+	Platform string
+	UserID   string
+}
+
+func (o Owner) key() string {
+	return fmt.Sprintf("portfolio:%s:%s", o.Platform, o.UserID)
+}
+
+// Manager persists portfolios and values them with live quotes
+type Manager struct {
+	Store store.Store
+	Data  dataservice.DataService
+	Rates RateProvider
+
+	mu sync.Mutex // serializes read-modify-write of a portfolio
+}
+
+func NewManager(st store.Store, data dataservice.DataService, rates RateProvider) *Manager {
+	if rates == nil {
+		rates = fx.NewService(data)
+	}
+	return &Manager{
+		Store: st,
+		Data:  data,
+		Rates: rates,
+	}
+}
+
+func (m *Manager) Get(ctx context.Context, owner Owner) (*Portfolio, error) {
+	p := &Portfolio{BaseCurrency: DefaultBase}
+	if _, err := m.Store.Get(ctx, owner.key(), p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Buy adds to a position, averaging the cost basis. Currency, asset type and
+// market are inferred from the symbol when empty.
+func (m *Manager) Buy(ctx context.Context, owner Owner, pos Position) (*Position, error) {
+	pos.Symbol = m.resolve(ctx, pos.Symbol)
+	if pos.Symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if pos.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	if pos.CostBasis < 0 {
+		return nil, fmt.Errorf("cost basis cannot be negative")
+	}
+	assetType, market, currency := Classify(pos.Symbol)
+	if pos.AssetType == "" {
+		pos.AssetType = assetType
+	}
+	if pos.Market == "" {
+		pos.Market = market
+	}
+	if pos.Currency == "" {
+		pos.Currency = currency
+	} else {
+		code, _, err := fx.Normalize(pos.Currency) // "港币" and "hkd" hold as HKD
+		if err != nil {
+			return nil, err
+		}
+		pos.Currency = code
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := p.find(pos.Symbol)
+	if existing == nil {
+		pos.UpdatedAt = time.Now()
+		existing = &pos
+		p.Positions = append(p.Positions, existing)
+	} else {
+		if existing.Currency != pos.Currency {
+			return nil, fmt.Errorf("%s is held in %s, cannot add in %s", pos.Symbol, existing.Currency, pos.Currency)
+		}
+		total := existing.Quantity + pos.Quantity
+		existing.CostBasis = (existing.CostBasis*existing.Quantity + pos.CostBasis*pos.Quantity) / total
+		existing.Quantity = total
+		existing.UpdatedAt = time.Now()
+	}
+	return existing, m.save(ctx, owner, p)
+}
+
+// Sell reduces a position; quantity 0 closes it
+func (m *Manager) Sell(ctx context.Context, owner Owner, symbol string, quantity float64) (*Portfolio, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	pos := p.find(symbol)
+	if pos == nil {
+		pos = p.find(m.resolve(ctx, symbol))
+	}
+	if pos == nil {
+		return nil, fmt.Errorf("no position in %s", symbol)
+	}
+	if quantity < 0 || quantity > pos.Quantity {
+		return nil, fmt.Errorf("can sell at most %g %s", pos.Quantity, symbol)
+	}
+
+	if quantity == 0 || quantity == pos.Quantity {
+		for i, x := range p.Positions {
+			if x == pos {
+				p.Positions = append(p.Positions[:i], p.Positions[i+1:]...)
+				break
+			}
+		}
+	} else {
+		pos.Quantity -= quantity
+		pos.UpdatedAt = time.Now()
+	}
+	return p, m.save(ctx, owner, p)
+}
+
+// SetBase changes the reporting currency
+func (m *Manager) SetBase(ctx context.Context, owner Owner, currency string) (*Portfolio, error) {
+	currency, _, err := fx.Normalize(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	p.BaseCurrency = currency
+	return p, m.save(ctx, owner, p)
+}
+
+// resolve maps names and aliases ("茅台", "btc") to the quoted symbol, so the
+// same holding entered two ways lands in one position
+func (m *Manager) resolve(ctx context.Context, symbol string) string {
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return ""
+	}
+	if q, err := m.Data.GetMarketQuote(ctx, symbol); err == nil && q.Symbol != "" {
+		return strings.ToUpper(q.Symbol)
+	}
+	return strings.ToUpper(symbol)
+}
+
+func (m *Manager) save(ctx context.Context, owner Owner, p *Portfolio) error {
+	p.UpdatedAt = time.Now()
+	return m.Store.Put(ctx, owner.key(), p)
+}
+
+func (p *Portfolio) find(symbol string) *Position {
+	for _, pos := range p.Positions {
+		if strings.EqualFold(pos.Symbol, symbol) {
+			return pos
+		}
+	}
+	return nil
+}
+
+// Holding is a valued position; money fields are in the report's base currency
+type Holding struct {
+	Position
+	Price        float64 `json:"price"` // in the position currency
+	Value        float64 `json:"value"`
+	Cost         float64 `json:"cost"`
+	PnL          float64 `json:"pnl"`
+	PnLPct       float64 `json:"pnl_pct"`
+	DayChange    float64 `json:"day_change"`
+	DayChangePct float64 `json:"day_change_pct"`
+	Weight       float64 `json:"weight"` // share of total value, 0-1
+	Error        string  `json:"error,omitempty"`
+}
+
+// Report is a valued portfolio with exposures and warnings
+type Report struct {
+	BaseCurrency string             `json:"base_currency"`
+	TotalValue   float64            `json:"total_value"`
+	TotalCost    float64            `json:"total_cost"`
+	PnL          float64            `json:"pnl"`
+	PnLPct       float64            `json:"pnl_pct"`
+	DayChange    float64            `json:"day_change"`
+	DayChangePct float64            `json:"day_change_pct"`
+	Holdings     []Holding          `json:"holdings"`
+	ByAssetType  map[string]float64 `json:"by_asset_type"` // weights, 0-1
+	ByMarket     map[string]float64 `json:"by_market"`
+	ByCurrency   map[string]float64 `json:"by_currency"`
+	Warnings     []string           `json:"warnings,omitempty"`
+	UpdatedAt    string             `json:"updated_at"`
+}
+
+// Valuate prices every position (4 at a time) and converts it to the base currency.
+// Positions whose quote or FX rate is unavailable are reported with an error and excluded from totals.
+func (m *Manager) Valuate(ctx context.Context, owner Owner) (*Report, error) {
+	p, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		BaseCurrency: p.BaseCurrency,
+		Holdings:     make([]Holding, len(p.Positions)),
+		ByAssetType:  make(map[string]float64),
+		ByMarket:     make(map[string]float64),
+		ByCurrency:   make(map[string]float64),
+		UpdatedAt:    time.Now().Format(time.RFC3339),
+	}
+
+	sem := make(chan struct{}, 4)
+	var wg sync.WaitGroup
+	for i, pos := range p.Positions {
+		wg.Add(1)
+		go func(i int, pos Position) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r.Holdings[i] = m.value(ctx, pos, p.BaseCurrency)
+		}(i, *pos)
+	}
+	wg.Wait()
+
+	var prevValue float64
+	for _, h := range r.Holdings {
+		if h.Error != "" {
+			continue
+		}
+		r.TotalValue += h.Value
+		r.TotalCost += h.Cost
+		r.DayChange += h.DayChange
+		prevValue += h.Value - h.DayChange
+	}
+	r.PnL = r.TotalValue - r.TotalCost
+	r.PnLPct = pct(r.PnL, r.TotalCost)
+	r.DayChangePct = pct(r.DayChange, prevValue)
+
+	for i := range r.Holdings {
+		h := &r.Holdings[i]
+		if h.Error != "" || r.TotalValue == 0 {
+			continue
+		}
+		h.Weight = h.Value / r.TotalValue
+		r.ByAssetType[h.AssetType] += h.Weight
+		r.ByMarket[h.Market] += h.Weight
+		r.ByCurrency[h.Currency] += h.Weight
+	}
+	sort.SliceStable(r.Holdings, func(i, j int) bool { return r.Holdings[i].Value > r.Holdings[j].Value })
+	r.Warnings = warnings(r)
+	return r, nil
+}
+
+func (m *Manager) value(ctx context.Context, pos Position, base string) Holding {
+	h := Holding{Position: pos}
+	q, err := m.Data.GetMarketQuote(ctx, pos.Symbol)
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+	rate, err := m.Rates.Rate(ctx, pos.Currency, base)
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+
+	h.Price = q.Price
+	h.Value = pos.Quantity * q.Price * rate
+	h.Cost = pos.Quantity * pos.CostBasis * rate
+	h.PnL = h.Value - h.Cost
+	h.PnLPct = pct(h.PnL, h.Cost)
+	h.DayChange = pos.Quantity * q.Change * rate
+	h.DayChangePct = q.ChangePct
+	return h
+}
+
+func warnings(r *Report) []string {
+	var out []string
+	for _, h := range r.Holdings {
+		if h.Weight > MaxPositionWeight {
+			out = append(out, fmt.Sprintf("%s 占组合 %.0f%%，超过单一持仓 %.0f%% 上限", h.Symbol, h.Weight*100, MaxPositionWeight*100))
+		}
+	}
+	groups := []struct {
+		label   string
+		weights map[string]float64
+	}{
+		{"市场", r.ByMarket},
+		{"币种", r.ByCurrency},
+	}
+	for _, g := range groups {
+		for _, name := range sortedKeys(g.weights) {
+			// A single-holding portfolio is already flagged above
+			if w := g.weights[name]; w > MaxGroupWeight && len(r.Holdings) > 1 {
+				out = append(out, fmt.Sprintf("%s %s 占 %.0f%%，集中度偏高", g.label, name, w*100))
+			}
+		}
+	}
+	for _, h := range r.Holdings {
+		if h.Error != "" {
+			out = append(out, fmt.Sprintf("%s 行情不可用，未计入总值", h.Symbol))
+		}
+	}
+	return out
+}
+
+func pct(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(part/whole*10000) / 100
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Classify infers asset type, market and quote currency from a (normalized) symbol
+func Classify(symbol string) (assetType, market, currency string) {
+	s := strings.ToUpper(symbol)
+	switch {
+	case strings.HasSuffix(s, ".SS"), strings.HasSuffix(s, ".SZ"), strings.HasSuffix(s, ".BJ"):
+		return "stock", "cn", "CNY"
+	case strings.HasSuffix(s, ".HK"):
+		return "stock", "hk", "HKD"
+	case strings.HasSuffix(s, ".T"):
+		return "stock", "jp", "JPY"
+	case strings.HasSuffix(s, ".L"):
+		return "stock", "uk", "GBP"
+	case strings.HasSuffix(s, "=X"):
+		return "forex", "global", "USD"
+	case strings.HasSuffix(s, "=F"):
+		return "commodity", "global", "USD"
+	case strings.HasPrefix(s, "^"):
+		return "index", "global", "USD"
+	case strings.HasSuffix(s, "USDT"), strings.HasSuffix(s, "-USD"), cryptoAssets[s]:
+		return "crypto", "crypto", "USD"
+	}
+	return "stock", "us", "USD"
+}
+
+var cryptoAssets = map[string]bool{
+	"BTC": true, "ETH": true, "SOL": true, "BNB": true, "XRP": true, "DOGE": true, "ADA": true, "TON": true,
+	"比特币": true, "以太坊": true,
+}
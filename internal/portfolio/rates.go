@@ -0,0 +1,11 @@
+package portfolio
+
+import (
+	"context"
+)
+
+// RateProvider converts between currencies: amount_in_to = amount_in_from * Rate(from, to).
+// fx.Service is the usual implementation.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
@@ -0,0 +1,68 @@
+package portfolio
+
+import (
+	"fmt"
+
+	"investor/internal/render"
+)
+
+// Document renders the report as a summary, holdings table and exposures
+func (r *Report) Document() *render.Document {
+	if len(r.Holdings) == 0 {
+		return render.NewDocument().Paragraph("组合为空。示例: /pf add AAPL 10 180")
+	}
+
+	pnlIcon, dayIcon := "📈", "📈"
+	if r.PnL < 0 {
+		pnlIcon = "📉"
+	}
+	if r.DayChange < 0 {
+		dayIcon = "📉"
+	}
+
+	doc := render.NewDocument().
+		Heading("💼", "我的组合").
+		Divider().
+		Fields("",
+			render.Field{Icon: "💰", Key: "总市值", Value: fmt.Sprintf("%.2f %s", r.TotalValue, r.BaseCurrency)},
+			render.Field{Icon: pnlIcon, Key: "浮动盈亏", Value: fmt.Sprintf("%+.2f (%+.2f%%)", r.PnL, r.PnLPct)},
+			render.Field{Icon: dayIcon, Key: "今日", Value: fmt.Sprintf("%+.2f (%+.2f%%)", r.DayChange, r.DayChangePct)},
+		)
+
+	var rows [][]string
+	for _, h := range r.Holdings {
+		if h.Error != "" {
+			rows = append(rows, []string{h.Symbol, fmt.Sprintf("%g", h.Quantity), "-", "-", "-", "数据不可用"})
+			continue
+		}
+		rows = append(rows, []string{
+			h.Symbol,
+			fmt.Sprintf("%g", h.Quantity),
+			fmt.Sprintf("%.2f %s", h.Price, h.Currency),
+			fmt.Sprintf("%.2f", h.Value),
+			fmt.Sprintf("%+.2f%%", h.PnLPct),
+			fmt.Sprintf("%.1f%%", h.Weight*100),
+		})
+	}
+	doc.Table([]string{"标的", "数量", "现价", "市值(" + r.BaseCurrency + ")", "盈亏", "占比"}, rows)
+
+	doc.Fields("资产配置", allocation(r.ByAssetType)...).
+		Fields("市场分布", allocation(r.ByMarket)...).
+		Fields("币种分布", allocation(r.ByCurrency)...)
+
+	if len(r.Warnings) > 0 {
+		doc.Divider()
+		for _, w := range r.Warnings {
+			doc.Paragraph("⚠️ " + w)
+		}
+	}
+	return doc
+}
+
+func allocation(weights map[string]float64) []render.Field {
+	var fields []render.Field
+	for _, k := range sortedKeys(weights) {
+		fields = append(fields, render.Field{Key: k, Value: fmt.Sprintf("%.1f%%", weights[k]*100)})
+	}
+	return fields
+}
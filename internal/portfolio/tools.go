@@ -0,0 +1,200 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"investor/internal/command"
+	"investor/internal/model"
+	"investor/internal/render"
+	"investor/internal/tools"
+)
+
+// RegisterTools exposes the portfolio to the LLM
+func RegisterTools(r *tools.Registry, m *Manager) {
+	r.Register(tools.Tool{
+		Name:        "add_position",
+		Description: "记录一笔买入/持仓（同一标的会按加权平均更新成本）",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "标的代码或名称，如 'AAPL', 'BTC', '茅台'",
+				},
+				"quantity": map[string]interface{}{
+					"type":        "number",
+					"description": "数量 (股/枚)",
+				},
+				"cost_basis": map[string]interface{}{
+					"type":        "number",
+					"description": "每单位成本价，使用标的计价货币",
+				},
+				"currency": map[string]interface{}{
+					"type":        "string",
+					"description": "计价货币 (可选，默认按市场推断: A股 CNY, 港股 HKD, 美股/加密 USD)",
+				},
+			},
+			"required": []string{"symbol", "quantity", "cost_basis"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			owner, err := ownerFrom(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var pos Position
+			if err := tools.Decode(raw, &pos); err != nil {
+				return nil, err
+			}
+			return m.Buy(ctx, owner, pos)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "remove_position",
+		Description: "记录卖出/减仓，quantity 省略或为 0 时清仓",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "标的代码或名称",
+				},
+				"quantity": map[string]interface{}{
+					"type":        "number",
+					"description": "卖出数量 (可选)",
+				},
+			},
+			"required": []string{"symbol"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			owner, err := ownerFrom(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var args struct {
+				Symbol   string  `json:"symbol"`
+				Quantity float64 `json:"quantity"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return m.Sell(ctx, owner, args.Symbol, args.Quantity)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "get_portfolio",
+		Description: "获取用户持仓组合的实时估值：总市值、浮动盈亏、今日盈亏、按资产类别/市场/币种的配置比例和集中度风险提示",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"base_currency": map[string]interface{}{
+					"type":        "string",
+					"description": "折算货币 (可选)，如 'USD', 'CNY', 'HKD'；设置后会保存为默认",
+				},
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			owner, err := ownerFrom(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var args struct {
+				BaseCurrency string `json:"base_currency"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.BaseCurrency != "" {
+				if _, err := m.SetBase(ctx, owner, args.BaseCurrency); err != nil {
+					return nil, err
+				}
+			}
+			return m.Valuate(ctx, owner)
+		},
+	})
+}
+
+// RegisterCommands adds the /pf slash command:
+//
+//	/pf                          portfolio report
+//	/pf add AAPL 10 180 [USD]    record a buy
+//	/pf rm AAPL [5]              record a sell (all if no quantity)
+//	/pf base CNY                 change the reporting currency
+func RegisterCommands(r *command.Router, m *Manager) {
+	r.Register(command.Command{
+		Name:    "pf",
+		Usage:   "/pf [add <标的> <数量> <成本> [币种]|rm <标的> [数量]|base <币种>]",
+		Summary: "持仓组合：盈亏、配置与集中度",
+		Handler: func(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error) {
+			owner := Owner{Platform: msg.Platform, UserID: msg.UserID}
+			if len(args) == 0 {
+				report, err := m.Valuate(ctx, owner)
+				if err != nil {
+					return nil, err
+				}
+				return report.Document(), nil
+			}
+
+			switch args[0] {
+			case "add", "buy":
+				if len(args) < 4 {
+					return nil, fmt.Errorf("用法: /pf add AAPL 10 180 [USD]")
+				}
+				qty, err1 := strconv.ParseFloat(args[2], 64)
+				cost, err2 := strconv.ParseFloat(args[3], 64)
+				if err1 != nil || err2 != nil {
+					return nil, fmt.Errorf("数量和成本必须是数字")
+				}
+				pos := Position{Symbol: args[1], Quantity: qty, CostBasis: cost}
+				if len(args) > 4 {
+					pos.Currency = args[4]
+				}
+				p, err := m.Buy(ctx, owner, pos)
+				if err != nil {
+					return nil, err
+				}
+				return render.NewDocument().Paragraph(fmt.Sprintf("✅ %s 持仓 %g，成本 %.2f %s", p.Symbol, p.Quantity, p.CostBasis, p.Currency)), nil
+
+			case "rm", "sell", "del":
+				if len(args) < 2 {
+					return nil, fmt.Errorf("用法: /pf rm AAPL [数量]")
+				}
+				var qty float64
+				if len(args) > 2 {
+					var err error
+					if qty, err = strconv.ParseFloat(args[2], 64); err != nil {
+						return nil, fmt.Errorf("数量必须是数字")
+					}
+				}
+				if _, err := m.Sell(ctx, owner, args[1], qty); err != nil {
+					return nil, err
+				}
+				return render.NewDocument().Paragraph("✅ 已更新 " + strings.ToUpper(args[1])), nil
+
+			case "base":
+				if len(args) != 2 {
+					return nil, fmt.Errorf("用法: /pf base CNY")
+				}
+				p, err := m.SetBase(ctx, owner, args[1])
+				if err != nil {
+					return nil, err
+				}
+				return render.NewDocument().Paragraph("✅ 折算货币: " + p.BaseCurrency), nil
+			}
+			return nil, fmt.Errorf("unknown subcommand %q, see /help", args[0])
+		},
+	})
+}
+
+func ownerFrom(ctx context.Context) (Owner, error) {
+	msg := tools.MessageFrom(ctx)
+	if msg == nil {
+		return Owner{}, fmt.Errorf("portfolios are only available in a chat")
+	}
+	return Owner{Platform: msg.Platform, UserID: msg.UserID}, nil
+}
@@ -0,0 +1,149 @@
+package render
+
+// Document is a platform-neutral reply made of ordered blocks.
+// Templates build Documents and a Renderer turns them into the target
+// platform's native text format (Feishu card markdown, Telegram MarkdownV2, ...).
+type Document struct {
+	Blocks []Block
+}
+
+// Block is one element of a Document
+type Block interface {
+	block()
+}
+
+// Heading is the title line of a reply, e.g. "📊 **AAPL 实时行情**"
+type Heading struct {
+	Icon string
+	Text string
+}
+
+// Paragraph is a plain text line
+type Paragraph struct {
+	Text   string
+	Italic bool
+}
+
+// Field is a single key-value pair
+type Field struct {
+	Icon  string
+	Key   string
+	Value string
+}
+
+// KeyValue is a list of fields, optionally grouped under a title
+type KeyValue struct {
+	Title  string
+	Fields []Field
+}
+
+// Table is a simple grid with a header row
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Link is a labelled URL
+type Link struct {
+	Icon  string
+	Label string
+	URL   string
+}
+
+// Sparkline renders a series as unicode blocks
+type Sparkline struct {
+	Icon   string
+	Label  string
+	Values []float64
+}
+
+// Item is a list entry with a title, a meta line and an optional quote (e.g. a news item)
+type Item struct {
+	Title string
+	URL   string
+	Meta  string
+	Quote string
+}
+
+// Divider separates sections
+type Divider struct{}
+
+// Spacer is an empty line
+type Spacer struct{}
+
+// Markdown is raw (GitHub-flavoured) markdown, e.g. LLM output.
+// Renderers convert it best-effort to their own dialect.
+type Markdown struct {
+	Text string
+}
+
+func (Heading) block()   {}
+func (Paragraph) block() {}
+func (KeyValue) block()  {}
+func (Table) block()     {}
+func (Link) block()      {}
+func (Sparkline) block() {}
+func (Item) block()      {}
+func (Divider) block()   {}
+func (Spacer) block()    {}
+func (Markdown) block()  {}
+
+// NewDocument creates an empty document
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// FromMarkdown wraps raw markdown in a document
+func FromMarkdown(text string) *Document {
+	return NewDocument().Markdown(text)
+}
+
+// Add appends blocks
+func (d *Document) Add(blocks ...Block) *Document {
+	d.Blocks = append(d.Blocks, blocks...)
+	return d
+}
+
+func (d *Document) Heading(icon, text string) *Document {
+	return d.Add(Heading{Icon: icon, Text: text})
+}
+
+func (d *Document) Paragraph(text string) *Document {
+	return d.Add(Paragraph{Text: text})
+}
+
+func (d *Document) Note(text string) *Document {
+	return d.Add(Paragraph{Text: text, Italic: true})
+}
+
+func (d *Document) Fields(title string, fields ...Field) *Document {
+	return d.Add(KeyValue{Title: title, Fields: fields})
+}
+
+func (d *Document) Table(headers []string, rows [][]string) *Document {
+	return d.Add(Table{Headers: headers, Rows: rows})
+}
+
+func (d *Document) Link(icon, label, url string) *Document {
+	return d.Add(Link{Icon: icon, Label: label, URL: url})
+}
+
+func (d *Document) Sparkline(icon, label string, values []float64) *Document {
+	return d.Add(Sparkline{Icon: icon, Label: label, Values: values})
+}
+
+func (d *Document) Item(item Item) *Document {
+	return d.Add(item)
+}
+
+func (d *Document) Divider() *Document {
+	return d.Add(Divider{})
+}
+
+func (d *Document) Spacer() *Document {
+	return d.Add(Spacer{})
+}
+
+func (d *Document) Markdown(text string) *Document {
+	return d.Add(Markdown{Text: text})
+}
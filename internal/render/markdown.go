@@ -0,0 +1,146 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	bulletRe   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	tableSepRe = regexp.MustCompile(`^\|?\s*:?-{2,}:?\s*(\|\s*:?-{2,}:?\s*)*\|?$`)
+	hruleRe    = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,})$`)
+)
+
+// markdown converts GitHub-flavoured markdown (typically LLM output)
+// into the dialect line by line. It handles the subset the agent produces:
+// headings, bullets, quotes, pipe tables, code fences and inline
+// bold/italic/code/links. Anything else is escaped as text.
+func (d *dialect) markdown(text string) string {
+	var out []string
+	var table *Table
+	inCode := false
+
+	flush := func() {
+		if table != nil {
+			out = append(out, d.renderTable(*table))
+			table = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			inCode = !inCode
+			if d.name == "plain" {
+				continue
+			}
+			out = append(out, "```")
+			continue
+		}
+		if inCode {
+			out = append(out, escapeTelegramCodeIf(d, line))
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			if tableSepRe.MatchString(trimmed) {
+				continue
+			}
+			cells := splitRow(trimmed)
+			if table == nil {
+				table = &Table{Headers: cells}
+			} else {
+				table.Rows = append(table.Rows, cells)
+			}
+			continue
+		}
+		flush()
+
+		switch {
+		case trimmed == "":
+			out = append(out, "")
+		case hruleRe.MatchString(trimmed):
+			out = append(out, d.escape("-------------------"))
+		case headingRe.MatchString(trimmed):
+			m := headingRe.FindStringSubmatch(trimmed)
+			out = append(out, d.bold(d.inline(stripBold(m[2]))))
+		case bulletRe.MatchString(line):
+			m := bulletRe.FindStringSubmatch(line)
+			out = append(out, m[1]+d.escape("• ")+d.inline(m[2]))
+		case strings.HasPrefix(trimmed, ">"):
+			out = append(out, d.quote+d.inline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))))
+		default:
+			out = append(out, d.inline(line))
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+func splitRow(line string) []string {
+	line = strings.Trim(line, "|")
+	parts := strings.Split(line, "|")
+	cells := make([]string, 0, len(parts))
+	for _, p := range parts {
+		cells = append(cells, stripBold(strings.TrimSpace(p)))
+	}
+	return cells
+}
+
+func stripBold(s string) string {
+	return strings.ReplaceAll(s, "**", "")
+}
+
+// inline converts **bold**, *italic*/_italic_, `code` and [label](url) spans
+func (d *dialect) inline(s string) string {
+	var sb strings.Builder
+	var text strings.Builder
+
+	emit := func() {
+		sb.WriteString(d.escape(text.String()))
+		text.Reset()
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "**"):
+			if end := strings.Index(rest[2:], "**"); end > 0 {
+				emit()
+				sb.WriteString(d.bold(d.escape(rest[2 : 2+end])))
+				i += end + 4
+				continue
+			}
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				emit()
+				sb.WriteString(d.code(rest[1 : 1+end]))
+				i += end + 2
+				continue
+			}
+		case rest[0] == '*' || rest[0] == '_':
+			if end := strings.IndexByte(rest[1:], rest[0]); end > 0 && rest[1] != ' ' {
+				emit()
+				sb.WriteString(d.italic(d.escape(rest[1 : 1+end])))
+				i += end + 2
+				continue
+			}
+		case rest[0] == '[':
+			if close := strings.Index(rest, "]("); close > 0 {
+				if end := strings.IndexByte(rest[close+2:], ')'); end >= 0 {
+					emit()
+					sb.WriteString(d.link(d.escape(rest[1:close]), rest[close+2:close+2+end]))
+					i += close + 3 + end
+					continue
+				}
+			}
+		}
+		text.WriteByte(s[i])
+		i++
+	}
+	emit()
+	return sb.String()
+}
@@ -0,0 +1,321 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Renderer turns a Document into a platform's native text format
+type Renderer interface {
+	Name() string
+	Render(doc *Document) string
+}
+
+// tableMode controls how a dialect lays out tables
+type tableMode int
+
+const (
+	tableNative tableMode = iota // GitHub pipe tables
+	tableList                    // one bullet per row
+	tableCode                    // aligned monospace block
+	tableText                    // aligned monospace without fences
+)
+
+// dialect describes the inline syntax of a target format.
+// All built-in renderers share one layout engine and differ only here.
+type dialect struct {
+	name        string
+	escape      func(string) string
+	bold        func(string) string
+	italic      func(string) string
+	code        func(string) string
+	link        func(label, url string) string
+	quote       string
+	table       tableMode
+	passthrough bool // raw markdown is already in this dialect
+}
+
+func (d *dialect) Name() string {
+	return d.name
+}
+
+var (
+	// MarkdownRenderer is GitHub-flavoured markdown, used for REST clients by default
+	MarkdownRenderer Renderer = &dialect{
+		name:        "markdown",
+		escape:      identity,
+		bold:        wrap("**"),
+		italic:      wrap("*"),
+		code:        wrap("`"),
+		link:        func(l, u string) string { return fmt.Sprintf("[%s](%s)", l, u) },
+		quote:       "> ",
+		table:       tableNative,
+		passthrough: true,
+	}
+
+	// FeishuRenderer is the card "markdown" element dialect: no tables, no headings
+	FeishuRenderer Renderer = &dialect{
+		name:   "feishu",
+		escape: identity,
+		bold:   wrap("**"),
+		italic: wrap("*"),
+		code:   wrap("`"),
+		link:   func(l, u string) string { return fmt.Sprintf("[%s](%s)", l, u) },
+		quote:  "> ",
+		table:  tableList,
+	}
+
+	// TelegramRenderer is MarkdownV2 (every reserved character must be escaped)
+	TelegramRenderer Renderer = &dialect{
+		name:   "telegram",
+		escape: escapeTelegram,
+		bold:   wrap("*"),
+		italic: wrap("_"),
+		code:   func(s string) string { return "`" + escapeTelegramCode(s) + "`" },
+		link: func(l, u string) string {
+			return fmt.Sprintf("[%s](%s)", l, strings.NewReplacer(`\`, `\\`, ")", `\)`).Replace(u))
+		},
+		quote: ">",
+		table: tableCode,
+	}
+
+	// SlackRenderer is Slack mrkdwn
+	SlackRenderer Renderer = &dialect{
+		name:   "slack",
+		escape: strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace,
+		bold:   wrap("*"),
+		italic: wrap("_"),
+		code:   wrap("`"),
+		link:   func(l, u string) string { return fmt.Sprintf("<%s|%s>", u, l) },
+		quote:  "> ",
+		table:  tableCode,
+	}
+
+	// PlainRenderer strips all formatting
+	PlainRenderer Renderer = &dialect{
+		name:   "plain",
+		escape: identity,
+		bold:   identity,
+		italic: identity,
+		code:   identity,
+		link:   func(l, u string) string { return fmt.Sprintf("%s (%s)", l, u) },
+		quote:  "  ",
+		table:  tableText,
+	}
+)
+
+// ForPlatform picks the renderer for an InternalMessage.Platform value.
+// Unknown platforms get plain GitHub markdown.
+func ForPlatform(platform string) Renderer {
+	switch strings.ToLower(platform) {
+	case "feishu", "lark":
+		return FeishuRenderer
+	case "telegram":
+		return TelegramRenderer
+	case "slack":
+		return SlackRenderer
+	case "plain", "text", "wecom", "wechat":
+		return PlainRenderer
+	default:
+		return MarkdownRenderer
+	}
+}
+
+// Render is a shortcut for ForPlatform(platform).Render(doc)
+func Render(platform string, doc *Document) string {
+	return ForPlatform(platform).Render(doc)
+}
+
+func (d *dialect) Render(doc *Document) string {
+	if doc == nil {
+		return ""
+	}
+	var lines []string
+	for _, b := range doc.Blocks {
+		lines = append(lines, d.block(b))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *dialect) block(b Block) string {
+	switch v := b.(type) {
+	case Heading:
+		return prefix(v.Icon, d.bold(d.escape(v.Text)))
+	case Paragraph:
+		if v.Italic {
+			return d.italic(d.escape(v.Text))
+		}
+		return d.escape(v.Text)
+	case KeyValue:
+		return d.keyValue(v)
+	case Table:
+		return d.renderTable(v)
+	case Link:
+		return prefix(v.Icon, d.link(d.escape(v.Label), v.URL))
+	case Sparkline:
+		return prefix(v.Icon, d.escape(v.Label+": "+SparklineText(v.Values)))
+	case Item:
+		title := d.bold(d.escape(v.Title))
+		if v.URL != "" {
+			title = d.link(title, v.URL)
+		}
+		out := d.escape("• ") + title
+		if v.Meta != "" {
+			out += "\n  " + d.italic(d.escape(v.Meta))
+		}
+		if v.Quote != "" {
+			// Quote markers only count at the start of a line (and Telegram
+			// rejects a bare ">" anywhere else), so the quote is not indented
+			out += "\n" + d.quote + d.escape(v.Quote)
+		}
+		return out + "\n"
+	case Divider:
+		return d.escape("-------------------")
+	case Spacer:
+		return ""
+	case Markdown:
+		if d.passthrough {
+			return v.Text
+		}
+		return d.markdown(v.Text)
+	}
+	return ""
+}
+
+func (d *dialect) keyValue(kv KeyValue) string {
+	var sb strings.Builder
+	indent := ""
+	if kv.Title != "" {
+		sb.WriteString(d.escape("• ") + d.bold(d.escape(kv.Title)) + d.escape(":"))
+		indent = "  "
+	}
+	for i, f := range kv.Fields {
+		if i > 0 || kv.Title != "" {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(indent)
+		text := f.Value
+		if f.Key != "" {
+			text = f.Key + ": " + f.Value
+		}
+		sb.WriteString(prefix(f.Icon, d.escape(text)))
+	}
+	return sb.String()
+}
+
+func (d *dialect) renderTable(t Table) string {
+	switch d.table {
+	case tableNative:
+		var sb strings.Builder
+		sb.WriteString("| " + strings.Join(t.Headers, " | ") + " |\n")
+		seps := make([]string, len(t.Headers))
+		for i := range seps {
+			seps[i] = "---"
+		}
+		sb.WriteString("|" + strings.Join(seps, "|") + "|")
+		for _, row := range t.Rows {
+			sb.WriteString("\n| " + strings.Join(row, " | ") + " |")
+		}
+		return sb.String()
+	case tableList:
+		var sb strings.Builder
+		sb.WriteString(d.bold(d.escape(strings.Join(t.Headers, " | "))))
+		for _, row := range t.Rows {
+			sb.WriteString("\n" + d.escape("• "+strings.Join(row, " | ")))
+		}
+		return sb.String()
+	default:
+		text := alignTable(t)
+		if d.table == tableText {
+			return text
+		}
+		return "```\n" + escapeTelegramCodeIf(d, text) + "\n```"
+	}
+}
+
+// alignTable lays out a table in monospace, padding by display width
+func alignTable(t Table) string {
+	cols := len(t.Headers)
+	for _, r := range t.Rows {
+		if len(r) > cols {
+			cols = len(r)
+		}
+	}
+	widths := make([]int, cols)
+	all := append([][]string{t.Headers}, t.Rows...)
+	for _, r := range all {
+		for i, c := range r {
+			if w := displayWidth(c); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	var lines []string
+	for _, r := range all {
+		var cells []string
+		for i := 0; i < cols; i++ {
+			c := ""
+			if i < len(r) {
+				c = r[i]
+			}
+			cells = append(cells, c+strings.Repeat(" ", widths[i]-displayWidth(c)))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// displayWidth counts wide (CJK) runes as two columns
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hiragana, r) || (r >= 0xFF00 && r <= 0xFFEF) {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return w
+}
+
+func prefix(icon, text string) string {
+	if icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
+
+func identity(s string) string {
+	return s
+}
+
+func wrap(marker string) func(string) string {
+	return func(s string) string {
+		if s == "" {
+			return s
+		}
+		return marker + s + marker
+	}
+}
+
+var telegramReserved = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+func escapeTelegram(s string) string {
+	return telegramReserved.Replace(s)
+}
+
+func escapeTelegramCode(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(s)
+}
+
+func escapeTelegramCodeIf(d *dialect, s string) string {
+	if d.name == "telegram" {
+		return escapeTelegramCode(s)
+	}
+	return s
+}
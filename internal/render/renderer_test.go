@@ -0,0 +1,116 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+var dialects = []Renderer{MarkdownRenderer, FeishuRenderer, TelegramRenderer, SlackRenderer, PlainRenderer}
+
+func TestRenderBlocks(t *testing.T) {
+	cases := []struct {
+		name  string
+		block Block
+		want  map[string]string // by renderer name
+	}{
+		{"heading", Heading{Icon: "📊", Text: "AAPL 实时行情"}, map[string]string{
+			"markdown": "📊 **AAPL 实时行情**",
+			"feishu":   "📊 **AAPL 实时行情**",
+			"telegram": "📊 *AAPL 实时行情*",
+			"slack":    "📊 *AAPL 实时行情*",
+			"plain":    "📊 AAPL 实时行情",
+		}},
+		{"paragraph", Paragraph{Text: "Price 1.5 (+2%) <b>"}, map[string]string{
+			"markdown": "Price 1.5 (+2%) <b>",
+			"feishu":   "Price 1.5 (+2%) <b>",
+			"telegram": `Price 1\.5 \(\+2%\) <b\>`,
+			"slack":    "Price 1.5 (+2%) &lt;b&gt;",
+			"plain":    "Price 1.5 (+2%) <b>",
+		}},
+		{"note", Paragraph{Text: "数据仅供参考", Italic: true}, map[string]string{
+			"markdown": "*数据仅供参考*",
+			"feishu":   "*数据仅供参考*",
+			"telegram": "_数据仅供参考_",
+			"slack":    "_数据仅供参考_",
+			"plain":    "数据仅供参考",
+		}},
+		{"fields", KeyValue{Title: "Trade", Fields: []Field{{Icon: "🎯", Key: "Entry", Value: "180-185"}, {Key: "Stop", Value: "175"}}}, map[string]string{
+			"markdown": "• **Trade**:\n  🎯 Entry: 180-185\n  Stop: 175",
+			"feishu":   "• **Trade**:\n  🎯 Entry: 180-185\n  Stop: 175",
+			"telegram": "• *Trade*:\n  🎯 Entry: 180\\-185\n  Stop: 175",
+			"slack":    "• *Trade*:\n  🎯 Entry: 180-185\n  Stop: 175",
+			"plain":    "• Trade:\n  🎯 Entry: 180-185\n  Stop: 175",
+		}},
+		{"table", Table{Headers: []string{"代码", "价格"}, Rows: [][]string{{"AAPL", "232.1"}, {"茅台", "1480"}}}, map[string]string{
+			"markdown": "| 代码 | 价格 |\n|---|---|\n| AAPL | 232.1 |\n| 茅台 | 1480 |",
+			"feishu":   "**代码 | 价格**\n• AAPL | 232.1\n• 茅台 | 1480",
+			"telegram": "```\n代码  价格\nAAPL  232.1\n茅台  1480\n```",
+			"slack":    "```\n代码  价格\nAAPL  232.1\n茅台  1480\n```",
+			"plain":    "代码  价格\nAAPL  232.1\n茅台  1480",
+		}},
+		{"link", Link{Icon: "🔗", Label: "Yahoo", URL: "https://x.com/a_(b)"}, map[string]string{
+			"markdown": "🔗 [Yahoo](https://x.com/a_(b))",
+			"feishu":   "🔗 [Yahoo](https://x.com/a_(b))",
+			"telegram": `🔗 [Yahoo](https://x.com/a_(b\))`,
+			"slack":    "🔗 <https://x.com/a_(b)|Yahoo>",
+			"plain":    "🔗 Yahoo (https://x.com/a_(b))",
+		}},
+		// The quote starts its own line: a ">" after indentation is no quote
+		// and is a reserved character in Telegram MarkdownV2
+		{"item", Item{Title: "Fed holds", URL: "https://n.com/1", Meta: "Reuters · 2h", Quote: "Rates stay at 4.5%"}, map[string]string{
+			"markdown": "• [**Fed holds**](https://n.com/1)\n  *Reuters · 2h*\n> Rates stay at 4.5%\n",
+			"feishu":   "• [**Fed holds**](https://n.com/1)\n  *Reuters · 2h*\n> Rates stay at 4.5%\n",
+			"telegram": "• [*Fed holds*](https://n.com/1)\n  _Reuters · 2h_\n>Rates stay at 4\\.5%\n",
+			"slack":    "• <https://n.com/1|*Fed holds*>\n  _Reuters · 2h_\n> Rates stay at 4.5%\n",
+			"plain":    "• Fed holds (https://n.com/1)\n  Reuters · 2h\n  Rates stay at 4.5%\n",
+		}},
+		{"divider", Divider{}, map[string]string{
+			"markdown": "-------------------",
+			"feishu":   "-------------------",
+			"telegram": strings.Repeat(`\-`, 19),
+			"slack":    "-------------------",
+			"plain":    "-------------------",
+		}},
+	}
+	for _, c := range cases {
+		doc := NewDocument().Add(c.block)
+		for _, r := range dialects {
+			if got := r.Render(doc); got != c.want[r.Name()] {
+				t.Errorf("%s/%s:\n got %q\nwant %q", c.name, r.Name(), got, c.want[r.Name()])
+			}
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	text := "## Title\n- **NVDA** up 3.2%\n> note <b>\n| a | b |\n|---|---|\n| 1 | 2 |\n```\nx`y\n```"
+	want := map[string]string{
+		"markdown": text, // passed through
+		"feishu":   "**Title**\n• **NVDA** up 3.2%\n> note <b>\n**a | b**\n• 1 | 2\n```\nx`y\n```",
+		"telegram": "*Title*\n• *NVDA* up 3\\.2%\n>note <b\\>\n```\na  b\n1  2\n```\n```\nx\\`y\n```",
+		"slack":    "*Title*\n• *NVDA* up 3.2%\n> note &lt;b&gt;\n```\na  b\n1  2\n```\n```\nx`y\n```",
+		"plain":    "Title\n• NVDA up 3.2%\n  note <b>\na  b\n1  2\nx`y",
+	}
+	for _, r := range dialects {
+		if got := r.Render(FromMarkdown(text)); got != want[r.Name()] {
+			t.Errorf("%s:\n got %q\nwant %q", r.Name(), got, want[r.Name()])
+		}
+	}
+}
+
+func TestForPlatform(t *testing.T) {
+	cases := map[string]string{
+		"feishu":   "feishu",
+		"Lark":     "feishu",
+		"telegram": "telegram",
+		"slack":    "slack",
+		"wecom":    "plain",
+		"api":      "markdown",
+		"":         "markdown",
+	}
+	for platform, want := range cases {
+		if got := ForPlatform(platform).Name(); got != want {
+			t.Errorf("ForPlatform(%q) = %s, want %s", platform, got, want)
+		}
+	}
+}
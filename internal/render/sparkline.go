@@ -0,0 +1,33 @@
+package render
+
+import "strings"
+
+// SparklineText creates a unicode sparkline from data
+func SparklineText(data []float64) string {
+	if len(data) == 0 {
+		return ""
+	}
+	min := data[0]
+	max := data[0]
+	for _, v := range data {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	rangeVal := max - min
+	if rangeVal == 0 {
+		return strings.Repeat("▅", len(data))
+	}
+
+	blocks := []string{" ", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+	var sb strings.Builder
+	for _, v := range data {
+		idx := int((v - min) / rangeVal * float64(len(blocks)-1))
+		sb.WriteString(blocks[idx])
+	}
+	return sb.String()
+}
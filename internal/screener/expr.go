@@ -0,0 +1,412 @@
+package screener
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Fields are the metrics a filter can reference
+var Fields = map[string]string{
+	"price":      "现价",
+	"change_pct": "日涨跌幅 (%)",
+	"ma20":       "20日均线",
+	"ma60":       "60日均线",
+	"rsi":        "RSI(14)",
+	"vol_ratio":  "量比 (当日量 / 5日均量)",
+	"support":    "20日支撑位",
+	"resistance": "20日压力位",
+}
+
+// Aliases map common spellings to field names
+var fieldAliases = map[string]string{
+	"close": "price", "last": "price", "价格": "price", "现价": "price",
+	"change": "change_pct", "pct": "change_pct", "chg": "change_pct", "涨跌幅": "change_pct",
+	"rsi14": "rsi", "volume_ratio": "vol_ratio", "volratio": "vol_ratio", "量比": "vol_ratio",
+	"ma_20": "ma20", "ma_60": "ma60",
+}
+
+// FieldNames lists the field names in a stable order
+func FieldNames() []string {
+	names := make([]string, 0, len(Fields))
+	for k := range Fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expr is a compiled filter, e.g. "rsi < 30 and price > ma60 * 1.02"
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse compiles a filter expression. Grammar:
+//
+//	or      := and (("or" | "||") and)*
+//	and     := unary (("and" | "&&" | ",") unary)*
+//	unary   := "not" unary | "(" or ")" | compare
+//	compare := sum ("<" | "<=" | ">" | ">=" | "==" | "!=") sum
+//	sum     := product (("+" | "-") product)*
+//	product := atom (("*" | "/") atom)*
+//	atom    := number | field
+func Parse(src string) (*Expr, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q at end of filter", p.toks[p.pos].text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Match evaluates the filter against a metric set. Missing metrics never match.
+func (e *Expr) Match(metrics map[string]float64) bool {
+	return e.root.eval(metrics) != 0
+}
+
+// node evaluates to a number; comparisons and logic yield 1 (true) or 0 (false).
+// NaN marks a missing metric and makes every comparison false.
+type node interface {
+	eval(m map[string]float64) float64
+}
+
+type num float64
+
+func (n num) eval(map[string]float64) float64 { return float64(n) }
+
+type field string
+
+func (f field) eval(m map[string]float64) float64 {
+	if v, ok := m[string(f)]; ok {
+		return v
+	}
+	return math.NaN()
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (b *binary) eval(m map[string]float64) float64 {
+	switch b.op {
+	case "and":
+		return bool2f(b.l.eval(m) != 0 && b.r.eval(m) != 0)
+	case "or":
+		return bool2f(b.l.eval(m) != 0 || b.r.eval(m) != 0)
+	}
+
+	l, r := b.l.eval(m), b.r.eval(m)
+	if math.IsNaN(l) || math.IsNaN(r) {
+		if b.op == "+" || b.op == "-" || b.op == "*" || b.op == "/" {
+			return math.NaN()
+		}
+		return 0
+	}
+	switch b.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	case "/":
+		if r == 0 {
+			return math.NaN()
+		}
+		return l / r
+	case "<":
+		return bool2f(l < r)
+	case "<=":
+		return bool2f(l <= r)
+	case ">":
+		return bool2f(l > r)
+	case ">=":
+		return bool2f(l >= r)
+	case "==":
+		return bool2f(l == r)
+	case "!=":
+		return bool2f(l != r)
+	}
+	return 0
+}
+
+type not struct{ x node }
+
+func (n not) eval(m map[string]float64) float64 { return bool2f(n.x.eval(m) == 0) }
+
+func bool2f(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type token struct {
+	kind string // num, ident, op, lparen, rparen
+	text string
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			toks = append(toks, token{"lparen", "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{"rparen", ")"})
+			i++
+		case c == ',':
+			toks = append(toks, token{"op", "and"})
+			i++
+		case unicode.IsDigit(c) || c == '.' || (c == '-' && i+1 < len(rs) && (unicode.IsDigit(rs[i+1]) || rs[i+1] == '.') && expectsOperand(toks)):
+			j := i + 1
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			text := string(rs[i:j])
+			if j < len(rs) && rs[j] == '%' { // "-3%" reads as -3
+				j++
+			}
+			toks = append(toks, token{"num", text})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_') {
+				j++
+			}
+			word := strings.ToLower(string(rs[i:j]))
+			switch word {
+			case "and", "or", "not":
+				toks = append(toks, token{"op", word})
+			default:
+				toks = append(toks, token{"ident", word})
+			}
+			i = j
+		default:
+			two := ""
+			if i+1 < len(rs) {
+				two = string(rs[i : i+2])
+			}
+			switch two {
+			case "<=", ">=", "==", "!=":
+				toks = append(toks, token{"op", two})
+				i += 2
+				continue
+			case "&&":
+				toks = append(toks, token{"op", "and"})
+				i += 2
+				continue
+			case "||":
+				toks = append(toks, token{"op", "or"})
+				i += 2
+				continue
+			}
+			switch c {
+			case '<', '>', '+', '-', '*', '/':
+				toks = append(toks, token{"op", string(c)})
+			case '=':
+				toks = append(toks, token{"op", "=="})
+			case '!':
+				toks = append(toks, token{"op", "not"})
+			default:
+				return nil, fmt.Errorf("unexpected character %q in filter", c)
+			}
+			i++
+		}
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+	return toks, nil
+}
+
+// expectsOperand reports whether a '-' here starts a negative number rather than a subtraction
+func expectsOperand(toks []token) bool {
+	if len(toks) == 0 {
+		return true
+	}
+	last := toks[len(toks)-1]
+	return last.kind == "op" || last.kind == "lparen"
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return token{}
+}
+
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != "op" {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) or() (node, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("or"); !ok {
+			return l, nil
+		}
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = &binary{"or", l, r}
+	}
+}
+
+func (p *parser) and() (node, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("and"); !ok {
+			return l, nil
+		}
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = &binary{"and", l, r}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if _, ok := p.acceptOp("not"); ok {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return not{x}, nil
+	}
+	if p.peek().kind == "lparen" {
+		p.pos++
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != "rparen" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return x, nil
+	}
+	return p.compare()
+}
+
+func (p *parser) compare() (node, error) {
+	l, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		return nil, fmt.Errorf("expected a comparison after %q", p.toks[p.pos-1].text)
+	}
+	r, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op, l, r}, nil
+}
+
+func (p *parser) sum() (node, error) {
+	l, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("+", "-")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		l = &binary{op, l, r}
+	}
+}
+
+func (p *parser) product() (node, error) {
+	l, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("*", "/")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		l = &binary{op, l, r}
+	}
+}
+
+func (p *parser) atom() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case "num":
+		p.pos++
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return num(v), nil
+	case "ident":
+		p.pos++
+		name := t.text
+		if alias, ok := fieldAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := Fields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", t.text, strings.Join(FieldNames(), ", "))
+		}
+		return field(name), nil
+	case "":
+		return nil, fmt.Errorf("filter ends unexpectedly")
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
@@ -0,0 +1,198 @@
+package screener
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"investor/internal/dataservice"
+)
+
+// Screener evaluates filters across a universe
+type Screener struct {
+	Data        dataservice.DataService
+	Universes   Universes
+	Concurrency int
+}
+
+func New(data dataservice.DataService, universes Universes, concurrency int) *Screener {
+	if universes == nil {
+		universes = DefaultUniverses()
+	}
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	return &Screener{
+		Data:        data,
+		Universes:   universes,
+		Concurrency: concurrency,
+	}
+}
+
+// Request describes a screen. Symbols, if set, replace the universe.
+type Request struct {
+	Universe string   `json:"universe"`
+	Symbols  []string `json:"symbols,omitempty"`
+	Filter   string   `json:"filter"`
+	SortBy   string   `json:"sort_by,omitempty"` // a field name, default change_pct
+	Order    string   `json:"order,omitempty"`   // "asc" or "desc" (default)
+	Limit    int      `json:"limit,omitempty"`   // default 20
+}
+
+// Row is one matching security with its metrics
+type Row struct {
+	Symbol  string             `json:"symbol"`
+	Name    string             `json:"name,omitempty"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// Result is the outcome of a screen
+type Result struct {
+	Filter   string   `json:"filter"`
+	Universe string   `json:"universe"`
+	Scanned  int      `json:"scanned"`
+	Matched  int      `json:"matched"`
+	Failed   []string `json:"failed,omitempty"` // symbols whose data was unavailable
+	SortBy   string   `json:"sort_by"`
+	Rows     []Row    `json:"rows"`
+}
+
+// Screen fetches metrics for every member (Concurrency at a time) and keeps the matches
+func (s *Screener) Screen(ctx context.Context, req Request) (*Result, error) {
+	expr, err := Parse(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	members, name, err := s.members(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = "change_pct"
+	}
+	if alias, ok := fieldAliases[sortBy]; ok {
+		sortBy = alias
+	}
+	if _, ok := Fields[sortBy]; !ok {
+		return nil, fmt.Errorf("unknown sort field %q", req.SortBy)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	res := &Result{Filter: expr.String(), Universe: name, Scanned: len(members), SortBy: sortBy, Rows: []Row{}}
+	var mu sync.Mutex
+	sem := make(chan struct{}, s.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, m := range members {
+		wg.Add(1)
+		go func(m Member) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			metrics, err := s.metrics(ctx, m.Symbol)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				res.Failed = append(res.Failed, m.Symbol)
+				return
+			}
+			if expr.Match(metrics) {
+				res.Rows = append(res.Rows, Row{Symbol: m.Symbol, Name: m.Name, Metrics: metrics})
+			}
+		}(m)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	asc := strings.EqualFold(req.Order, "asc")
+	sort.SliceStable(res.Rows, func(i, j int) bool {
+		a, b := res.Rows[i].Metrics[sortBy], res.Rows[j].Metrics[sortBy]
+		if asc {
+			return a < b
+		}
+		return a > b
+	})
+	sort.Strings(res.Failed)
+	res.Matched = len(res.Rows)
+	if len(res.Rows) > limit {
+		res.Rows = res.Rows[:limit]
+	}
+	return res, nil
+}
+
+func (s *Screener) members(req Request) ([]Member, string, error) {
+	if len(req.Symbols) > 0 {
+		var members []Member
+		for _, sym := range req.Symbols {
+			members = append(members, Member{Symbol: sym})
+		}
+		return members, "custom", nil
+	}
+
+	name := strings.ToLower(req.Universe)
+	if name == "" {
+		name = "all"
+	}
+	members, ok := s.Universes[name]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown universe %q (available: %s)", req.Universe, strings.Join(s.Universes.Names(), ", "))
+	}
+	return members, name, nil
+}
+
+// metrics computes the filter fields for one symbol from its technical analysis
+func (s *Screener) metrics(ctx context.Context, symbol string) (map[string]float64, error) {
+	a, err := s.Data.GetSecurityAnalysis(ctx, symbol, "stock")
+	if err != nil {
+		return nil, err
+	}
+	if a.CurrentPrice == 0 {
+		return nil, fmt.Errorf("no price for %s", symbol)
+	}
+
+	m := map[string]float64{
+		"price": a.CurrentPrice,
+		"rsi":   round(a.RSI),
+	}
+	// Indicators that need history are left out when it was unavailable, so they never match
+	if a.MA20 > 0 {
+		m["ma20"] = round(a.MA20)
+	}
+	if a.MA60 > 0 {
+		m["ma60"] = round(a.MA60)
+	}
+	if a.VolumeRatio > 0 {
+		m["vol_ratio"] = round(a.VolumeRatio)
+	}
+	if a.SupportLevel > 0 {
+		m["support"] = a.SupportLevel
+		m["resistance"] = a.ResistanceLevel
+	}
+	if n := len(a.RecentKLines); n >= 2 && a.RecentKLines[n-2].Close > 0 {
+		m["change_pct"] = round((a.CurrentPrice/a.RecentKLines[n-2].Close - 1) * 100)
+	}
+	if a.Trend == "unknown" {
+		delete(m, "rsi")
+	}
+	return m, nil
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
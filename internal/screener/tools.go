@@ -0,0 +1,119 @@
+package screener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"investor/internal/render"
+	"investor/internal/tools"
+)
+
+// RegisterTools exposes the screener to the LLM
+func RegisterTools(r *tools.Registry, s *Screener) {
+	var universes []string
+	for name := range s.Universes {
+		universes = append(universes, name)
+	}
+	sort.Strings(universes)
+
+	var fields []string
+	for _, name := range FieldNames() {
+		fields = append(fields, fmt.Sprintf("%s=%s", name, Fields[name]))
+	}
+
+	r.Register(tools.Tool{
+		Name:        "screen_securities",
+		Description: "按技术指标条件筛选股票池，返回满足条件的标的及指标 (按指定字段排序)。例: 'A股超卖' -> universe=cn, filter='rsi < 30'",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"type": "string",
+					"description": "筛选表达式，支持 < <= > >= == !=、and/or/not、括号和 + - * /。可用字段: " + strings.Join(fields, "; ") +
+						"。例: 'rsi < 30', 'price > ma60 and vol_ratio > 2', 'change_pct <= -3 or change_pct >= 3', 'price > ma20 * 1.05'",
+				},
+				"universe": map[string]interface{}{
+					"type":        "string",
+					"description": "股票池: cn=A股核心蓝筹, hk=港股, us=美股, all=全部 (默认 all)",
+					"enum":        universes,
+				},
+				"symbols": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "自定义标的列表 (可选，设置后忽略 universe)",
+				},
+				"sort_by": map[string]interface{}{
+					"type":        "string",
+					"description": "排序字段，默认 change_pct",
+					"enum":        FieldNames(),
+				},
+				"order": map[string]interface{}{
+					"type":        "string",
+					"description": "asc 升序 / desc 降序 (默认)",
+					"enum":        []string{"asc", "desc"},
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "最多返回条数，默认 20",
+				},
+			},
+			"required": []string{"filter"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var req Request
+			if err := tools.Decode(raw, &req); err != nil {
+				return nil, err
+			}
+			res, err := s.Screen(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"result": res,
+				"table":  render.MarkdownRenderer.Render(res.Document()), // ready-to-show card
+			}, nil
+		},
+	})
+}
+
+// Document renders the matches as a table
+func (r *Result) Document() *render.Document {
+	doc := render.NewDocument().
+		Heading("🔎", fmt.Sprintf("选股: %s", r.Filter)).
+		Paragraph(fmt.Sprintf("股票池 %s: 扫描 %d，命中 %d", r.Universe, r.Scanned, r.Matched))
+	if len(r.Rows) == 0 {
+		return doc.Paragraph("没有满足条件的标的。")
+	}
+
+	var rows [][]string
+	for _, row := range r.Rows {
+		name := row.Symbol
+		if row.Name != "" {
+			name = fmt.Sprintf("%s %s", row.Name, row.Symbol)
+		}
+		rows = append(rows, []string{
+			name,
+			metric(row.Metrics, "price", "%.2f"),
+			metric(row.Metrics, "change_pct", "%+.2f%%"),
+			metric(row.Metrics, "rsi", "%.1f"),
+			metric(row.Metrics, "vol_ratio", "%.2f"),
+			metric(row.Metrics, "ma60", "%.2f"),
+		})
+	}
+	doc.Table([]string{"标的", "现价", "涨跌", "RSI", "量比", "MA60"}, rows)
+	if len(r.Failed) > 0 {
+		doc.Note(fmt.Sprintf("%d 个标的数据不可用", len(r.Failed)))
+	}
+	return doc
+}
+
+func metric(m map[string]float64, key, format string) string {
+	v, ok := m[key]
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf(format, v)
+}
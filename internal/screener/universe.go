@@ -0,0 +1,92 @@
+package screener
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"investor/internal/symbols"
+)
+
+// Member is one security in a universe
+type Member struct {
+	Symbol string `json:"symbol"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Universes maps a universe name ("cn", "hk", "us", "all", or custom) to its members
+type Universes map[string][]Member
+
+// DefaultUniverses builds cn/hk/us/all from the stocks of the instrument master
+func DefaultUniverses() Universes {
+	u := Universes{}
+	for _, in := range symbols.Default().Instruments() {
+		if in.AssetClass != symbols.ClassStock {
+			continue
+		}
+		m := Member{Symbol: in.Code, Name: in.Name()}
+		u[marketOf(in.Code)] = append(u[marketOf(in.Code)], m)
+		u["all"] = append(u["all"], m)
+	}
+	for _, members := range u {
+		sort.Slice(members, func(i, j int) bool { return members[i].Symbol < members[j].Symbol })
+	}
+	return u
+}
+
+func marketOf(symbol string) string {
+	switch {
+	case strings.HasSuffix(symbol, ".SS"), strings.HasSuffix(symbol, ".SZ"):
+		return "cn"
+	case strings.HasSuffix(symbol, ".HK"):
+		return "hk"
+	}
+	return "us"
+}
+
+// LoadUniverses reads custom universes from a JSON file and merges them over the defaults.
+// Members can be given as plain symbols or {"symbol", "name"} objects:
+//
+//	{"semis": ["NVDA", "AMD", {"symbol": "688981.SS", "name": "中芯国际"}]}
+func LoadUniverses(path string) (Universes, error) {
+	u := DefaultUniverses()
+	if path == "" {
+		return u, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return u, err
+	}
+	var raw map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return u, fmt.Errorf("invalid universe file %s: %w", path, err)
+	}
+	for name, items := range raw {
+		var members []Member
+		for _, item := range items {
+			var m Member
+			if err := json.Unmarshal(item, &m.Symbol); err != nil {
+				if err := json.Unmarshal(item, &m); err != nil {
+					return u, fmt.Errorf("universe %s: invalid member %s", name, item)
+				}
+			}
+			if m.Symbol != "" {
+				members = append(members, m)
+			}
+		}
+		u[strings.ToLower(name)] = members
+	}
+	return u, nil
+}
+
+// Names lists the universe names with their sizes, e.g. "cn (52)"
+func (u Universes) Names() []string {
+	var names []string
+	for name, members := range u {
+		names = append(names, fmt.Sprintf("%s (%d)", name, len(members)))
+	}
+	sort.Strings(names)
+	return names
+}
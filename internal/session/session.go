@@ -2,30 +2,47 @@ package session
 
 import (
 	"context"
-	"sync"
 	"time"
 
 	"investor/internal/llm"
+	"investor/internal/store"
 )
 
-// Manager (In-Memory Version for local dev)
+// Manager keeps per-conversation history in the shared state store,
+// so it survives restarts when the store is file-backed.
 type Manager struct {
-	store sync.Map // map[string][]llm.Message
+	store store.Store
 	ttl   time.Duration
 }
 
-func NewManager() *Manager {
+type record struct {
+	Messages  []llm.Message `json:"messages"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+const keyPrefix = "session:"
+
+func NewManager(st store.Store) *Manager {
+	if st == nil {
+		st = store.NewMemoryStore()
+	}
 	return &Manager{
-		ttl: 24 * time.Hour,
+		store: st,
+		ttl:   24 * time.Hour,
 	}
 }
 
 func (s *Manager) GetHistory(ctx context.Context, sessionID string) ([]llm.Message, error) {
-	val, ok := s.store.Load(sessionID)
-	if !ok {
+	var rec record
+	ok, err := s.store.Get(ctx, keyPrefix+sessionID, &rec)
+	if err != nil || !ok {
+		return []llm.Message{}, err
+	}
+	// Expired conversations start fresh
+	if time.Since(rec.UpdatedAt) > s.ttl {
 		return []llm.Message{}, nil
 	}
-	return val.([]llm.Message), nil
+	return rec.Messages, nil
 }
 
 func (s *Manager) Append(ctx context.Context, sessionID string, msgs ...llm.Message) error {
@@ -41,6 +58,5 @@ func (s *Manager) Append(ctx context.Context, sessionID string, msgs ...llm.Mess
 	}
 
 	// 4. Save
-	s.store.Store(sessionID, history)
-	return nil
+	return s.store.Put(ctx, keyPrefix+sessionID, record{Messages: history, UpdatedAt: time.Now()})
 }
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// PendingTTL is how long a "which one did you mean?" question stays open
+const PendingTTL = 10 * time.Minute
+
+const choicePrefix = "symbols:"
+
+// Pending is a symbol question waiting for the user's answer
+type Pending struct {
+	Query     string    `json:"query"`   // what the user wrote, e.g. "阿里"
+	Text      string    `json:"text"`    // the original message, replayed once answered
+	Options   []string  `json:"options"` // candidate codes, in the order they were offered
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// symbolRecord holds a conversation's symbol choices. Choices outlive the
+// chat history: once "阿里" means BABA in a chat, it keeps meaning BABA.
+type symbolRecord struct {
+	Choices map[string]string `json:"choices,omitempty"` // normalized query -> code
+	Pending *Pending          `json:"pending,omitempty"`
+}
+
+func (s *Manager) symbols(ctx context.Context, sessionID string) (*symbolRecord, error) {
+	var rec symbolRecord
+	if _, err := s.store.Get(ctx, choicePrefix+sessionID, &rec); err != nil {
+		return nil, err
+	}
+	if rec.Choices == nil {
+		rec.Choices = make(map[string]string)
+	}
+	return &rec, nil
+}
+
+func choiceKey(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
+
+// Choice returns the code the user picked earlier for a query
+func (s *Manager) Choice(ctx context.Context, sessionID, query string) (string, bool) {
+	rec, err := s.symbols(ctx, sessionID)
+	if err != nil {
+		return "", false
+	}
+	code, ok := rec.Choices[choiceKey(query)]
+	return code, ok
+}
+
+// Remember records the user's pick for a query and closes the pending question
+func (s *Manager) Remember(ctx context.Context, sessionID, query, code string) error {
+	rec, err := s.symbols(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	rec.Choices[choiceKey(query)] = code
+	rec.Pending = nil
+	return s.store.Put(ctx, choicePrefix+sessionID, rec)
+}
+
+// GetPending returns the open question, nil if none or expired
+func (s *Manager) GetPending(ctx context.Context, sessionID string) (*Pending, error) {
+	rec, err := s.symbols(ctx, sessionID)
+	if err != nil || rec.Pending == nil {
+		return nil, err
+	}
+	if time.Since(rec.Pending.CreatedAt) > PendingTTL {
+		return nil, nil
+	}
+	return rec.Pending, nil
+}
+
+// SetPending opens a question; nil clears it
+func (s *Manager) SetPending(ctx context.Context, sessionID string, p *Pending) error {
+	rec, err := s.symbols(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if p != nil && p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	rec.Pending = p
+	return s.store.Put(ctx, choicePrefix+sessionID, rec)
+}
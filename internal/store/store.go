@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store is a small persistent key-value store for bot state
+// (sessions, alerts, watchlists, ...). Values are JSON-encoded.
+type Store interface {
+	// Get decodes the value at key into v. Returns false if the key does not exist.
+	Get(ctx context.Context, key string, v interface{}) (bool, error)
+	Put(ctx context.Context, key string, v interface{}) error
+	Delete(ctx context.Context, key string) error
+	// Keys lists keys with the given prefix, sorted
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// New returns a FileStore at path, or an in-memory store when path is empty
+func New(path string) (Store, error) {
+	if path == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewFileStore(path)
+}
+
+// MemoryStore keeps everything in memory (local dev, tests)
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string][]byte)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string, v interface{}) (bool, error) {
+	s.mu.RLock()
+	raw, ok := s.data[key]
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func (s *MemoryStore) Put(ctx context.Context, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.data[key] = raw
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.data, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Keys(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedKeys(s.data, prefix), nil
+}
+
+// FileStore is a MemoryStore snapshotted to a single JSON file on every write.
+// Good enough for a single bot instance; swap for Redis/SQL when scaling out.
+type FileStore struct {
+	MemoryStore
+	path    string
+	writeMu sync.Mutex
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: MemoryStore{data: make(map[string][]byte)},
+		path:        path,
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(raw) > 0 {
+		var snapshot map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &snapshot); err != nil {
+			return nil, fmt.Errorf("corrupt store file %s: %v", path, err)
+		}
+		for k, v := range snapshot {
+			s.data[k] = v
+		}
+	}
+	return s, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, key string, v interface{}) error {
+	if err := s.MemoryStore.Put(ctx, key, v); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	s.MemoryStore.Delete(ctx, key)
+	return s.flush()
+}
+
+// flush writes the snapshot atomically (temp file + rename)
+func (s *FileStore) flush() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	snapshot := make(map[string]json.RawMessage, len(s.data))
+	for k, v := range s.data {
+		snapshot[k] = v
+	}
+	s.mu.RUnlock()
+
+	raw, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func sortedKeys(data map[string][]byte, prefix string) []string {
+	var keys []string
+	for k := range data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
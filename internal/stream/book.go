@@ -0,0 +1,172 @@
+package stream
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/symbols"
+)
+
+// DefaultMaxAge is how long a streamed price is served before falling back to REST
+const DefaultMaxAge = time.Minute
+
+// Tick is the latest 24h ticker of one instrument
+type Tick struct {
+	Symbol    string    `json:"symbol"` // instrument master code, e.g. BTCUSDT
+	Price     float64   `json:"price"`
+	Open24h   float64   `json:"open_24h"`
+	High24h   float64   `json:"high_24h,omitempty"`
+	Low24h    float64   `json:"low_24h,omitempty"`
+	Volume24h float64   `json:"volume_24h,omitempty"`
+	Source    string    `json:"source"` // exchange name
+	Time      time.Time `json:"time"`
+}
+
+// ChangePct is the move against the 24h open
+func (t Tick) ChangePct() float64 {
+	if t.Open24h == 0 {
+		return 0
+	}
+	return (t.Price - t.Open24h) / t.Open24h * 100
+}
+
+// Quote converts the tick to the DataService shape
+func (t Tick) Quote() *dataservice.MarketQuote {
+	change := 0.0
+	if t.Open24h != 0 {
+		change = t.Price - t.Open24h
+	}
+	return &dataservice.MarketQuote{
+		Symbol:    t.Symbol,
+		Price:     t.Price,
+		Change:    change,
+		ChangePct: t.ChangePct(),
+		UpdatedAt: t.Time.Format(time.RFC3339),
+	}
+}
+
+// Book keeps the last tick per symbol and fans updates out to subscribers.
+// It implements dataservice.PriceBook.
+type Book struct {
+	MaxAge time.Duration
+
+	mu    sync.RWMutex
+	ticks map[string]Tick
+	subs  map[chan Tick]struct{}
+}
+
+func NewBook(maxAge time.Duration) *Book {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	return &Book{
+		MaxAge: maxAge,
+		ticks:  make(map[string]Tick),
+		subs:   make(map[chan Tick]struct{}),
+	}
+}
+
+// Update stores a tick and notifies subscribers. Slow subscribers miss
+// updates rather than blocking the feed.
+func (b *Book) Update(t Tick) {
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if old, ok := b.ticks[t.Symbol]; ok && old.Time.After(t.Time) {
+		return
+	}
+	b.ticks[t.Symbol] = t
+	for ch := range b.subs {
+		select {
+		case ch <- t:
+		default:
+		}
+	}
+}
+
+// Get returns the last tick for a symbol if it is fresher than MaxAge
+func (b *Book) Get(symbol string) (Tick, bool) {
+	b.mu.RLock()
+	t, ok := b.ticks[symbol]
+	b.mu.RUnlock()
+	if !ok || time.Since(t.Time) > b.MaxAge {
+		return Tick{}, false
+	}
+	return t, true
+}
+
+// Quote implements dataservice.PriceBook
+func (b *Book) Quote(symbol string) (*dataservice.MarketQuote, bool) {
+	code, ok := Canonical(symbol)
+	if !ok {
+		return nil, false
+	}
+	t, ok := b.Get(code)
+	if !ok {
+		return nil, false
+	}
+	return t.Quote(), true
+}
+
+// Snapshot returns the fresh ticks of the given symbols (all if none given)
+func (b *Book) Snapshot(symbols ...string) []Tick {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var out []Tick
+	if len(symbols) == 0 {
+		for _, t := range b.ticks {
+			if time.Since(t.Time) <= b.MaxAge {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	for _, sym := range symbols {
+		if t, ok := b.ticks[sym]; ok && time.Since(t.Time) <= b.MaxAge {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Subscribe returns a channel receiving every update until cancel is called
+func (b *Book) Subscribe(buffer int) (<-chan Tick, func()) {
+	ch := make(chan Tick, buffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Canonical maps user input (BTC, 比特币, BTC-USDT, btcusdt) to the master code
+// of a crypto pair. Only crypto is streamed; everything else returns false.
+func Canonical(symbol string) (string, bool) {
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return "", false
+	}
+	if res := symbols.Default().Resolve(symbol); res.Best != nil {
+		inst := res.Best.Instrument
+		if inst.AssetClass == symbols.ClassCrypto {
+			return inst.Code, true
+		}
+		return "", false
+	}
+	code := strings.ToUpper(strings.ReplaceAll(symbol, "-", ""))
+	if strings.HasSuffix(code, "USDT") && len(code) > len("USDT") {
+		return code, true
+	}
+	return "", false
+}
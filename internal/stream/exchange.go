@@ -0,0 +1,195 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"investor/internal/symbols"
+)
+
+// Exchange describes one public ticker WebSocket: where to connect, how to
+// subscribe and how to read its pushes.
+type Exchange interface {
+	Name() string
+	URL() string
+	// Subscribe returns the messages to send after connecting
+	Subscribe(codes []string) []interface{}
+	// Ping is the keep-alive text message, nil if the server pings us
+	Ping() []byte
+	// Parse turns one message into ticks; acks and pongs yield none
+	Parse(msg []byte) ([]Tick, error)
+}
+
+// ExchangeByName returns the built-in exchange, nil if unknown
+func ExchangeByName(name string) Exchange {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "okx":
+		return &OKX{}
+	case "binance":
+		return &Binance{}
+	}
+	return nil
+}
+
+// ticker returns the provider ticker from the instrument master, or derives it
+func ticker(code, provider string) string {
+	if inst, ok := symbols.Default().Lookup(code); ok {
+		if t := inst.Ticker(provider); t != "" && t != inst.Code {
+			return t
+		}
+	}
+	if provider == "okx" && strings.HasSuffix(code, "USDT") {
+		return strings.TrimSuffix(code, "USDT") + "-USDT"
+	}
+	return code
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// OKX streams the v5 public "tickers" channel
+type OKX struct {
+	Endpoint string // defaults to the production public endpoint
+}
+
+func (e *OKX) Name() string { return "okx" }
+
+func (e *OKX) URL() string {
+	if e.Endpoint != "" {
+		return e.Endpoint
+	}
+	return "wss://ws.okx.com:8443/ws/v5/public"
+}
+
+func (e *OKX) Subscribe(codes []string) []interface{} {
+	type arg struct {
+		Channel string `json:"channel"`
+		InstID  string `json:"instId"`
+	}
+	args := make([]arg, len(codes))
+	for i, code := range codes {
+		args[i] = arg{Channel: "tickers", InstID: ticker(code, "okx")}
+	}
+	return []interface{}{map[string]interface{}{"op": "subscribe", "args": args}}
+}
+
+// Ping keeps the connection alive: OKX drops it after 30s without traffic
+func (e *OKX) Ping() []byte { return []byte("ping") }
+
+func (e *OKX) Parse(msg []byte) ([]Tick, error) {
+	if string(msg) == "pong" {
+		return nil, nil
+	}
+	var push struct {
+		Event string `json:"event"`
+		Code  string `json:"code"`
+		Msg   string `json:"msg"`
+		Data  []struct {
+			InstID  string `json:"instId"`
+			Last    string `json:"last"`
+			Open24h string `json:"open24h"`
+			High24h string `json:"high24h"`
+			Low24h  string `json:"low24h"`
+			Vol24h  string `json:"vol24h"`
+			Ts      string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &push); err != nil {
+		return nil, err
+	}
+	if push.Event == "error" {
+		return nil, fmt.Errorf("okx: %s %s", push.Code, push.Msg)
+	}
+
+	ticks := make([]Tick, 0, len(push.Data))
+	for _, d := range push.Data {
+		t := Tick{
+			Symbol:    strings.ReplaceAll(strings.ToUpper(d.InstID), "-", ""),
+			Price:     parseFloat(d.Last),
+			Open24h:   parseFloat(d.Open24h),
+			High24h:   parseFloat(d.High24h),
+			Low24h:    parseFloat(d.Low24h),
+			Volume24h: parseFloat(d.Vol24h),
+			Source:    e.Name(),
+		}
+		if ms, err := strconv.ParseInt(d.Ts, 10, 64); err == nil {
+			t.Time = time.UnixMilli(ms)
+		}
+		if t.Price > 0 {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, nil
+}
+
+// Binance streams "<symbol>@ticker" 24h rolling tickers
+type Binance struct {
+	Endpoint string // defaults to the production stream endpoint
+}
+
+func (e *Binance) Name() string { return "binance" }
+
+func (e *Binance) URL() string {
+	if e.Endpoint != "" {
+		return e.Endpoint
+	}
+	return "wss://stream.binance.com:9443/ws"
+}
+
+func (e *Binance) Subscribe(codes []string) []interface{} {
+	params := make([]string, len(codes))
+	for i, code := range codes {
+		params[i] = strings.ToLower(ticker(code, "binance")) + "@ticker"
+	}
+	return []interface{}{map[string]interface{}{"method": "SUBSCRIBE", "params": params, "id": 1}}
+}
+
+// Ping is not needed: Binance pings and gorilla answers with a pong
+func (e *Binance) Ping() []byte { return nil }
+
+func (e *Binance) Parse(msg []byte) ([]Tick, error) {
+	var push struct {
+		Event  string `json:"e"`
+		Time   int64  `json:"E"`
+		Symbol string `json:"s"`
+		Last   string `json:"c"`
+		Open   string `json:"o"`
+		High   string `json:"h"`
+		Low    string `json:"l"`
+		Volume string `json:"v"`
+		Error  *struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &push); err != nil {
+		return nil, err
+	}
+	if push.Error != nil {
+		return nil, fmt.Errorf("binance: %d %s", push.Error.Code, push.Error.Msg)
+	}
+	if push.Event != "24hrTicker" {
+		return nil, nil // subscription ack
+	}
+	t := Tick{
+		Symbol:    strings.ToUpper(push.Symbol),
+		Price:     parseFloat(push.Last),
+		Open24h:   parseFloat(push.Open),
+		High24h:   parseFloat(push.High),
+		Low24h:    parseFloat(push.Low),
+		Volume24h: parseFloat(push.Volume),
+		Source:    e.Name(),
+	}
+	if push.Time > 0 {
+		t.Time = time.UnixMilli(push.Time)
+	}
+	if t.Price <= 0 {
+		return nil, nil
+	}
+	return []Tick{t}, nil
+}
@@ -0,0 +1,199 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Feed keeps one WebSocket connection to an exchange subscribed to the current
+// symbol set, writing every tick to the book. It reconnects with exponential
+// backoff and resubscribes whenever the symbol set changes.
+type Feed struct {
+	Exchange Exchange
+	Book     *Book
+	Logger   *zap.Logger
+	Dialer   *websocket.Dialer
+
+	MinBackoff   time.Duration // first retry delay, doubled up to MaxBackoff
+	MaxBackoff   time.Duration
+	PingInterval time.Duration // how often Exchange.Ping is sent
+	ReadTimeout  time.Duration // reconnect if nothing arrives for this long
+
+	mu      sync.Mutex
+	symbols []string
+	changed chan struct{}
+}
+
+func NewFeed(ex Exchange, book *Book, logger *zap.Logger) *Feed {
+	return &Feed{
+		Exchange:     ex,
+		Book:         book,
+		Logger:       logger,
+		Dialer:       websocket.DefaultDialer,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Minute,
+		PingInterval: 20 * time.Second,
+		ReadTimeout:  90 * time.Second,
+		changed:      make(chan struct{}, 1),
+	}
+}
+
+// errResubscribe ends a session because the symbol set changed
+var errResubscribe = errors.New("symbol set changed")
+
+// SetSymbols replaces the subscribed set (master codes); the connection is
+// re-established only if it actually differs.
+func (f *Feed) SetSymbols(codes []string) {
+	codes = append([]string(nil), codes...)
+	sort.Strings(codes)
+
+	f.mu.Lock()
+	same := len(codes) == len(f.symbols)
+	for i := 0; same && i < len(codes); i++ {
+		same = codes[i] == f.symbols[i]
+	}
+	f.symbols = codes
+	f.mu.Unlock()
+
+	if !same {
+		select {
+		case f.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Symbols returns the current subscription set
+func (f *Feed) Symbols() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.symbols...)
+}
+
+// Run connects and reconnects until ctx is cancelled
+func (f *Feed) Run(ctx context.Context) {
+	backoff := f.MinBackoff
+	for {
+		// The set read below is current, so an earlier change needs no resubscribe
+		select {
+		case <-f.changed:
+		default:
+		}
+		codes := f.Symbols()
+		if len(codes) == 0 {
+			// Nothing to watch: idle until symbols arrive
+			select {
+			case <-ctx.Done():
+				return
+			case <-f.changed:
+				continue
+			}
+		}
+
+		received, err := f.session(ctx, codes)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = f.MinBackoff
+		}
+		if errors.Is(err, errResubscribe) {
+			continue
+		}
+
+		f.Logger.Warn("Market stream disconnected",
+			zap.String("exchange", f.Exchange.Name()), zap.Duration("retry_in", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > f.MaxBackoff {
+			backoff = f.MaxBackoff
+		}
+	}
+}
+
+// session runs one connection. It reports whether any tick arrived, so a
+// connection that worked for a while restarts the backoff.
+func (f *Feed) session(ctx context.Context, codes []string) (bool, error) {
+	conn, _, err := f.Dialer.DialContext(ctx, f.Exchange.URL(), nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	for _, msg := range f.Exchange.Subscribe(codes) {
+		if err := conn.WriteJSON(msg); err != nil {
+			return false, err
+		}
+	}
+	f.Logger.Info("Market stream connected",
+		zap.String("exchange", f.Exchange.Name()), zap.Int("symbols", len(codes)))
+
+	// Closing the connection unblocks the read loop on shutdown or resubscribe
+	done := make(chan struct{})
+	defer close(done)
+	var resubscribe bool
+	var mu sync.Mutex
+	go func() {
+		var ping <-chan time.Time
+		if f.Exchange.Ping() != nil && f.PingInterval > 0 {
+			t := time.NewTicker(f.PingInterval)
+			defer t.Stop()
+			ping = t.C
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-f.changed:
+				mu.Lock()
+				resubscribe = true
+				mu.Unlock()
+				conn.Close()
+				return
+			case <-ping:
+				conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				if err := conn.WriteMessage(websocket.TextMessage, f.Exchange.Ping()); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	received := false
+	for {
+		if f.ReadTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(f.ReadTimeout))
+		}
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			if resubscribe {
+				return received, errResubscribe
+			}
+			return received, err
+		}
+		ticks, err := f.Exchange.Parse(msg)
+		if err != nil {
+			f.Logger.Debug("Market stream message skipped", zap.String("exchange", f.Exchange.Name()), zap.Error(err))
+			continue
+		}
+		for _, t := range ticks {
+			f.Book.Update(t)
+			received = true
+		}
+	}
+}
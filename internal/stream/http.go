@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeat keeps proxies from closing an idle event stream
+const heartbeat = 15 * time.Second
+
+// RegisterRoutes mounts the live quote stream (server-sent events). It starts
+// with the latest known tick of each symbol, then pushes every update.
+//
+//	GET /stream/quotes?symbols=BTC,ETHUSDT,比特币
+//
+//	event: quote
+//	data: {"symbol":"BTCUSDT","price":64000.5,"open_24h":63000,...}
+func RegisterRoutes(r gin.IRouter, m *Manager) {
+	r.GET("/stream/quotes", func(c *gin.Context) {
+		var codes []string
+		want := make(map[string]bool)
+		for _, sym := range strings.Split(c.Query("symbols"), ",") {
+			if code, ok := Canonical(sym); ok && !want[code] {
+				want[code] = true
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "symbols must list at least one crypto pair, e.g. symbols=BTC,ETH"})
+			return
+		}
+
+		release := m.Acquire(codes...)
+		defer release()
+		updates, cancel := m.Book.Subscribe(64)
+		defer cancel()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		for _, t := range m.Book.Snapshot(codes...) {
+			c.SSEvent("quote", t)
+		}
+		c.Writer.Flush()
+
+		ping := time.NewTicker(heartbeat)
+		defer ping.Stop()
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case t := <-updates:
+				if want[t.Symbol] {
+					c.SSEvent("quote", t)
+				}
+			case <-ping.C:
+				io.WriteString(w, ": ping\n\n")
+			}
+			return true
+		})
+	})
+}
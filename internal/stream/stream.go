@@ -0,0 +1,176 @@
+// Package stream keeps a live last-price book for crypto pairs from the
+// exchanges' public WebSocket tickers. The book serves quotes (it plugs into
+// the Yahoo service as its PriceBook), wakes up price alerts and is
+// re-published to clients over server-sent events.
+package stream
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SymbolSource lists symbols worth streaming (watchlists, alerts, ...), as typed by users
+type SymbolSource func(ctx context.Context) ([]string, error)
+
+// Manager decides what to subscribe: the symbols of all sources plus the ones
+// SSE clients are currently listening to, refreshed every Refresh.
+type Manager struct {
+	Book    *Book
+	Feeds   []*Feed
+	Logger  *zap.Logger
+	Refresh time.Duration
+
+	mu      sync.Mutex
+	sources []SymbolSource
+	clients map[string]int // code -> listening SSE clients
+	watched []string       // codes from the sources at the last refresh
+}
+
+func NewManager(book *Book, logger *zap.Logger, feeds ...*Feed) *Manager {
+	return &Manager{
+		Book:    book,
+		Feeds:   feeds,
+		Logger:  logger,
+		Refresh: time.Minute,
+		clients: make(map[string]int),
+	}
+}
+
+// Watch adds a symbol source
+func (m *Manager) Watch(src SymbolSource) {
+	m.mu.Lock()
+	m.sources = append(m.sources, src)
+	m.mu.Unlock()
+}
+
+// Acquire subscribes the given codes for as long as a client listens; call
+// the returned func when it goes away.
+func (m *Manager) Acquire(codes ...string) func() {
+	m.mu.Lock()
+	for _, c := range codes {
+		m.clients[c]++
+	}
+	m.mu.Unlock()
+	m.apply()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			for _, c := range codes {
+				if m.clients[c]--; m.clients[c] <= 0 {
+					delete(m.clients, c)
+				}
+			}
+			m.mu.Unlock()
+			m.apply()
+		})
+	}
+}
+
+// Symbols is the current subscription set
+func (m *Manager) Symbols() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	set := make(map[string]bool)
+	for _, c := range m.watched {
+		set[c] = true
+	}
+	for c := range m.clients {
+		set[c] = true
+	}
+	codes := make([]string, 0, len(set))
+	for c := range set {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Run starts the feeds and refreshes the symbol set until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	for _, f := range m.Feeds {
+		go f.Run(ctx)
+	}
+	m.Logger.Info("Market stream started", zap.Int("feeds", len(m.Feeds)))
+
+	ticker := time.NewTicker(m.Refresh)
+	defer ticker.Stop()
+	for {
+		m.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) refresh(ctx context.Context) {
+	m.mu.Lock()
+	sources := append([]SymbolSource(nil), m.sources...)
+	m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var codes []string
+	for _, src := range sources {
+		list, err := src(ctx)
+		if err != nil {
+			m.Logger.Warn("Market stream symbol source failed", zap.Error(err))
+			continue
+		}
+		for _, sym := range list {
+			if code, ok := Canonical(sym); ok && !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.watched = codes
+	m.mu.Unlock()
+	m.apply()
+}
+
+func (m *Manager) apply() {
+	codes := m.Symbols()
+	for _, f := range m.Feeds {
+		f.SetSymbols(codes)
+	}
+}
+
+// OnUpdate calls fn at most once per interval with the symbols that ticked
+// since the last call. Used to evaluate price alerts on live prices without
+// running them on every tick.
+func (m *Manager) OnUpdate(ctx context.Context, interval time.Duration, fn func(codes []string)) {
+	ch, cancel := m.Book.Subscribe(256)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pending := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ch:
+			pending[t.Symbol] = true
+		case <-ticker.C:
+			if len(pending) == 0 {
+				continue
+			}
+			codes := make([]string, 0, len(pending))
+			for c := range pending {
+				codes = append(codes, c)
+			}
+			sort.Strings(codes)
+			pending = make(map[string]bool)
+			fn(codes)
+		}
+	}
+}
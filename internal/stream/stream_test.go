@@ -0,0 +1,332 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// wsServer is a stand-in exchange: it records each connection's subscribe
+// message and hands the connection to serve.
+type wsServer struct {
+	*httptest.Server
+	conns atomic.Int32
+
+	mu   sync.Mutex
+	subs []string
+}
+
+func newWSServer(t *testing.T, serve func(n int32, conn *websocket.Conn)) *wsServer {
+	s := &wsServer{}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		n := s.conns.Add(1)
+
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.subs = append(s.subs, string(msg))
+		s.mu.Unlock()
+		serve(n, conn)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *wsServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *wsServer) lastSub() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) == 0 {
+		return ""
+	}
+	return s.subs[len(s.subs)-1]
+}
+
+func testFeed(ex Exchange, book *Book) *Feed {
+	f := NewFeed(ex, book, zap.NewNop())
+	f.MinBackoff = 10 * time.Millisecond
+	f.MaxBackoff = 50 * time.Millisecond
+	f.ReadTimeout = 2 * time.Second
+	return f
+}
+
+// waitFor polls cond until it holds or the deadline passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func okxPush(instID, last, open string) string {
+	return `{"arg":{"channel":"tickers","instId":"` + instID + `"},"data":[{"instId":"` + instID +
+		`","last":"` + last + `","open24h":"` + open + `","high24h":"70000","low24h":"60000","vol24h":"1234","ts":"` +
+		jsonMillis(time.Now()) + `"}]}`
+}
+
+func jsonMillis(t time.Time) string {
+	b, _ := json.Marshal(t.UnixMilli())
+	return string(b)
+}
+
+func TestOKXFeedSubscribesAndReconnects(t *testing.T) {
+	srv := newWSServer(t, func(n int32, conn *websocket.Conn) {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"subscribe","arg":{"channel":"tickers","instId":"BTC-USDT"}}`))
+		if n == 1 {
+			// First connection drops right after one tick
+			conn.WriteMessage(websocket.TextMessage, []byte(okxPush("BTC-USDT", "64000", "62000")))
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(okxPush("BTC-USDT", "65000", "62000")))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	book := NewBook(0)
+	feed := testFeed(&OKX{Endpoint: srv.url()}, book)
+	feed.SetSymbols([]string{"BTCUSDT"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feed.Run(ctx)
+
+	waitFor(t, "reconnected tick", func() bool {
+		tk, ok := book.Get("BTCUSDT")
+		return ok && tk.Price == 65000
+	})
+	if n := srv.conns.Load(); n < 2 {
+		t.Fatalf("connections = %d, want a reconnect", n)
+	}
+
+	var sub struct {
+		Op   string `json:"op"`
+		Args []struct {
+			Channel string `json:"channel"`
+			InstID  string `json:"instId"`
+		} `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(srv.lastSub()), &sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Op != "subscribe" || len(sub.Args) != 1 || sub.Args[0].Channel != "tickers" || sub.Args[0].InstID != "BTC-USDT" {
+		t.Fatalf("unexpected subscribe message: %s", srv.lastSub())
+	}
+
+	tk, _ := book.Get("BTCUSDT")
+	if tk.Source != "okx" || tk.Open24h != 62000 || tk.High24h != 70000 {
+		t.Fatalf("unexpected tick: %+v", tk)
+	}
+	q, ok := book.Quote("BTC")
+	if !ok || q.Symbol != "BTCUSDT" || q.Price != 65000 || q.Change != 3000 {
+		t.Fatalf("Quote(BTC) = %+v, %v", q, ok)
+	}
+}
+
+func TestBinanceFeedResubscribesOnChange(t *testing.T) {
+	srv := newWSServer(t, func(n int32, conn *websocket.Conn) {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"result":null,"id":1}`))
+		for _, sym := range []string{"BTCUSDT", "ETHUSDT"} {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"e":"24hrTicker","E":`+jsonMillis(time.Now())+
+				`,"s":"`+sym+`","c":"100.5","o":"100","h":"101","l":"99","v":"10"}`))
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	book := NewBook(0)
+	feed := testFeed(&Binance{Endpoint: srv.url()}, book)
+	feed.SetSymbols([]string{"BTCUSDT"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feed.Run(ctx)
+
+	waitFor(t, "first tick", func() bool { _, ok := book.Get("BTCUSDT"); return ok })
+	if !strings.Contains(srv.lastSub(), `"btcusdt@ticker"`) || !strings.Contains(srv.lastSub(), `"SUBSCRIBE"`) {
+		t.Fatalf("unexpected subscribe message: %s", srv.lastSub())
+	}
+
+	// Same set: no reconnect; new set: one more connection with both streams
+	feed.SetSymbols([]string{"BTCUSDT"})
+	feed.SetSymbols([]string{"ETHUSDT", "BTCUSDT"})
+	waitFor(t, "resubscribe", func() bool { return strings.Contains(srv.lastSub(), `"ethusdt@ticker"`) })
+	if n := srv.conns.Load(); n != 2 {
+		t.Fatalf("connections = %d, want 2", n)
+	}
+	tk, ok := book.Get("ETHUSDT")
+	if !ok || tk.Source != "binance" || tk.Price != 100.5 {
+		t.Fatalf("ETHUSDT tick = %+v, %v", tk, ok)
+	}
+}
+
+func TestFeedBacksOffWhenUnreachable(t *testing.T) {
+	var dials atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dials.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	feed := testFeed(&OKX{Endpoint: "ws" + strings.TrimPrefix(srv.URL, "http")}, NewBook(0))
+	feed.MinBackoff = 20 * time.Millisecond
+	feed.MaxBackoff = 80 * time.Millisecond
+	feed.SetSymbols([]string{"BTCUSDT"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	feed.Run(ctx)
+
+	// 20+40+80+80 ms of waits fit in 300ms: a handful of dials, not a busy loop
+	if n := dials.Load(); n < 2 || n > 8 {
+		t.Fatalf("dials = %d, want a few with backoff", n)
+	}
+}
+
+func TestBookFreshnessAndSubscribers(t *testing.T) {
+	book := NewBook(time.Minute)
+	ch, cancel := book.Subscribe(4)
+
+	book.Update(Tick{Symbol: "ETHUSDT", Price: 3000, Open24h: 2900, Time: time.Now()})
+	book.Update(Tick{Symbol: "ETHUSDT", Price: 2000, Time: time.Now().Add(-time.Hour)}) // out of order, ignored
+	book.Update(Tick{Symbol: "SOLUSDT", Price: 150, Time: time.Now().Add(-2 * time.Minute)})
+
+	if tk := <-ch; tk.Price != 3000 {
+		t.Fatalf("first update = %+v", tk)
+	}
+	if tk, ok := book.Get("ETHUSDT"); !ok || tk.Price != 3000 {
+		t.Fatalf("ETHUSDT = %+v, %v", tk, ok)
+	}
+	if _, ok := book.Get("SOLUSDT"); ok {
+		t.Fatal("stale tick should not be served")
+	}
+	if got := book.Snapshot(); len(got) != 1 {
+		t.Fatalf("Snapshot() = %+v", got)
+	}
+
+	cancel()
+	book.Update(Tick{Symbol: "ETHUSDT", Price: 3100, Time: time.Now()})
+	select {
+	case tk := <-ch:
+		if tk.Symbol != "SOLUSDT" {
+			t.Fatalf("update after cancel: %+v", tk)
+		}
+	default:
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for in, want := range map[string]string{
+		"BTC":      "BTCUSDT",
+		"比特币":      "BTCUSDT",
+		"eth-usdt": "ETHUSDT",
+		"PEPEUSDT": "PEPEUSDT",
+	} {
+		if got, ok := Canonical(in); !ok || got != want {
+			t.Errorf("Canonical(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"AAPL", "茅台", "", "USDT"} {
+		if got, ok := Canonical(in); ok {
+			t.Errorf("Canonical(%q) = %q, want not streamable", in, got)
+		}
+	}
+}
+
+func TestManagerSymbolsAndSSE(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	book := NewBook(0)
+	feed := testFeed(&OKX{}, book)
+	m := NewManager(book, zap.NewNop(), feed)
+	m.Watch(func(ctx context.Context) ([]string, error) { return []string{"BTC", "AAPL", "btcusdt"}, nil })
+	m.refresh(context.Background())
+	if got := feed.Symbols(); len(got) != 1 || got[0] != "BTCUSDT" {
+		t.Fatalf("feed symbols = %v", got)
+	}
+
+	book.Update(Tick{Symbol: "ETHUSDT", Price: 3000, Open24h: 3000, Source: "okx"})
+
+	r := gin.New()
+	RegisterRoutes(r, m)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stream/quotes?symbols=AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("non-crypto symbols: status %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/stream/quotes?symbols=ETH,SOL", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// The client's symbols are subscribed while it listens
+	waitFor(t, "client subscription", func() bool { return len(feed.Symbols()) == 3 })
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() string {
+		for lines.Scan() {
+			if l := lines.Text(); strings.HasPrefix(l, "data:") {
+				return l
+			}
+		}
+		t.Fatal("stream ended")
+		return ""
+	}
+	if l := next(); !strings.Contains(l, `"symbol":"ETHUSDT"`) {
+		t.Fatalf("snapshot event = %s", l)
+	}
+	book.Update(Tick{Symbol: "BTCUSDT", Price: 1, Source: "okx"}) // not requested
+	book.Update(Tick{Symbol: "SOLUSDT", Price: 150, Open24h: 140, Source: "okx"})
+	if l := next(); !strings.Contains(l, `"symbol":"SOLUSDT"`) || !strings.Contains(l, `"price":150`) {
+		t.Fatalf("update event = %s", l)
+	}
+
+	cancel()
+	waitFor(t, "client release", func() bool { return len(feed.Symbols()) == 1 })
+}
@@ -0,0 +1,228 @@
+package symbols
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match scores, 0-100
+const (
+	ScoreCode     = 100 // code or provider ticker
+	ScoreName     = 95  // name or alias
+	MinScore      = 60  // below this a candidate is not reported
+	AmbiguityGap  = 10  // candidates closer than this to the best are ambiguous
+	maxCandidates = 5
+)
+
+// What a key of the index is
+const (
+	keyCode = iota
+	keyName
+)
+
+type matchKey struct {
+	text  string // normalized
+	kind  int
+	index int // into Master.instruments
+}
+
+// Match is a scored candidate
+type Match struct {
+	Instrument *Instrument `json:"instrument"`
+	Score      int         `json:"score"`
+	Matched    string      `json:"matched"` // the name, alias or code that matched
+}
+
+// Resolution is the outcome of resolving user input
+type Resolution struct {
+	Query      string  `json:"query"`
+	Best       *Match  `json:"best,omitempty"`
+	Candidates []Match `json:"candidates,omitempty"` // best first, Best included
+	Ambiguous  bool    `json:"ambiguous"`            // another candidate is within AmbiguityGap of Best
+}
+
+// Code is the resolved code, empty if nothing matched
+func (r Resolution) Code() string {
+	if r.Best == nil {
+		return ""
+	}
+	return r.Best.Instrument.Code
+}
+
+// Describe lists the candidates as "1. 中芯国际 (688981.SS, SSE)" lines
+func (r Resolution) Describe() string {
+	var b strings.Builder
+	for i, c := range r.Candidates {
+		fmt.Fprintf(&b, "%d. %s (%s", i+1, c.Instrument.Name(), c.Instrument.Code)
+		if c.Instrument.Exchange != "" {
+			b.WriteString(", " + c.Instrument.Exchange)
+		}
+		b.WriteString(")\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// normalize lowercases and drops spaces, '_' and '-' so "Heating Oil",
+// "heating_oil" and "heating-oil" compare equal
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case unicode.IsSpace(r), r == '_', r == '-':
+			continue
+		case r >= 'Ａ' && r <= 'ｚ': // full-width ASCII
+			r -= 'Ａ' - 'A'
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyOK reports whether a string is long enough for partial matching:
+// short Latin strings ("PL", "ES") are tickers, single CJK characters ("铜") are too vague
+func fuzzyOK(s string) bool {
+	if isASCII(s) {
+		return len(s) >= 4
+	}
+	return utf8.RuneCountInString(s) >= 2
+}
+
+// reindex rebuilds the search keys; callers hold the write lock
+func (m *Master) reindex() {
+	m.keys = m.keys[:0]
+	for i, in := range m.instruments {
+		add := func(text string, kind int) {
+			if n := normalize(text); n != "" {
+				m.keys = append(m.keys, matchKey{text: n, kind: kind, index: i})
+			}
+		}
+		add(in.Code, keyCode)
+		for _, t := range in.Tickers {
+			add(t, keyCode)
+		}
+		add(in.NameCN, keyName)
+		add(in.NameEN, keyName)
+		for _, a := range in.Aliases {
+			add(a, keyName)
+		}
+	}
+}
+
+// score rates how well query q matches key k (both normalized), 0 = no match
+func score(q string, k matchKey) int {
+	if q == k.text {
+		if k.kind == keyCode {
+			return ScoreCode
+		}
+		return ScoreName
+	}
+	if k.kind == keyCode || !fuzzyOK(q) {
+		return 0
+	}
+	ql, kl := utf8.RuneCountInString(q), utf8.RuneCountInString(k.text)
+	switch {
+	case strings.HasPrefix(k.text, q): // "nvid" -> "nvidia"
+		return 70 + 20*ql/kl
+	case strings.Contains(k.text, q): // "茅台" -> "贵州茅台"
+		return 50 + 20*ql/kl
+	case fuzzyOK(k.text) && strings.Contains(q, k.text): // "茅台股价" -> "茅台"
+		return 50 + 20*kl/ql
+	}
+	return 0
+}
+
+// Search returns up to limit instruments scoring at least MinScore, best first.
+// Ties keep master order, so results are deterministic.
+func (m *Master) Search(query string, limit int) []Match {
+	q := normalize(query)
+	if q == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	best := make(map[int]Match)
+	for _, k := range m.keys {
+		s := score(q, k)
+		if s < MinScore || s <= best[k.index].Score {
+			continue
+		}
+		best[k.index] = Match{Instrument: m.instruments[k.index], Score: s, Matched: k.text}
+	}
+
+	order := make([]int, 0, len(best))
+	for i := range best {
+		order = append(order, i)
+	}
+	sort.Slice(order, func(a, b int) bool {
+		sa, sb := best[order[a]].Score, best[order[b]].Score
+		if sa != sb {
+			return sa > sb
+		}
+		return order[a] < order[b]
+	})
+
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+	matches := make([]Match, len(order))
+	for i, idx := range order {
+		matches[i] = best[idx]
+	}
+	return matches
+}
+
+// Resolve picks the best instrument for the input and flags ambiguity
+func (m *Master) Resolve(query string) Resolution {
+	r := Resolution{Query: query, Candidates: m.Search(query, maxCandidates)}
+	if len(r.Candidates) == 0 {
+		return r
+	}
+	r.Best = &r.Candidates[0]
+	r.Ambiguous = len(r.Candidates) > 1 && r.Candidates[0].Score-r.Candidates[1].Score < AmbiguityGap
+	// Only report the competing candidates
+	if !r.Ambiguous {
+		r.Candidates = r.Candidates[:1]
+	}
+	return r
+}
+
+var (
+	aShareRe = regexp.MustCompile(`^\d{6}$`)
+	hkRe     = regexp.MustCompile(`^\d{4,5}$`)
+)
+
+// GuessCode derives an exchange suffix from a bare numeric code:
+// 6xxxxx -> .SS, 0xxxxx/3xxxxx -> .SZ, 4-5 digits -> .HK (0700, 09988)
+func GuessCode(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	switch {
+	case aShareRe.MatchString(s):
+		switch s[0] {
+		case '6':
+			return s + ".SS", true
+		case '0', '3':
+			return s + ".SZ", true
+		}
+	case hkRe.MatchString(s):
+		// Yahoo pads to 4 digits: 09988 -> 9988.HK, 0700 -> 0700.HK
+		if n := strings.TrimLeft(s, "0"); n != "" && len(n) <= 4 {
+			return fmt.Sprintf("%04s.HK", n), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,259 @@
+// Package symbols resolves user input ("茅台", "btc", "Apple", "0700") to
+// instruments of a loadable instrument master.
+package symbols
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Asset classes
+const (
+	ClassStock  = "stock"
+	ClassIndex  = "index"
+	ClassCrypto = "crypto"
+	ClassForex  = "forex"
+	ClassFuture = "future"
+	ClassETF    = "etf"
+)
+
+// Instrument is one entry of the master. Code is the canonical symbol used
+// across the bot; Tickers holds provider specific symbols ("yahoo": "BTC-USD").
+type Instrument struct {
+	Code       string            `json:"code"`
+	NameCN     string            `json:"name_cn,omitempty"`
+	NameEN     string            `json:"name_en,omitempty"`
+	Aliases    []string          `json:"aliases,omitempty"`
+	Exchange   string            `json:"exchange,omitempty"`
+	AssetClass string            `json:"asset_class,omitempty"`
+	Currency   string            `json:"currency,omitempty"`
+	Tickers    map[string]string `json:"tickers,omitempty"`
+}
+
+// Ticker returns the provider's symbol, falling back to Code
+func (i *Instrument) Ticker(provider string) string {
+	if t, ok := i.Tickers[provider]; ok && t != "" {
+		return t
+	}
+	return i.Code
+}
+
+// Name returns the Chinese name, else the English one, else the code
+func (i *Instrument) Name() string {
+	if i.NameCN != "" {
+		return i.NameCN
+	}
+	if i.NameEN != "" {
+		return i.NameEN
+	}
+	return i.Code
+}
+
+// Master is an ordered, indexed set of instruments. Order matters: when two
+// instruments match equally well, the earlier one wins.
+type Master struct {
+	mu          sync.RWMutex
+	instruments []*Instrument
+	byCode      map[string]int
+	keys        []matchKey // every searchable string, pre-normalized
+}
+
+// NewMaster indexes the instruments; codes must be unique
+func NewMaster(list []Instrument) (*Master, error) {
+	m := &Master{byCode: make(map[string]int)}
+	if err := m.Merge(list...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Merge adds instruments, replacing existing ones with the same code in place
+func (m *Master) Merge(list ...Instrument) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, in := range list {
+		in := in
+		in.Code = strings.TrimSpace(in.Code)
+		if in.Code == "" {
+			return fmt.Errorf("instrument without code (%s)", in.Name())
+		}
+		code := strings.ToUpper(in.Code)
+		if seen[code] {
+			return fmt.Errorf("duplicate instrument code %s", in.Code)
+		}
+		seen[code] = true
+		if i, ok := m.byCode[code]; ok {
+			m.instruments[i] = &in
+			continue
+		}
+		m.byCode[code] = len(m.instruments)
+		m.instruments = append(m.instruments, &in)
+	}
+	m.reindex()
+	return nil
+}
+
+// Lookup finds an instrument by exact code (case-insensitive)
+func (m *Master) Lookup(code string) (*Instrument, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return nil, false
+	}
+	return m.instruments[i], true
+}
+
+// Instruments returns all instruments in master order
+func (m *Master) Instruments() []*Instrument {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*Instrument(nil), m.instruments...)
+}
+
+// Len is the number of instruments
+func (m *Master) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.instruments)
+}
+
+//go:embed instruments.json
+var embedded []byte
+
+var (
+	defaultOnce   sync.Once
+	defaultMaster *Master
+)
+
+// Default returns the process-wide master, seeded from the embedded
+// instruments.json. Extend it with Merge (see Load).
+func Default() *Master {
+	defaultOnce.Do(func() {
+		list, err := ParseJSON(bytes.NewReader(embedded))
+		if err == nil {
+			defaultMaster, err = NewMaster(list)
+		}
+		if err != nil {
+			panic("symbols: invalid embedded master: " + err.Error())
+		}
+	})
+	return defaultMaster
+}
+
+// Load reads instruments from a .json or .csv file
+func Load(path string) ([]Instrument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return ParseCSV(f)
+	}
+	return ParseJSON(f)
+}
+
+// MergeFile loads a .json or .csv master file into the Default master.
+// Entries with an existing code replace the embedded ones.
+func MergeFile(path string) error {
+	list, err := Load(path)
+	if err != nil {
+		return err
+	}
+	return Default().Merge(list...)
+}
+
+// ParseJSON reads an array of instruments
+func ParseJSON(r io.Reader) ([]Instrument, error) {
+	var list []Instrument
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// csvColumns are the recognized header names. Aliases are separated by "|",
+// tickers are "provider:ticker" pairs separated by "|":
+//
+//	code,name_cn,name_en,aliases,exchange,asset_class,currency,tickers
+//	BTCUSDT,比特币,Bitcoin,btc|大饼,CRYPTO,crypto,USDT,yahoo:BTC-USD|okx:BTC-USDT
+var csvColumns = []string{"code", "name_cn", "name_en", "aliases", "exchange", "asset_class", "currency", "tickers"}
+
+// ParseCSV reads instruments from a CSV with a header row; columns may be in
+// any order and only "code" is required.
+func ParseCSV(r io.Reader) ([]Instrument, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := col["code"]; !ok {
+		return nil, fmt.Errorf("csv header has no code column (expected %s)", strings.Join(csvColumns, ","))
+	}
+
+	var list []Instrument
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		in := Instrument{
+			Code:       get("code"),
+			NameCN:     get("name_cn"),
+			NameEN:     get("name_en"),
+			Aliases:    splitList(get("aliases")),
+			Exchange:   get("exchange"),
+			AssetClass: get("asset_class"),
+			Currency:   get("currency"),
+		}
+		for _, pair := range splitList(get("tickers")) {
+			provider, ticker, ok := strings.Cut(pair, ":")
+			if !ok {
+				return nil, fmt.Errorf("csv line %d: ticker %q is not provider:ticker", line, pair)
+			}
+			if in.Tickers == nil {
+				in.Tickers = make(map[string]string)
+			}
+			in.Tickers[strings.TrimSpace(provider)] = strings.TrimSpace(ticker)
+		}
+		list = append(list, in)
+	}
+	return list, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, "|") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,304 @@
+package symbols
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveDefault(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		// Previously broken aliases
+		{"瑞郎", "CHF=X"},
+		{"韩元", "KRW=X"},
+		{"越南盾", "VND=X"},
+
+		// Codes and provider tickers
+		{"AAPL", "AAPL"},
+		{"aapl", "AAPL"},
+		{"BTC-USD", "BTCUSDT"},
+		{"btc-usdt", "BTCUSDT"},
+		{"^gspc", "^GSPC"},
+
+		// Names and aliases
+		{"btc", "BTCUSDT"},
+		{"比特币", "BTCUSDT"},
+		{"茅台", "600519.SS"},
+		{"贵州茅台", "600519.SS"},
+		{"腾讯", "0700.HK"},
+		{"Tencent", "0700.HK"},
+		{"appl", "AAPL"},
+		{"Heating Oil", "HO=F"},
+		{"heating-oil", "HO=F"},
+		{"ＡＡＰＬ", "AAPL"},
+		{"纳指", "^IXIC"},
+		{"纳指期货", "NQ=F"},
+		{"铜", "HG=F"},
+
+		// Fuzzy
+		{"nvid", "NVDA"},
+		{"宁德", "300750.SZ"},
+		{"茅台股价", "600519.SS"},
+		{"Apple Inc", "AAPL"},
+	}
+	m := Default()
+	for _, c := range cases {
+		if got := m.Resolve(c.query).Code(); got != c.want {
+			t.Errorf("Resolve(%q) = %q, want %q", c.query, got, c.want)
+		}
+	}
+}
+
+func TestResolveNoMatch(t *testing.T) {
+	m := Default()
+	// Short Latin input is a ticker, never fuzzy matched ("PL" is not platinum)
+	for _, q := range []string{"", "  ", "PL", "PLTR", "铝土", "zzzzzz"} {
+		r := m.Resolve(q)
+		if r.Best != nil {
+			t.Errorf("Resolve(%q) = %s (score %d), want no match", q, r.Code(), r.Best.Score)
+		}
+		if r.Code() != "" || r.Ambiguous || len(r.Candidates) != 0 {
+			t.Errorf("Resolve(%q) = %+v, want empty", q, r)
+		}
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	m := Default()
+
+	r := m.Resolve("中芯国际")
+	if !r.Ambiguous {
+		t.Fatalf("中芯国际 should be ambiguous: %+v", r)
+	}
+	if r.Code() != "688981.SS" || len(r.Candidates) < 2 || r.Candidates[1].Instrument.Code != "0981.HK" {
+		t.Errorf("中芯国际 candidates = %s", r.Describe())
+	}
+	if !strings.Contains(r.Describe(), "1. 中芯国际 (688981.SS, SSE)") {
+		t.Errorf("Describe() = %q", r.Describe())
+	}
+
+	// Dual listings share their aliases
+	r = m.Resolve("阿里")
+	if !r.Ambiguous || len(r.Candidates) != 2 || r.Candidates[0].Instrument.Code != "9988.HK" || r.Candidates[1].Instrument.Code != "BABA" {
+		t.Errorf("阿里 = %s", r.Describe())
+	}
+
+	// An exact alias clearly beats prefix matches
+	r = m.Resolve("京东")
+	if r.Ambiguous || r.Code() != "JD" || len(r.Candidates) != 1 {
+		t.Errorf("京东 = %+v", r)
+	}
+
+	// Deterministic across calls
+	for i := 0; i < 20; i++ {
+		if got := m.Resolve("中国").Describe(); got != m.Resolve("中国").Describe() {
+			t.Fatalf("non-deterministic result: %s", got)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	m := Default()
+	got := m.Search("中国", 3)
+	if len(got) != 3 {
+		t.Fatalf("Search limit: got %d", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Score > got[i-1].Score {
+			t.Errorf("not sorted by score: %+v", got)
+		}
+	}
+	if all := m.Search("中国", 0); len(all) <= 3 {
+		t.Errorf("unlimited search returned %d", len(all))
+	}
+	if m.Search("", 5) != nil {
+		t.Error("empty query should return nil")
+	}
+}
+
+func TestScore(t *testing.T) {
+	name := func(s string) matchKey { return matchKey{text: normalize(s), kind: keyName} }
+	code := func(s string) matchKey { return matchKey{text: normalize(s), kind: keyCode} }
+	cases := []struct {
+		q    string
+		k    matchKey
+		want int
+	}{
+		{"aapl", code("AAPL"), ScoreCode},
+		{"aapl", name("aapl"), ScoreName},
+		{"nvid", name("nvidia"), 70 + 20*4/6},
+		{"茅台", name("贵州茅台"), 50 + 20*2/4},
+		{"茅台股价", name("茅台"), 50 + 20*2/4},
+		{"aap", name("apple"), 0},  // too short for fuzzy
+		{"aapl", code("AAPLX"), 0}, // codes only match exactly
+		{"铜价", name("铜"), 0},       // single character keys only match exactly
+		{"nvidia", name("amd"), 0},
+	}
+	for _, c := range cases {
+		if got := score(normalize(c.q), c.k); got != c.want {
+			t.Errorf("score(%q, %q) = %d, want %d", c.q, c.k.text, got, c.want)
+		}
+	}
+}
+
+func TestGuessCode(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"600519", "600519.SS", true},
+		{"000001", "000001.SZ", true},
+		{"300750", "300750.SZ", true},
+		{"830799", "", false},
+		{"0700", "0700.HK", true},
+		{"09988", "9988.HK", true},
+		{" 1810 ", "1810.HK", true},
+		{"00000", "", false},
+		{"AAPL", "", false},
+		{"123", "", false},
+	}
+	for _, c := range cases {
+		got, ok := GuessCode(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("GuessCode(%q) = %q, %v; want %q, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	btc, ok := Default().Lookup("btcusdt")
+	if !ok {
+		t.Fatal("BTCUSDT missing")
+	}
+	if btc.Ticker("yahoo") != "BTC-USD" || btc.Ticker("okx") != "BTC-USDT" || btc.Ticker("unknown") != "BTCUSDT" {
+		t.Errorf("tickers = %v", btc.Tickers)
+	}
+	if btc.Name() != "比特币" {
+		t.Errorf("Name() = %q", btc.Name())
+	}
+	if n := (&Instrument{Code: "X", NameEN: "Ex"}).Name(); n != "Ex" {
+		t.Errorf("Name() = %q", n)
+	}
+	if n := (&Instrument{Code: "X"}).Name(); n != "X" {
+		t.Errorf("Name() = %q", n)
+	}
+	if _, ok := Default().Lookup("NOPE"); ok {
+		t.Error("Lookup(NOPE) found something")
+	}
+}
+
+func TestEmbeddedMaster(t *testing.T) {
+	m := Default()
+	if m.Len() < 100 {
+		t.Fatalf("embedded master has %d instruments", m.Len())
+	}
+	classes := map[string]bool{ClassStock: true, ClassIndex: true, ClassCrypto: true, ClassForex: true, ClassFuture: true, ClassETF: true}
+	for _, in := range m.Instruments() {
+		if in.Name() == in.Code || in.Exchange == "" || in.Currency == "" || !classes[in.AssetClass] {
+			t.Errorf("incomplete instrument %+v", in)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	m, err := NewMaster([]Instrument{
+		{Code: "AAA", NameEN: "Alpha"},
+		{Code: "BBB", NameEN: "Beta"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Replacing keeps the position, new codes are appended
+	if err := m.Merge(Instrument{Code: "aaa", NameEN: "Alpha Prime"}, Instrument{Code: "CCC", NameEN: "Gamma"}); err != nil {
+		t.Fatal(err)
+	}
+	list := m.Instruments()
+	if len(list) != 3 || list[0].NameEN != "Alpha Prime" || list[2].Code != "CCC" {
+		t.Errorf("after merge: %+v %+v %+v", list[0], list[1], list[2])
+	}
+	if r := m.Resolve("Alpha"); r.Best == nil || r.Best.Score == ScoreName || m.Resolve("Alpha Prime").Code() != "aaa" {
+		t.Error("index not rebuilt after merge")
+	}
+
+	if _, err := NewMaster([]Instrument{{Code: "A"}, {Code: "a"}}); err == nil {
+		t.Error("duplicate codes accepted")
+	}
+	if _, err := NewMaster([]Instrument{{Code: " ", NameEN: "blank"}}); err == nil {
+		t.Error("empty code accepted")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	in := "\ufeffCode,name_en,aliases,tickers,exchange\n" +
+		"BTCUSDT,Bitcoin,btc|大饼,yahoo:BTC-USD|okx:BTC-USDT,CRYPTO\n" +
+		"ES=F,E-mini,,,CME\n" +
+		"SHORT\n"
+	list, err := ParseCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("got %d rows", len(list))
+	}
+	if b := list[0]; b.Code != "BTCUSDT" || len(b.Aliases) != 2 || b.Aliases[1] != "大饼" || b.Tickers["okx"] != "BTC-USDT" || b.Exchange != "CRYPTO" {
+		t.Errorf("row 1 = %+v", b)
+	}
+	if e := list[1]; e.Code != "ES=F" || e.Aliases != nil || e.Tickers != nil {
+		t.Errorf("row 2 = %+v", e)
+	}
+	if s := list[2]; s.Code != "SHORT" || s.NameEN != "" {
+		t.Errorf("row 3 = %+v", s)
+	}
+
+	bad := []string{
+		"",
+		"name_en\nBitcoin\n",
+		"code,tickers\nBTC,yahoo-BTC\n",
+		"code\n\"unterminated\n",
+	}
+	for _, b := range bad {
+		if _, err := ParseCSV(strings.NewReader(b)); err == nil {
+			t.Errorf("ParseCSV(%q) accepted", b)
+		}
+	}
+}
+
+func TestLoadAndMergeFile(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "extra.json")
+	csvPath := filepath.Join(dir, "extra.CSV")
+	os.WriteFile(jsonPath, []byte(`[{"code": "PLTR", "name_cn": "帕兰提尔", "name_en": "Palantir", "exchange": "NASDAQ", "asset_class": "stock", "currency": "USD"}]`), 0o644)
+	os.WriteFile(csvPath, []byte("code,name_cn\nARM,安谋\n"), 0o644)
+
+	list, err := Load(jsonPath)
+	if err != nil || len(list) != 1 || list[0].NameEN != "Palantir" {
+		t.Fatalf("Load json = %+v, %v", list, err)
+	}
+	list, err = Load(csvPath)
+	if err != nil || len(list) != 1 || list[0].NameCN != "安谋" {
+		t.Fatalf("Load csv = %+v, %v", list, err)
+	}
+
+	if err := MergeFile(jsonPath); err != nil {
+		t.Fatal(err)
+	}
+	if got := Default().Resolve("帕兰提尔").Code(); got != "PLTR" {
+		t.Errorf("after MergeFile: %q", got)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file accepted")
+	}
+	if err := MergeFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("MergeFile of missing file succeeded")
+	}
+	os.WriteFile(jsonPath, []byte(`{"code": "X"}`), 0o644)
+	if _, err := Load(jsonPath); err == nil {
+		t.Error("non-array JSON accepted")
+	}
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"investor/internal/dataservice"
+	"investor/internal/render"
+)
+
+// NewDataRegistry registers the DataService tools, reusing the schemas in dataservice.ToolsDefinition
+func NewDataRegistry(data dataservice.DataService) *Registry {
+	r := NewRegistry()
+	RegisterDataTools(r, data)
+	return r
+}
+
+// RegisterDataTools adds one tool per entry of dataservice.ToolsDefinition
+func RegisterDataTools(r *Registry, data dataservice.DataService) {
+	handlers := map[string]Handler{
+		"get_ipo_list": func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+			return data.GetIPOList(ctx)
+		},
+		"get_market_quote": func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbol string `json:"symbol"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return data.GetMarketQuote(ctx, args.Symbol)
+		},
+		"get_market_quotes": func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbols []string `json:"symbols"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if len(args.Symbols) == 0 || len(args.Symbols) > dataservice.MaxBatchQuotes {
+				return nil, fmt.Errorf("symbols must list 1-%d symbols", dataservice.MaxBatchQuotes)
+			}
+			results := dataservice.GetMarketQuotes(ctx, data, args.Symbols)
+			return map[string]interface{}{
+				"quotes": results,
+				"table":  render.MarkdownRenderer.Render(dataservice.QuotesDocument(results)), // ready-to-show card
+			}, nil
+		},
+		"search_market_news": func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Query string `json:"query"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return data.SearchMarketNews(ctx, args.Query)
+		},
+		"get_market_index": func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+			return data.GetMarketIndex(ctx)
+		},
+		"get_security_analysis": func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbol    string `json:"symbol"`
+				AssetType string `json:"asset_type"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return data.GetSecurityAnalysis(ctx, args.Symbol, args.AssetType)
+		},
+		"get_historical_quotes": func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbol   string `json:"symbol"`
+				Interval string `json:"interval"`
+				Range    string `json:"range"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.Interval == "" {
+				args.Interval = "1d"
+			}
+			if args.Range == "" {
+				args.Range = "1mo"
+			}
+			return data.GetHistoricalQuotes(ctx, args.Symbol, args.Interval, args.Range)
+		},
+		"get_market_sentiment": func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Market string `json:"market"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return data.GetMarketSentiment(ctx, args.Market)
+		},
+	}
+	// Optional capabilities: only advertised when the service implements them
+	if fp, ok := data.(dataservice.FundamentalsProvider); ok {
+		handlers["get_fundamentals"] = func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbol string `json:"symbol"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return fp.GetFundamentals(ctx, args.Symbol)
+		}
+	}
+	if sp, ok := data.(dataservice.SectorProvider); ok {
+		handlers["get_sectors"] = func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Symbol string `json:"symbol"`
+			}
+			if err := Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return sp.GetSectors(ctx, args.Symbol)
+		}
+	}
+
+	for _, def := range dataservice.ToolsDefinition {
+		fn, _ := def["function"].(map[string]interface{})
+		name, _ := fn["name"].(string)
+		handler, ok := handlers[name]
+		if !ok {
+			continue
+		}
+		desc, _ := fn["description"].(string)
+		params, _ := fn["parameters"].(map[string]interface{})
+		r.Register(Tool{
+			Name:        name,
+			Description: desc,
+			Parameters:  params,
+			Handler:     handler,
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"investor/internal/model"
+)
+
+// Handler executes a tool with its raw JSON arguments
+type Handler func(ctx context.Context, args json.RawMessage) (interface{}, error)
+
+// Tool is a callable capability with a JSON Schema for its parameters.
+// The same definition feeds LLM function calling and the MCP server.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON Schema (type: object)
+	Handler     Handler
+}
+
+// Registry holds the tools available to the agent
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		tools: make(map[string]Tool),
+	}
+}
+
+// Register adds or replaces a tool. Registration order is kept for listing.
+func (r *Registry) Register(t Tool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.Parameters == nil {
+		t.Parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	if _, exists := r.tools[t.Name]; !exists {
+		r.order = append(r.order, t.Name)
+	}
+	r.tools[t.Name] = t
+}
+
+func (r *Registry) Get(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[name]
+	return t, ok
+}
+
+// List returns tools in registration order
+func (r *Registry) List() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Tool, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.tools[name])
+	}
+	return list
+}
+
+// Names returns the sorted tool names
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := append([]string(nil), r.order...)
+	sort.Strings(names)
+	return names
+}
+
+// Definitions renders the tools in OpenAI function-calling format
+func (r *Registry) Definitions() []map[string]interface{} {
+	var defs []map[string]interface{}
+	for _, t := range r.List() {
+		defs = append(defs, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  t.Parameters,
+			},
+		})
+	}
+	return defs
+}
+
+// Call runs a tool by name
+func (r *Registry) Call(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+	t, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	return t.Handler(ctx, args)
+}
+
+type messageKey struct{}
+
+// WithMessage attaches the message being answered, so tools can act on behalf of its sender
+func WithMessage(ctx context.Context, msg *model.InternalMessage) context.Context {
+	return context.WithValue(ctx, messageKey{}, msg)
+}
+
+// MessageFrom returns the message being answered, or nil outside a chat (e.g. MCP calls)
+func MessageFrom(ctx context.Context) *model.InternalMessage {
+	msg, _ := ctx.Value(messageKey{}).(*model.InternalMessage)
+	return msg
+}
+
+// Decode unmarshals tool arguments, reporting a readable error
+func Decode(args json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package tradinghours
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // exchange time zones work on hosts without zoneinfo
+)
+
+// The built-in holiday tables cover 2025-2026 (full-day closures on
+// weekdays, as published by the exchanges). Later years, or corrections,
+// are added with MergeFile; past the last listed year a Status is marked
+// Uncovered (see Exchange.Covers).
+
+var cnHolidays = []string{
+	// 2025
+	"2025-01-01", "2025-01-28", "2025-01-29", "2025-01-30", "2025-01-31", "2025-02-03", "2025-02-04",
+	"2025-04-04", "2025-05-01", "2025-05-02", "2025-05-05", "2025-06-02",
+	"2025-10-01", "2025-10-02", "2025-10-03", "2025-10-06", "2025-10-07", "2025-10-08",
+	// 2026
+	"2026-01-01", "2026-01-02", "2026-02-16", "2026-02-17", "2026-02-18", "2026-02-19", "2026-02-20", "2026-02-23",
+	"2026-04-06", "2026-05-01", "2026-05-04", "2026-05-05", "2026-06-19", "2026-09-25",
+	"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06", "2026-10-07",
+}
+
+var hkHolidays = []string{
+	// 2025
+	"2025-01-01", "2025-01-29", "2025-01-30", "2025-01-31", "2025-04-04", "2025-04-18", "2025-04-21",
+	"2025-05-01", "2025-05-05", "2025-07-01", "2025-10-01", "2025-10-07", "2025-10-29", "2025-12-25", "2025-12-26",
+	// 2026
+	"2026-01-01", "2026-02-17", "2026-02-18", "2026-02-19", "2026-04-03", "2026-04-06", "2026-04-07",
+	"2026-05-01", "2026-05-25", "2026-06-19", "2026-07-01", "2026-10-01", "2026-10-19", "2026-12-25",
+}
+
+// Half-day sessions (morning only) before Lunar New Year, Christmas and New Year
+var hkEarlyClose = []string{"2025-01-28", "2025-12-24", "2025-12-31", "2026-02-16", "2026-12-24", "2026-12-31"}
+
+var usHolidays = []string{
+	// 2025
+	"2025-01-01", "2025-01-09", "2025-01-20", "2025-02-17", "2025-04-18", "2025-05-26",
+	"2025-06-19", "2025-07-04", "2025-09-01", "2025-11-27", "2025-12-25",
+	// 2026
+	"2026-01-01", "2026-01-19", "2026-02-16", "2026-04-03", "2026-05-25",
+	"2026-06-19", "2026-07-03", "2026-09-07", "2026-11-26", "2026-12-25",
+}
+
+// 13:00 closes around Independence Day, Thanksgiving and Christmas
+var usEarlyClose = []string{"2025-07-03", "2025-11-28", "2025-12-24", "2026-11-27", "2026-12-24"}
+
+// CME Globex stays open, on a shortened session, on most US holidays; only
+// these days are fully closed. Holiday hours vary by product, so futures
+// status around holidays is approximate.
+var cmeHolidays = []string{"2025-01-01", "2025-04-18", "2025-12-25", "2026-01-01", "2026-04-03", "2026-12-25"}
+
+var fxHolidays = []string{"2025-01-01", "2025-12-25", "2026-01-01", "2026-12-25"}
+
+func weekdays(spans ...Span) map[time.Weekday][]Span {
+	m := make(map[time.Weekday][]Span)
+	for d := time.Monday; d <= time.Friday; d++ {
+		m[d] = spans
+	}
+	return m
+}
+
+func dates(list []string) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, d := range list {
+		m[d] = true
+	}
+	return m
+}
+
+func closes(list []string, at string) map[string]int {
+	m := make(map[string]int, len(list))
+	for _, d := range list {
+		m[d] = clock(at)
+	}
+	return m
+}
+
+// mustLocation cannot fail: the time zone database is embedded
+func mustLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// The A-share exchanges share one calendar, as do NYSE and Nasdaq, so a
+// holiday merged into one applies to all of them
+var (
+	cnCalendar    = dates(cnHolidays)
+	usCalendar    = dates(usHolidays)
+	usEarlyCloses = closes(usEarlyClose, "13:00")
+)
+
+func cnExchange(code, name string) *Exchange {
+	return &Exchange{
+		Code: code, Name: name, Location: mustLocation("Asia/Shanghai"),
+		Regular:  weekdays(hm("09:30", "11:30"), hm("13:00", "15:00")),
+		Holidays: cnCalendar,
+	}
+}
+
+func usExchange(code, name string) *Exchange {
+	return &Exchange{
+		Code: code, Name: name, Location: mustLocation("America/New_York"),
+		Regular: weekdays(hm("09:30", "16:00")),
+		PreOpen: clock("04:00"), PostLength: 4 * 60,
+		Holidays: usCalendar, EarlyClose: usEarlyCloses,
+	}
+}
+
+// roundTheClock is Sunday evening to Friday afternoon with a daily break,
+// the shape of CME Globex and the FX market
+func roundTheClock(open, close string) map[time.Weekday][]Span {
+	o, c := clock(open), clock(close)
+	m := map[time.Weekday][]Span{
+		time.Sunday: {{Open: o, Close: dayMinutes}},
+		time.Friday: {{Open: 0, Close: c}},
+	}
+	for d := time.Monday; d <= time.Thursday; d++ {
+		if o > c {
+			m[d] = []Span{{Open: 0, Close: c}, {Open: o, Close: dayMinutes}}
+		} else {
+			m[d] = []Span{{Open: 0, Close: dayMinutes}}
+		}
+	}
+	return m
+}
+
+func init() {
+	for _, e := range []*Exchange{
+		cnExchange("SSE", "A股 (SSE/SZSE)"),
+		cnExchange("SZSE", "深交所"),
+		cnExchange("BSE", "北交所"),
+		{
+			Code: "HKEX", Name: "港股 (HKEX)", Location: mustLocation("Asia/Hong_Kong"),
+			Regular:  weekdays(hm("09:30", "12:00"), hm("13:00", "16:00")),
+			Holidays: dates(hkHolidays), EarlyClose: closes(hkEarlyClose, "12:00"),
+		},
+		usExchange("NYSE", "美股 (NYSE/Nasdaq)"),
+		usExchange("NASDAQ", "纳斯达克"),
+		{
+			Code: "CME", Name: "期货 (CME Globex)", Location: mustLocation("America/Chicago"),
+			Regular:  roundTheClock("17:00", "16:00"),
+			Holidays: dates(cmeHolidays),
+		},
+		{
+			Code: "FX", Name: "外汇", Location: mustLocation("America/New_York"),
+			Regular:  roundTheClock("17:00", "17:00"),
+			Holidays: dates(fxHolidays),
+		},
+		{Code: "CRYPTO", Name: "加密货币", Location: time.UTC, AlwaysOpen: true},
+	} {
+		Exchanges[e.Code] = e
+	}
+}
+
+// fileCalendar is one exchange's entry in a holidays file
+type fileCalendar struct {
+	Holidays   []string          `json:"holidays"`
+	EarlyClose map[string]string `json:"early_close"` // date -> "13:00"
+}
+
+// MergeFile adds holidays and early closes from a JSON file keyed by exchange:
+//
+//	{"NYSE": {"holidays": ["2027-01-01"], "early_close": {"2027-11-26": "13:00"}}}
+func MergeFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file map[string]fileCalendar
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("trading calendar %s: %w", path, err)
+	}
+	for code, cal := range file {
+		e, ok := Get(code)
+		if !ok {
+			return fmt.Errorf("trading calendar %s: unknown exchange %q", path, code)
+		}
+		for _, d := range cal.Holidays {
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				return fmt.Errorf("trading calendar %s: %s: bad date %q", path, code, d)
+			}
+			e.Holidays[d] = true
+		}
+		for d, at := range cal.EarlyClose {
+			if _, err := time.Parse("2006-01-02", d); err != nil || !strings.Contains(at, ":") {
+				return fmt.Errorf("trading calendar %s: %s: bad early close %q %q", path, code, d, at)
+			}
+			if e.EarlyClose == nil {
+				e.EarlyClose = make(map[string]int)
+			}
+			e.EarlyClose[d] = clock(at)
+		}
+	}
+	return nil
+}
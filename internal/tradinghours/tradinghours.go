@@ -0,0 +1,335 @@
+// Package tradinghours knows when markets trade: regular sessions (with the
+// A-share and Hong Kong lunch breaks), US extended hours, early closes and
+// exchange holidays for SSE/SZSE, HKEX, NYSE/Nasdaq, CME Globex, 24/5 FX and
+// 24/7 crypto.
+package tradinghours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"investor/internal/symbols"
+)
+
+// Session states, the same strings Yahoo uses for marketState
+const (
+	Pre     = "PRE"
+	Regular = "REGULAR"
+	Post    = "POST"
+	Closed  = "CLOSED"
+)
+
+const dayMinutes = 24 * 60
+
+// Span is a trading window in minutes after local midnight, Close exclusive
+type Span struct {
+	Open, Close int
+}
+
+func (s Span) contains(min int) bool {
+	return min >= s.Open && min < s.Close
+}
+
+// hm builds a span from "09:30"-style clock times
+func hm(open, close string) Span {
+	return Span{Open: clock(open), Close: clock(close)}
+}
+
+func clock(s string) int {
+	var h, m int
+	fmt.Sscanf(s, "%d:%d", &h, &m)
+	return h*60 + m
+}
+
+// Exchange is one venue's trading calendar
+type Exchange struct {
+	Code     string
+	Name     string // label shown to users
+	Location *time.Location
+
+	Regular    map[time.Weekday][]Span // regular sessions by local weekday
+	PreOpen    int                     // start of pre-market (US 04:00), 0 = no extended hours
+	PostLength int                     // minutes of after-hours trading after the close
+	AlwaysOpen bool
+
+	Holidays   map[string]bool // local dates ("2006-01-02") without trading
+	EarlyClose map[string]int  // local date -> regular close in minutes
+}
+
+// Status is where an exchange is in its day
+type Status struct {
+	Exchange string    `json:"exchange"`
+	State    string    `json:"state"`            // PRE, REGULAR, POST or CLOSED
+	Reason   string    `json:"reason,omitempty"` // why it is not trading: 周末, 节假日, 午间休市 ...
+	Break    bool      `json:"break,omitempty"`  // paused between two sessions of the day (lunch, futures daily break)
+	NextOpen time.Time `json:"next_open,omitempty"`
+
+	// Uncovered is set past the end of the holiday table: the state follows
+	// the weekly schedule only, and the day may well be a holiday
+	Uncovered bool `json:"uncovered,omitempty"`
+}
+
+// Open reports whether the regular session is trading
+func (s Status) Open() bool {
+	return s.State == Regular
+}
+
+// sessions are the regular spans of a local date after holidays and early closes
+func (e *Exchange) sessions(local time.Time) []Span {
+	date := local.Format("2006-01-02")
+	if e.Holidays[date] {
+		return nil
+	}
+	spans := e.Regular[local.Weekday()]
+	close, early := e.EarlyClose[date]
+	if !early {
+		return spans
+	}
+	var out []Span
+	for _, s := range spans {
+		if s.Open >= close {
+			break
+		}
+		if s.Close > close {
+			s.Close = close
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// Covers reports whether the holiday table includes t's local year. A table
+// covers every year it lists a holiday in (and the years before).
+func (e *Exchange) Covers(t time.Time) bool {
+	if e.AlwaysOpen {
+		return true
+	}
+	year := t.In(e.Location).Format("2006")
+	for d := range e.Holidays {
+		if d[:4] >= year {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTradingDay reports whether the exchange has a regular session on t's local date
+func (e *Exchange) IsTradingDay(t time.Time) bool {
+	return e.AlwaysOpen || len(e.sessions(t.In(e.Location))) > 0
+}
+
+// Status places t in the exchange's day
+func (e *Exchange) Status(t time.Time) Status {
+	st := Status{Exchange: e.Code, State: Closed, Uncovered: !e.Covers(t)}
+	if e.AlwaysOpen {
+		st.State = Regular
+		return st
+	}
+	local := t.In(e.Location)
+	min := local.Hour()*60 + local.Minute()
+	spans := e.sessions(local)
+
+	for _, s := range spans {
+		if s.contains(min) {
+			st.State = Regular
+			return st
+		}
+	}
+	st.NextOpen = e.NextOpen(t)
+
+	switch {
+	case e.Holidays[local.Format("2006-01-02")]:
+		st.Reason = "节假日"
+	case len(spans) == 0:
+		st.Reason = "周末"
+	case min < spans[0].Open:
+		if e.PreOpen > 0 && min >= e.PreOpen {
+			st.State, st.Reason = Pre, "盘前交易"
+		} else {
+			st.Reason = "未开盘"
+		}
+	case min >= spans[len(spans)-1].Close:
+		if last := spans[len(spans)-1].Close; e.PostLength > 0 && min < last+e.PostLength {
+			st.State, st.Reason = Post, "盘后交易"
+		} else {
+			st.Reason = "已收盘"
+		}
+	case spans[0].Open == 0:
+		st.Reason, st.Break = "每日休市", true // futures/FX daily break
+	default:
+		st.Reason, st.Break = "午间休市", true
+	}
+	return st
+}
+
+// NextOpen is the start of the next regular session after t (zero if none within a month)
+func (e *Exchange) NextOpen(t time.Time) time.Time {
+	if e.AlwaysOpen {
+		return time.Time{}
+	}
+	local := t.In(e.Location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, e.Location)
+	for i := 0; i < 31; i++ {
+		d := day.AddDate(0, 0, i)
+		for _, s := range e.sessions(d) {
+			open := d.Add(time.Duration(s.Open) * time.Minute)
+			// a session starting at midnight continues the previous day's
+			if s.Open == 0 && len(e.sessions(d.AddDate(0, 0, -1))) > 0 {
+				continue
+			}
+			if open.After(t) {
+				return open
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// Progress is the share of t's local trading day already traded, for
+// projecting a partial daily bar. It is false when the day has no session or
+// the sessions run through midnight (futures, FX), where daily bars do not
+// follow the calendar day. Crypto progresses through the UTC day.
+func (e *Exchange) Progress(t time.Time) (float64, bool) {
+	local := t.In(e.Location)
+	min := local.Hour()*60 + local.Minute()
+	if e.AlwaysOpen {
+		return float64(min) / dayMinutes, true
+	}
+	spans := e.sessions(local)
+	if len(spans) == 0 {
+		return 0, false
+	}
+	var total, done int
+	for _, s := range spans {
+		if s.Open == 0 || s.Close == dayMinutes {
+			return 0, false
+		}
+		total += s.Close - s.Open
+		switch {
+		case min >= s.Close:
+			done += s.Close - s.Open
+		case min > s.Open:
+			done += min - s.Open
+		}
+	}
+	return float64(done) / float64(total), true
+}
+
+// Exchanges by code. SZSE and BSE share the SSE calendar, Nasdaq shares NYSE's.
+var Exchanges = map[string]*Exchange{}
+
+// Get returns an exchange by code (case-insensitive)
+func Get(code string) (*Exchange, bool) {
+	e, ok := Exchanges[strings.ToUpper(code)]
+	return e, ok
+}
+
+// venues maps instrument-master exchanges to the calendar they trade on
+var venues = map[string]string{
+	"SSE": "SSE", "SZSE": "SZSE", "BSE": "BSE", "HKEX": "HKEX",
+	"NYSE": "NYSE", "NASDAQ": "NASDAQ",
+	"CME": "CME", "CBOT": "CME", "NYMEX": "CME", "COMEX": "CME", "CFE": "CME",
+	"FX": "FX", "CRYPTO": "CRYPTO",
+}
+
+// indexVenues are the index codes of the master's INDEX pseudo-exchange
+var indexVenues = map[string]string{"^GSPC": "NYSE", "^IXIC": "NASDAQ", "^DJI": "NYSE", "^VIX": "NYSE", "^HSI": "HKEX"}
+
+// ForSymbol finds the calendar a symbol trades on: the instrument master
+// first (codes, names, aliases), then the ticker's form (600519.SS, 0700.HK,
+// ES=F, EURUSD=X, BTC-USD, plain US tickers). False for venues without a calendar.
+func ForSymbol(symbol string) (*Exchange, bool) {
+	if res := symbols.Default().Resolve(symbol); res.Best != nil {
+		inst := res.Best.Instrument
+		if v, ok := venues[strings.ToUpper(inst.Exchange)]; ok {
+			return Get(v)
+		}
+		if v, ok := indexVenues[inst.Code]; ok {
+			return Get(v)
+		}
+		return nil, false
+	}
+	code := strings.ToUpper(strings.TrimSpace(symbol))
+	if guess, ok := symbols.GuessCode(code); ok {
+		code = guess
+	}
+	if v, ok := indexVenues[code]; ok {
+		return Get(v)
+	}
+
+	switch {
+	case strings.HasSuffix(code, ".SS"), strings.HasSuffix(code, ".SH"):
+		return Get("SSE")
+	case strings.HasSuffix(code, ".SZ"):
+		return Get("SZSE")
+	case strings.HasSuffix(code, ".BJ"):
+		return Get("BSE")
+	case strings.HasSuffix(code, ".HK"):
+		return Get("HKEX")
+	case strings.HasSuffix(code, "=F"):
+		return Get("CME")
+	case strings.HasSuffix(code, "=X"):
+		return Get("FX")
+	case strings.HasSuffix(code, "-USD"), strings.HasSuffix(code, "-USDT"), strings.HasSuffix(code, "USDT"):
+		return Get("CRYPTO")
+	case strings.Contains(code, ".") || strings.HasPrefix(code, "^"):
+		return nil, false // other markets
+	}
+	return Get("NYSE")
+}
+
+// Overview is the main markets, in the order they are shown to users
+var Overview = []string{"SSE", "HKEX", "NYSE", "CME", "FX", "CRYPTO"}
+
+// Summary describes every Overview market at t, one line each, e.g.
+// "A股 (SSE/SZSE): 休市 (周末), 下次开盘 2026-10-19 09:30 (Asia/Shanghai)"
+func Summary(t time.Time) string {
+	var b strings.Builder
+	for _, code := range Overview {
+		e, ok := Get(code)
+		if !ok {
+			continue
+		}
+		st := e.Status(t)
+		fmt.Fprintf(&b, "- %s: %s", e.Name, StateLabel(st.State))
+		if st.Reason != "" && st.State == Closed {
+			fmt.Fprintf(&b, " (%s)", st.Reason)
+		}
+		if !st.NextOpen.IsZero() && st.State != Regular {
+			fmt.Fprintf(&b, ", 下次开盘 %s (%s)", st.NextOpen.In(e.Location).Format("2006-01-02 15:04"), e.Location)
+		}
+		if st.Uncovered {
+			b.WriteString(" [节假日表未覆盖本年，状态仅按周历推算]")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Expiring lists the Overview exchanges whose holiday table does not cover t,
+// e.g. to warn a month ahead that next year's holidays are missing
+func Expiring(t time.Time) []string {
+	var codes []string
+	for _, code := range Overview {
+		if e, ok := Get(code); ok && !e.Covers(t) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+var stateLabels = map[string]string{
+	Pre:     "盘前交易",
+	Regular: "交易中",
+	Post:    "盘后交易",
+	Closed:  "休市",
+}
+
+// StateLabel is the Chinese name of a session state
+func StateLabel(state string) string {
+	if l, ok := stateLabels[state]; ok {
+		return l
+	}
+	return state
+}
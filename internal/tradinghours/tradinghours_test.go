@@ -0,0 +1,195 @@
+package tradinghours
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// at parses a local wall-clock time on the exchange
+func at(t *testing.T, code, local string) time.Time {
+	t.Helper()
+	e, ok := Get(code)
+	if !ok {
+		t.Fatalf("no exchange %s", code)
+	}
+	ts, err := time.ParseInLocation("2006-01-02 15:04", local, e.Location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestStatus(t *testing.T) {
+	cases := []struct {
+		code, at string
+		state    string
+		reason   string
+		brk      bool
+		nextOpen string // local, "" = none
+	}{
+		// A shares: lunch break, weekend and the National Day week
+		{"SSE", "2026-10-20 10:00", Regular, "", false, ""},
+		{"SSE", "2026-10-20 09:00", Closed, "未开盘", false, "2026-10-20 09:30"},
+		{"SSE", "2026-10-20 12:00", Closed, "午间休市", true, "2026-10-20 13:00"},
+		{"SSE", "2026-10-20 15:30", Closed, "已收盘", false, "2026-10-21 09:30"},
+		{"SSE", "2026-10-17 10:00", Closed, "周末", false, "2026-10-19 09:30"},
+		{"SSE", "2026-10-01 10:00", Closed, "节假日", false, "2026-10-08 09:30"},
+
+		// Hong Kong: Chung Yeung and the Christmas Eve half day
+		{"HKEX", "2026-10-19 10:00", Closed, "节假日", false, "2026-10-20 09:30"},
+		{"HKEX", "2026-12-24 11:00", Regular, "", false, ""},
+		{"HKEX", "2026-12-24 13:30", Closed, "已收盘", false, "2026-12-28 09:30"},
+
+		// US: extended hours, Thanksgiving and the 13:00 close the day after
+		{"NYSE", "2026-10-20 03:00", Closed, "未开盘", false, "2026-10-20 09:30"},
+		{"NYSE", "2026-10-20 08:00", Pre, "盘前交易", false, "2026-10-20 09:30"},
+		{"NYSE", "2026-10-20 10:00", Regular, "", false, ""},
+		{"NYSE", "2026-10-20 17:00", Post, "盘后交易", false, "2026-10-21 09:30"},
+		{"NYSE", "2026-10-20 21:00", Closed, "已收盘", false, "2026-10-21 09:30"},
+		{"NYSE", "2026-11-26 10:00", Closed, "节假日", false, "2026-11-27 09:30"},
+		{"NYSE", "2026-11-27 12:30", Regular, "", false, ""},
+		{"NYSE", "2026-11-27 13:30", Post, "盘后交易", false, "2026-11-30 09:30"},
+
+		// CME Globex: daily break, Sunday evening open
+		{"CME", "2026-10-20 03:00", Regular, "", false, ""},
+		{"CME", "2026-10-20 16:30", Closed, "每日休市", true, "2026-10-20 17:00"},
+		{"CME", "2026-10-17 12:00", Closed, "周末", false, "2026-10-18 17:00"},
+
+		// FX closes for the weekend on Friday afternoon; crypto never does
+		{"FX", "2026-10-16 18:00", Closed, "已收盘", false, "2026-10-18 17:00"},
+		{"CRYPTO", "2026-10-17 12:00", Regular, "", false, ""},
+	}
+	for _, c := range cases {
+		e, _ := Get(c.code)
+		st := e.Status(at(t, c.code, c.at))
+		if st.State != c.state || st.Reason != c.reason || st.Break != c.brk {
+			t.Errorf("%s %s: got %s %q break=%v, want %s %q break=%v",
+				c.code, c.at, st.State, st.Reason, st.Break, c.state, c.reason, c.brk)
+		}
+		var next string
+		if !st.NextOpen.IsZero() {
+			next = st.NextOpen.In(e.Location).Format("2006-01-02 15:04")
+		}
+		if next != c.nextOpen {
+			t.Errorf("%s %s: next open %q, want %q", c.code, c.at, next, c.nextOpen)
+		}
+		if st.Uncovered {
+			t.Errorf("%s %s: marked uncovered", c.code, c.at)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	cases := []struct {
+		code, at string
+		want     float64
+		ok       bool
+	}{
+		{"SSE", "2026-10-20 09:00", 0, true},
+		{"SSE", "2026-10-20 10:30", 0.25, true},
+		{"SSE", "2026-10-20 12:00", 0.5, true}, // lunch does not count
+		{"SSE", "2026-10-20 14:00", 0.75, true},
+		{"SSE", "2026-10-20 16:00", 1, true},
+		{"SSE", "2026-10-17 10:00", 0, false},
+		{"NYSE", "2026-11-27 11:15", 0.5, true}, // early close: 09:30-13:00
+		{"CME", "2026-10-20 10:00", 0, false},
+		{"CRYPTO", "2026-10-17 12:00", 0.5, true},
+	}
+	for _, c := range cases {
+		e, _ := Get(c.code)
+		got, ok := e.Progress(at(t, c.code, c.at))
+		if ok != c.ok || got != c.want {
+			t.Errorf("%s %s: got %v %v, want %v %v", c.code, c.at, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestForSymbol(t *testing.T) {
+	cases := []struct {
+		symbol string
+		want   string // "" = no calendar
+	}{
+		{"600519", "SSE"},
+		{"茅台", "SSE"},
+		{"600519.SS", "SSE"},
+		{"000001.SZ", "SZSE"},
+		{"0700.HK", "HKEX"},
+		{"^HSI", "HKEX"},
+		{"ES=F", "CME"},
+		{"EURUSD=X", "FX"},
+		{"BTC-USD", "CRYPTO"},
+		{"AAPL", "NASDAQ"},
+		{"VOD.L", ""},
+	}
+	for _, c := range cases {
+		e, ok := ForSymbol(c.symbol)
+		var got string
+		if ok {
+			got = e.Code
+		}
+		if got != c.want {
+			t.Errorf("ForSymbol(%q) = %q, want %q", c.symbol, got, c.want)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	sse, _ := Get("SSE")
+	if !sse.Covers(at(t, "SSE", "2026-12-31 10:00")) {
+		t.Error("2026 should be covered")
+	}
+	// 2027-02-08 is a Monday: only the weekly schedule is known
+	st := sse.Status(at(t, "SSE", "2027-02-08 10:00"))
+	if !st.Uncovered || st.State != Regular {
+		t.Errorf("2027-02-08: got %s uncovered=%v, want REGULAR uncovered", st.State, st.Uncovered)
+	}
+	crypto, _ := Get("CRYPTO")
+	if crypto.Status(at(t, "CRYPTO", "2030-01-01 00:00")).Uncovered {
+		t.Error("crypto has no holidays to cover")
+	}
+
+	expiring := Expiring(at(t, "SSE", "2027-01-04 10:00"))
+	if len(expiring) == 0 || expiring[0] != "SSE" {
+		t.Errorf("Expiring = %v, want SSE first", expiring)
+	}
+	for _, code := range expiring {
+		if code == "CRYPTO" {
+			t.Error("Expiring lists CRYPTO")
+		}
+	}
+}
+
+func TestMergeFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	for _, body := range []string{
+		`{"LSE": {"holidays": ["2027-01-01"]}}`,
+		`{"FX": {"holidays": ["01/01/2027"]}}`,
+		`{"FX": {"early_close": {"2027-12-24": "1pm"}}}`,
+	} {
+		if err := MergeFile(write("bad.json", body)); err == nil {
+			t.Errorf("MergeFile(%s) should fail", body)
+		}
+	}
+
+	fx, _ := Get("FX")
+	newYear := at(t, "FX", "2027-01-01 10:00")
+	if err := MergeFile(write("fx.json", `{"FX": {"holidays": ["2027-01-01"], "early_close": {"2027-12-24": "13:00"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if st := fx.Status(newYear); st.Reason != "节假日" || st.Uncovered {
+		t.Errorf("2027-01-01 after merge: %s %q uncovered=%v", st.State, st.Reason, st.Uncovered)
+	}
+	if st := fx.Status(at(t, "FX", "2027-12-24 14:00")); st.Reason != "已收盘" {
+		t.Errorf("2027-12-24 14:00 after merge: %s %q, want 已收盘", st.State, st.Reason)
+	}
+}
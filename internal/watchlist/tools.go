@@ -0,0 +1,159 @@
+package watchlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"investor/internal/command"
+	"investor/internal/model"
+	"investor/internal/render"
+	"investor/internal/tools"
+)
+
+var scopeParam = map[string]interface{}{
+	"type":        "string",
+	"description": "'user': 发送者自己的自选 (默认); 'group': 当前群的共享自选",
+	"enum":        []string{"user", "group"},
+}
+
+// RegisterTools exposes watchlists to the LLM
+func RegisterTools(r *tools.Registry, m *Manager) {
+	symbolsParams := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"symbols": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "标的代码或名称列表，如 ['AAPL', 'BTC', '茅台']",
+			},
+			"scope": scopeParam,
+		},
+		"required": []string{"symbols"},
+	}
+
+	type symbolsArgs struct {
+		Symbols []string `json:"symbols"`
+		Scope   Scope    `json:"scope"`
+	}
+
+	r.Register(tools.Tool{
+		Name:        "add_to_watchlist",
+		Description: "把标的加入自选列表",
+		Parameters:  symbolsParams,
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			msg, err := chatMessage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var args symbolsArgs
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return m.Add(ctx, OwnerOf(msg, args.Scope), args.Symbols...)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "remove_from_watchlist",
+		Description: "把标的从自选列表中移除",
+		Parameters:  symbolsParams,
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			msg, err := chatMessage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var args symbolsArgs
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			return m.Remove(ctx, OwnerOf(msg, args.Scope), args.Symbols...)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "get_watchlist",
+		Description: "查看自选列表及每个标的的最新价格、涨跌幅和近10日走势 (返回的 table 可直接展示)",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"scope": scopeParam,
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			msg, err := chatMessage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var args struct {
+				Scope Scope `json:"scope"`
+			}
+			if err := tools.Decode(raw, &args); err != nil {
+				return nil, err
+			}
+			wl, err := m.Get(ctx, OwnerOf(msg, args.Scope))
+			if err != nil {
+				return nil, err
+			}
+			rows := m.Quotes(ctx, wl.Symbols)
+			return map[string]interface{}{
+				"rows":  rows,
+				"table": render.MarkdownRenderer.Render(RowsDocument("自选", rows)), // ready-to-show card
+			}, nil
+		},
+	})
+}
+
+// RegisterCommands adds the /watch slash command:
+//
+//	/watch                  view my watchlist
+//	/watch add AAPL BTC     add symbols
+//	/watch rm AAPL          remove symbols
+//	/watch group ...        same, on the group's shared list
+func RegisterCommands(r *command.Router, m *Manager) {
+	r.Register(command.Command{
+		Name:    "watch",
+		Usage:   "/watch [group] [add|rm] <标的...>",
+		Summary: "查看或编辑自选列表，加 group 操作群自选",
+		Handler: func(ctx context.Context, msg *model.InternalMessage, args []string) (*render.Document, error) {
+			scope := ScopeUser
+			if len(args) > 0 && strings.EqualFold(args[0], "group") {
+				scope = ScopeGroup
+				args = args[1:]
+			}
+			owner := OwnerOf(msg, scope)
+
+			if len(args) == 0 || args[0] == "list" {
+				return m.View(ctx, owner)
+			}
+
+			var (
+				wl  *Watchlist
+				err error
+			)
+			switch args[0] {
+			case "add":
+				wl, err = m.Add(ctx, owner, args[1:]...)
+			case "rm", "remove", "del":
+				wl, err = m.Remove(ctx, owner, args[1:]...)
+			default:
+				// "/watch AAPL BTC" is shorthand for add
+				wl, err = m.Add(ctx, owner, args...)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return render.NewDocument().
+				Paragraph(fmt.Sprintf("⭐ 自选已更新 (%d): %s", len(wl.Symbols), strings.Join(wl.Symbols, ", "))), nil
+		},
+	})
+}
+
+func chatMessage(ctx context.Context) (*model.InternalMessage, error) {
+	msg := tools.MessageFrom(ctx)
+	if msg == nil {
+		return nil, fmt.Errorf("watchlists are only available in a chat")
+	}
+	return msg, nil
+}
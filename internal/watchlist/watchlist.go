@@ -0,0 +1,244 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"investor/internal/dataservice"
+	"investor/internal/model"
+	"investor/internal/render"
+	"investor/internal/store"
+)
+
+// Scope selects whose watchlist: the sender's own, or the whole group chat's
+type Scope string
+
+const (
+	ScopeUser  Scope = "user"
+	ScopeGroup Scope = "group"
+)
+
+// MaxSymbols caps a single watchlist
+const MaxSymbols = 50
+
+// Owner identifies a watchlist
+type Owner struct {
+	Scope    Scope
+	Platform string
+	ID       string // UserID for ScopeUser, ChatID for ScopeGroup
+}
+
+// OwnerOf derives the owner for a message and scope
+func OwnerOf(msg *model.InternalMessage, scope Scope) Owner {
+	if scope == ScopeGroup {
+		return Owner{Scope: ScopeGroup, Platform: msg.Platform, ID: msg.ChatID}
+	}
+	return Owner{Scope: ScopeUser, Platform: msg.Platform, ID: msg.UserID}
+}
+
+func (o Owner) key() string {
+	return fmt.Sprintf("watchlist:%s:%s:%s", o.Scope, o.Platform, o.ID)
+}
+
+// Watchlist is an ordered list of symbols
+type Watchlist struct {
+	Symbols   []string  `json:"symbols"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Row is one line of the watchlist view
+type Row struct {
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	ChangePct float64   `json:"change_pct"`
+	Trend     []float64 `json:"trend,omitempty"` // recent closes for the sparkline
+	Error     string    `json:"error,omitempty"`
+}
+
+// Manager persists watchlists in the shared state store (same as sessions)
+type Manager struct {
+	Store store.Store
+	Data  dataservice.DataService
+
+	mu sync.Mutex // serializes read-modify-write of a watchlist
+}
+
+func NewManager(st store.Store, data dataservice.DataService) *Manager {
+	return &Manager{
+		Store: st,
+		Data:  data,
+	}
+}
+
+func (m *Manager) Get(ctx context.Context, owner Owner) (*Watchlist, error) {
+	var wl Watchlist
+	if _, err := m.Store.Get(ctx, owner.key(), &wl); err != nil {
+		return nil, err
+	}
+	return &wl, nil
+}
+
+// Add appends symbols (case-insensitive dedupe) and returns the updated list
+func (m *Manager) Add(ctx context.Context, owner Owner, symbols ...string) (*Watchlist, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wl, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, sym := range symbols {
+		sym = strings.TrimSpace(sym)
+		if sym == "" || indexOf(wl.Symbols, sym) >= 0 {
+			continue
+		}
+		if len(wl.Symbols) >= MaxSymbols {
+			return nil, fmt.Errorf("自选列表最多 %d 个标的", MaxSymbols)
+		}
+		wl.Symbols = append(wl.Symbols, sym)
+	}
+	wl.UpdatedAt = time.Now()
+	return wl, m.Store.Put(ctx, owner.key(), wl)
+}
+
+// Remove deletes symbols and returns the updated list
+func (m *Manager) Remove(ctx context.Context, owner Owner, symbols ...string) (*Watchlist, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wl, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, sym := range symbols {
+		if i := indexOf(wl.Symbols, strings.TrimSpace(sym)); i >= 0 {
+			wl.Symbols = append(wl.Symbols[:i], wl.Symbols[i+1:]...)
+		}
+	}
+	wl.UpdatedAt = time.Now()
+	return wl, m.Store.Put(ctx, owner.key(), wl)
+}
+
+// Watched returns the union of the sender's own and the chat's group watchlist
+func (m *Manager) Watched(ctx context.Context, msg *model.InternalMessage) ([]string, error) {
+	var symbols []string
+	for _, scope := range []Scope{ScopeUser, ScopeGroup} {
+		wl, err := m.Get(ctx, OwnerOf(msg, scope))
+		if err != nil {
+			return nil, err
+		}
+		for _, sym := range wl.Symbols {
+			if indexOf(symbols, sym) < 0 {
+				symbols = append(symbols, sym)
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// All returns the distinct symbols of every stored watchlist
+func (m *Manager) All(ctx context.Context) ([]string, error) {
+	keys, err := m.Store.Keys(ctx, "watchlist:")
+	if err != nil {
+		return nil, err
+	}
+	var symbols []string
+	for _, k := range keys {
+		var wl Watchlist
+		if ok, err := m.Store.Get(ctx, k, &wl); err != nil || !ok {
+			continue
+		}
+		for _, sym := range wl.Symbols {
+			if indexOf(symbols, sym) < 0 {
+				symbols = append(symbols, sym)
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// Quotes fetches all quotes in one batch, then a short trend per symbol, 4 at a time
+func (m *Manager) Quotes(ctx context.Context, symbols []string) []Row {
+	rows := make([]Row, len(symbols))
+	sem := make(chan struct{}, 4)
+	var wg sync.WaitGroup
+
+	for i, r := range dataservice.GetMarketQuotes(ctx, m.Data, symbols) {
+		rows[i] = Row{Symbol: r.Symbol}
+		if r.Quote == nil {
+			rows[i].Error = r.Error
+			continue
+		}
+		rows[i].Price, rows[i].ChangePct = r.Quote.Price, r.Quote.ChangePct
+
+		wg.Add(1)
+		go func(row *Row) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if klines, err := m.Data.GetHistoricalQuotes(ctx, row.Symbol, "1d", "1mo"); err == nil {
+				if len(klines) > 10 {
+					klines = klines[len(klines)-10:]
+				}
+				for _, k := range klines {
+					row.Trend = append(row.Trend, k.Close)
+				}
+			}
+		}(&rows[i])
+	}
+	wg.Wait()
+	return rows
+}
+
+// View renders the compact "my watchlist" table
+func (m *Manager) View(ctx context.Context, owner Owner) (*render.Document, error) {
+	wl, err := m.Get(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "我的自选"
+	if owner.Scope == ScopeGroup {
+		title = "群自选"
+	}
+	if len(wl.Symbols) == 0 {
+		return render.NewDocument().Paragraph(fmt.Sprintf("%s为空。示例: /watch add AAPL BTC 茅台", title)), nil
+	}
+
+	return RowsDocument(title, m.Quotes(ctx, wl.Symbols)), nil
+}
+
+// RowsDocument renders watchlist rows as a table with sparklines
+func RowsDocument(title string, rows []Row) *render.Document {
+	var table [][]string
+	for _, r := range rows {
+		if r.Error != "" {
+			table = append(table, []string{r.Symbol, "-", "-", "数据不可用"})
+			continue
+		}
+		icon := "🟢"
+		if r.ChangePct < 0 {
+			icon = "🔴"
+		}
+		table = append(table, []string{
+			r.Symbol,
+			fmt.Sprintf("%.2f", r.Price),
+			fmt.Sprintf("%s %+.2f%%", icon, r.ChangePct),
+			render.SparklineText(r.Trend),
+		})
+	}
+	return render.NewDocument().
+		Heading("⭐", title).
+		Table([]string{"标的", "价格", "涨跌", "走势(10日)"}, table)
+}
+
+func indexOf(list []string, sym string) int {
+	for i, s := range list {
+		if strings.EqualFold(s, sym) {
+			return i
+		}
+	}
+	return -1
+}